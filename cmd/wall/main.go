@@ -11,11 +11,16 @@ import (
 	"syscall"
 
 	qzone "github.com/guohuiyuan/qzone-go"
+	"github.com/guohuiyuan/qzonewall-go/internal/anon"
+	"github.com/guohuiyuan/qzonewall-go/internal/artifact"
+	"github.com/guohuiyuan/qzonewall-go/internal/censor"
 	"github.com/guohuiyuan/qzonewall-go/internal/config"
+	"github.com/guohuiyuan/qzonewall-go/internal/quota"
 	"github.com/guohuiyuan/qzonewall-go/internal/render"
 	"github.com/guohuiyuan/qzonewall-go/internal/source"
 	"github.com/guohuiyuan/qzonewall-go/internal/store"
 	"github.com/guohuiyuan/qzonewall-go/internal/task"
+	"github.com/guohuiyuan/qzonewall-go/internal/validate"
 	"github.com/guohuiyuan/qzonewall-go/internal/web"
 )
 
@@ -76,17 +81,38 @@ func main() {
 	}()
 	log.Println("[Main] sqlite ready")
 
-	censorWords := store.LoadCensorWords(cfg.Censor.Words, cfg.Censor.WordsFile)
-	log.Printf("[Main] loaded censor words: %d", len(censorWords))
+	censorEngine, err := censor.NewEngine(cfg.Censor, st)
+	if err != nil {
+		log.Fatalf("init censor failed: %v", err)
+	}
+	censorEngine.Start()
+	defer censorEngine.Stop()
 
 	renderer := render.NewRenderer()
+	if err := renderer.ApplyConfig(cfg.Wall.Render); err != nil {
+		log.Fatalf("apply render config failed: %v", err)
+	}
 	if renderer.Available() {
 		log.Println("[Main] renderer enabled")
 	} else {
 		log.Println("[Main] renderer disabled")
 	}
 
-	qqBot := source.NewQQBot(cfg.Bot, cfg.Wall, cfg.Qzone, st, renderer, nil, censorWords)
+	namer, err := anon.FromConfig(cfg.Wall, st)
+	if err != nil {
+		log.Fatalf("init anon namer failed: %v", err)
+	}
+
+	artifacts, err := artifact.New(cfg.Database.MediaDir, st)
+	if err != nil {
+		log.Fatalf("init artifact store failed: %v", err)
+	}
+
+	limiter := quota.New(st)
+	validator := validate.New(cfg.Wall, censorEngine, st)
+	validator.SetImages(source.NewImageSource(), censorEngine)
+
+	qqBot := source.NewQQBot(cfg.Bot, cfg.Wall, cfg.Qzone, st, renderer, nil, namer, artifacts, limiter, validator, censorEngine)
 	if err := qqBot.Start(); err != nil {
 		log.Fatalf("start qq bot failed: %v", err)
 	}
@@ -131,7 +157,7 @@ func main() {
 
 	qqBot.SetClient(qzClient)
 
-	worker := task.NewWorker(cfg.Worker, cfg.Wall, qzClient, st, renderer)
+	worker := task.NewWorker(cfg.Worker, cfg.Wall, qzClient, st, renderer, artifacts)
 	worker.Start()
 	defer worker.Stop()
 
@@ -140,7 +166,7 @@ func main() {
 	defer keepAlive.Stop()
 
 	if cfg.Web.Enable {
-		webServer := web.NewServer(cfg.Web, cfg.Wall, st, qzClient, renderer)
+		webServer := web.NewServer(cfg.Web, cfg.Wall, st, qzClient, renderer, namer, artifacts, limiter, validator, censorEngine)
 		go func() {
 			if err := webServer.Start(); err != nil {
 				log.Printf("[Main] web server stopped: %v", err)
@@ -0,0 +1,105 @@
+// Package anon 为匿名稿件生成加盐的匿名标识。
+//
+// 标识 = HMAC(密钥, 周期|投稿者)，同一投稿者在同一周期内得到相同的假名和头像，
+// 换了周期 (日/周) 或换了墙之后无法关联。
+package anon
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/config"
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	"github.com/guohuiyuan/qzonewall-go/internal/store"
+)
+
+// 轮换周期
+const (
+	RotateDay  = "day"
+	RotateWeek = "week"
+	RotateWall = "wall"
+)
+
+// secretSetting 自动生成的密钥在 settings 表中的键名
+const secretSetting = "anon_secret"
+
+// Namer 计算匿名标识
+type Namer struct {
+	secret []byte
+	rotate string
+	wall   string
+}
+
+// New 创建 Namer
+func New(secret, rotate, wall string) *Namer {
+	switch rotate {
+	case RotateDay, RotateWeek, RotateWall:
+	default:
+		rotate = RotateDay
+	}
+	return &Namer{secret: []byte(secret), rotate: rotate, wall: wall}
+}
+
+// FromConfig 按墙配置创建 Namer；未配置密钥时读取数据库中的密钥，首次运行自动生成
+func FromConfig(cfg config.WallConfig, st *store.Store) (*Namer, error) {
+	secret := cfg.Anon.Secret
+	if secret == "" {
+		saved, err := st.GetSetting(secretSetting)
+		if err != nil {
+			return nil, fmt.Errorf("read anon secret: %w", err)
+		}
+		if saved == "" {
+			buf := make([]byte, 32)
+			if _, err := rand.Read(buf); err != nil {
+				return nil, fmt.Errorf("generate anon secret: %w", err)
+			}
+			saved = hex.EncodeToString(buf)
+			if err := st.SetSetting(secretSetting, saved); err != nil {
+				return nil, fmt.Errorf("save anon secret: %w", err)
+			}
+			log.Println("[Anon] 已生成匿名假名密钥")
+		}
+		secret = saved
+	}
+	return New(secret, cfg.Anon.Rotate, cfg.Name), nil
+}
+
+// period 返回 t 所在的轮换周期。墙名始终参与计算，不同墙之间不可关联
+func (n *Namer) period(t time.Time) string {
+	switch n.rotate {
+	case RotateWall:
+		return n.wall
+	case RotateWeek:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%s|%d-W%02d", n.wall, year, week)
+	}
+	return n.wall + "|" + t.Format("2006-01-02")
+}
+
+// Key 计算投稿者 identity 在 t 所在周期内的匿名标识 (16 位十六进制)
+func (n *Namer) Key(identity string, t time.Time) string {
+	mac := hmac.New(sha256.New, n.secret)
+	mac.Write([]byte(n.period(t) + "|" + identity))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// Apply 为匿名稿件填充匿名标识 (已有标识的不变)。identity 为空时使用 QQ 号
+func (n *Namer) Apply(p *model.Post, identity string) {
+	if n == nil || !p.Anon || p.AnonKey != "" {
+		return
+	}
+	if identity == "" {
+		identity = strconv.FormatInt(p.UIN, 10)
+	}
+	t := time.Now()
+	if p.CreateTime > 0 {
+		t = time.Unix(p.CreateTime, 0)
+	}
+	p.AnonKey = n.Key(identity, t)
+}
@@ -0,0 +1,80 @@
+package anon
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+)
+
+func TestKeyRotation(t *testing.T) {
+	day1 := time.Date(2024, 2, 14, 9, 0, 0, 0, time.Local)
+	day1Late := time.Date(2024, 2, 14, 23, 0, 0, 0, time.Local)
+	day2 := time.Date(2024, 2, 15, 9, 0, 0, 0, time.Local)
+
+	n := New("secret", RotateDay, "表白墙")
+	if n.Key("10001", day1) != n.Key("10001", day1Late) {
+		t.Error("same user on the same day should get the same key")
+	}
+	if n.Key("10001", day1) == n.Key("10001", day2) {
+		t.Error("key should rotate across days")
+	}
+	if n.Key("10001", day1) == n.Key("10002", day1) {
+		t.Error("different users should get different keys")
+	}
+
+	other := New("secret", RotateDay, "另一面墙")
+	if n.Key("10001", day1) == other.Key("10001", day1) {
+		t.Error("key should differ across walls")
+	}
+	salted := New("another secret", RotateDay, "表白墙")
+	if n.Key("10001", day1) == salted.Key("10001", day1) {
+		t.Error("key should depend on the secret")
+	}
+
+	wall := New("secret", RotateWall, "表白墙")
+	if wall.Key("10001", day1) != wall.Key("10001", day2.AddDate(0, 3, 0)) {
+		t.Error("wall rotation should keep the key stable over time")
+	}
+	week := New("secret", RotateWeek, "表白墙")
+	if week.Key("10001", day1) != week.Key("10001", day2) {
+		t.Error("week rotation should keep the key within the week")
+	}
+}
+
+func TestApply(t *testing.T) {
+	n := New("secret", RotateDay, "表白墙")
+	p := &model.Post{UIN: 10001, Anon: true, CreateTime: 1707916440}
+	n.Apply(p, "")
+	if p.AnonKey == "" {
+		t.Fatal("anon post should get a key")
+	}
+	if name := p.ShowName(); name == "匿名用户" || name == "" {
+		t.Errorf("ShowName = %q, want an alias", name)
+	}
+	if model.AliasName(p.AnonKey) != p.ShowName() {
+		t.Error("alias should be derived from the key")
+	}
+
+	named := &model.Post{UIN: 10001, Name: "张三"}
+	n.Apply(named, "")
+	if named.AnonKey != "" || named.ShowName() != "张三" {
+		t.Error("non-anonymous posts keep their name")
+	}
+}
+
+// TestAliasSpread 同一天的投稿者假名不应轻易重名
+func TestAliasSpread(t *testing.T) {
+	n := New("secret", RotateDay, "表白墙")
+	day := time.Date(2024, 2, 14, 9, 0, 0, 0, time.Local)
+	seen := map[string]string{}
+	for i := 0; i < 200; i++ {
+		id := fmt.Sprintf("ip:10.0.0.%d", i)
+		name := model.AliasName(n.Key(id, day))
+		if prev, ok := seen[name]; ok {
+			t.Fatalf("%s and %s share alias %q", prev, id, name)
+		}
+		seen[name] = id
+	}
+}
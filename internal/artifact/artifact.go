@@ -0,0 +1,123 @@
+// Package artifact 保存稿件的渲染产物。
+//
+// 发布时实际使用的图片按内容哈希命名存放在产物目录，数据库按版本记录。
+// 之后查看稿件直接读取保存的图片，不再重新下载配图 (可能已过期) 和渲染。
+package artifact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	"github.com/guohuiyuan/qzonewall-go/internal/store"
+)
+
+// fileName 产物文件名：32 位十六进制哈希 + 扩展名
+var fileName = regexp.MustCompile(`^[0-9a-f]{32}\.(jpg|png|gif|webp)$`)
+
+// Store 渲染产物存储
+type Store struct {
+	dir string
+	db  *store.Store
+}
+
+// New 创建产物存储，目录不存在时自动创建
+func New(dir string, db *store.Store) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create artifact dir: %w", err)
+	}
+	return &Store{dir: dir, db: db}, nil
+}
+
+// Save 保存一组图片为稿件的新版本，返回版本号。s 为 nil 时不做任何事
+func (s *Store) Save(postID int64, images [][]byte, published bool) (int, error) {
+	if s == nil || len(images) == 0 {
+		return 0, nil
+	}
+	files := make([]string, 0, len(images))
+	for _, data := range images {
+		name, err := s.write(data)
+		if err != nil {
+			return 0, err
+		}
+		files = append(files, name)
+	}
+	return s.db.SaveRenders(postID, files, published)
+}
+
+// write 按内容哈希写入文件，相同内容只保存一份
+func (s *Store) write(data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	name := hex.EncodeToString(sum[:16]) + extOf(data)
+	path := filepath.Join(s.dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return name, nil
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return "", fmt.Errorf("write artifact: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("write artifact: %w", err)
+	}
+	return name, nil
+}
+
+func extOf(data []byte) string {
+	switch http.DetectContentType(data) {
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	}
+	return ".jpg"
+}
+
+// Versions 列出稿件的全部渲染图，新版本在前
+func (s *Store) Versions(postID int64) ([]*model.Render, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return s.db.ListRenders(postID)
+}
+
+// Latest 返回稿件最新版本的渲染图，没有时返回 nil
+func (s *Store) Latest(postID int64) ([]*model.Render, error) {
+	all, err := s.Versions(postID)
+	if err != nil || len(all) == 0 {
+		return nil, err
+	}
+	var out []*model.Render
+	for _, r := range all {
+		if r.Version != all[0].Version {
+			break
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// Path 返回产物文件的路径，文件名不合法时返回 false
+func (s *Store) Path(name string) (string, bool) {
+	if s == nil || !fileName.MatchString(name) {
+		return "", false
+	}
+	return filepath.Join(s.dir, name), true
+}
+
+// Read 读取产物文件
+func (s *Store) Read(name string) ([]byte, error) {
+	path, ok := s.Path(name)
+	if !ok {
+		return nil, fmt.Errorf("invalid artifact name: %q", name)
+	}
+	return os.ReadFile(path)
+}
@@ -0,0 +1,90 @@
+package artifact
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/store"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := store.New(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	s, err := New(filepath.Join(dir, "renders"), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSaveVersions(t *testing.T) {
+	s := newTestStore(t)
+	png := []byte("\x89PNG\r\n\x1a\n fake png")
+	jpg := []byte("\xff\xd8\xff fake jpeg")
+
+	v1, err := s.Save(1, [][]byte{png, jpg}, true)
+	if err != nil || v1 != 1 {
+		t.Fatalf("first save: version=%d err=%v", v1, err)
+	}
+	v2, err := s.Save(1, [][]byte{png}, false)
+	if err != nil || v2 != 2 {
+		t.Fatalf("second save: version=%d err=%v", v2, err)
+	}
+	if v, _ := s.Save(2, [][]byte{jpg}, true); v != 1 {
+		t.Errorf("versions should be counted per post, got %d", v)
+	}
+
+	all, err := s.Versions(1)
+	if err != nil || len(all) != 3 {
+		t.Fatalf("versions: %d, err=%v", len(all), err)
+	}
+	if all[0].Version != 2 || all[0].Published {
+		t.Errorf("newest version should come first: %+v", all[0])
+	}
+	if all[1].File == all[2].File || filepath.Ext(all[1].File) != ".png" || filepath.Ext(all[2].File) != ".jpg" {
+		t.Errorf("unexpected files: %s, %s", all[1].File, all[2].File)
+	}
+	if all[0].File != all[1].File {
+		t.Error("identical images should share one file")
+	}
+
+	latest, err := s.Latest(1)
+	if err != nil || len(latest) != 1 || latest[0].Version != 2 {
+		t.Fatalf("latest: %+v, err=%v", latest, err)
+	}
+	data, err := s.Read(latest[0].File)
+	if err != nil || !bytes.Equal(data, png) {
+		t.Errorf("read back: %q, err=%v", data, err)
+	}
+	if none, err := s.Latest(3); err != nil || none != nil {
+		t.Errorf("post without renders: %v, err=%v", none, err)
+	}
+}
+
+func TestPathRejectsTraversal(t *testing.T) {
+	s := newTestStore(t)
+	for _, name := range []string{"../test.db", "abc.jpg", "0123456789abcdef0123456789abcdef.exe", ""} {
+		if _, ok := s.Path(name); ok {
+			t.Errorf("%q should be rejected", name)
+		}
+	}
+	if _, ok := s.Path("0123456789abcdef0123456789abcdef.png"); !ok {
+		t.Error("valid name rejected")
+	}
+}
+
+func TestNilStore(t *testing.T) {
+	var s *Store
+	if v, err := s.Save(1, [][]byte{{1}}, true); v != 0 || err != nil {
+		t.Errorf("nil store save: %d, %v", v, err)
+	}
+	if r, err := s.Latest(1); r != nil || err != nil {
+		t.Errorf("nil store latest: %v, %v", r, err)
+	}
+}
@@ -0,0 +1,92 @@
+package censor
+
+// automaton Aho-Corasick 自动机，一次扫描找出所有模式串的所有出现位置
+type automaton struct {
+	nodes []acNode
+}
+
+type acNode struct {
+	next map[rune]int32
+	fail int32
+	out  []int32 // 以该节点结尾的模式编号，含失败链上的
+}
+
+// match 一次匹配：模式编号与在输入中的结束下标 (不含)
+type match struct {
+	pattern int
+	end     int
+}
+
+// newAutomaton 用模式串构建自动机，模式编号即下标，空串忽略
+func newAutomaton(patterns [][]rune) *automaton {
+	a := &automaton{nodes: []acNode{{}}}
+	for i, p := range patterns {
+		if len(p) == 0 {
+			continue
+		}
+		cur := int32(0)
+		for _, r := range p {
+			next, ok := a.nodes[cur].next[r]
+			if !ok {
+				next = int32(len(a.nodes))
+				a.nodes = append(a.nodes, acNode{})
+				if a.nodes[cur].next == nil {
+					a.nodes[cur].next = make(map[rune]int32)
+				}
+				a.nodes[cur].next[r] = next
+			}
+			cur = next
+		}
+		a.nodes[cur].out = append(a.nodes[cur].out, int32(i))
+	}
+
+	// 按层 BFS 计算失败指针，并把失败节点的输出并入当前节点
+	queue := make([]int32, 0, len(a.nodes))
+	for _, child := range a.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for r, child := range a.nodes[cur].next {
+			f := a.nodes[cur].fail
+			for {
+				if n, ok := a.nodes[f].next[r]; ok && n != child {
+					a.nodes[child].fail = n
+					break
+				}
+				if f == 0 {
+					break
+				}
+				f = a.nodes[f].fail
+			}
+			if fo := a.nodes[a.nodes[child].fail].out; len(fo) > 0 {
+				a.nodes[child].out = append(a.nodes[child].out, fo...)
+			}
+			queue = append(queue, child)
+		}
+	}
+	return a
+}
+
+// find 扫描输入，返回所有匹配
+func (a *automaton) find(text []rune) []match {
+	var matches []match
+	cur := int32(0)
+	for i, r := range text {
+		for {
+			if n, ok := a.nodes[cur].next[r]; ok {
+				cur = n
+				break
+			}
+			if cur == 0 {
+				break
+			}
+			cur = a.nodes[cur].fail
+		}
+		for _, p := range a.nodes[cur].out {
+			matches = append(matches, match{pattern: int(p), end: i + 1})
+		}
+	}
+	return matches
+}
@@ -0,0 +1,219 @@
+// Package censor 敏感词检测：文本先规范化 (全角、繁体、形近字母、分隔符、零宽字符)，
+// 再用 Aho-Corasick 自动机一次扫描找出全部命中，可选按拼音匹配
+package censor
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+//go:generate go run ./gen
+
+// minPinyinRunes 按拼音匹配的词至少包含的汉字数，单字拼音太短，容易误伤
+const minPinyinRunes = 2
+
+// Hit 一次命中，位置按字符 (rune) 计算
+type Hit struct {
+	Word   string `json:"word"`   // 命中的规则 (词表中的写法或正则)
+	Action Action `json:"action"` // 处理方式
+	Start  int    `json:"start"`  // 在原文中的起始位置
+	End    int    `json:"end"`    // 在原文中的结束位置 (不含)
+	Text   string `json:"text"`   // 原文中对应的片段
+}
+
+// Options 匹配选项
+type Options struct {
+	Pinyin bool // 同时按拼音匹配，可发现 "wei xin"、"威信" 之类的写法
+}
+
+// Matcher 敏感词匹配器，构建后只读，可并发使用；nil 时不命中任何内容
+type Matcher struct {
+	rules     []Rule
+	plain     *automaton // 规范化后的词，模式编号即 rules 下标
+	plainLen  []int
+	latin     []bool     // 规范化后全是英文字母的词，只匹配完整单词
+	pinyin    *automaton // 词的拼音，Options.Pinyin 关闭时为空
+	pinyinLen []int
+	regexps   []regexRule
+}
+
+type regexRule struct {
+	re   *regexp.Regexp
+	rule Rule
+}
+
+// New 用规则构建匹配器。词在匹配前同样会被规范化，规范化后为空的词忽略；
+// 规范化后相同的词合并为一条，取最严格的处理方式
+func New(rules []Rule, opt Options) *Matcher {
+	m := &Matcher{}
+	seen := make(map[string]int, len(rules))
+	var patterns, pinyins [][]rune
+	for _, r := range rules {
+		if r.Regex {
+			if re, err := regexp.Compile(r.Pattern); err == nil {
+				m.regexps = append(m.regexps, regexRule{re: re, rule: r})
+			}
+			continue
+		}
+		r.Pattern = strings.TrimSpace(r.Pattern)
+		norm := normalize(r.Pattern).text
+		if len(norm) == 0 {
+			continue
+		}
+		if i, ok := seen[string(norm)]; ok {
+			if r.Action.severity() > m.rules[i].Action.severity() {
+				m.rules[i].Action = r.Action
+			}
+			continue
+		}
+		seen[string(norm)] = len(m.rules)
+		m.rules = append(m.rules, r)
+		patterns = append(patterns, norm)
+		m.plainLen = append(m.plainLen, len(norm))
+		m.latin = append(m.latin, isLatin(norm))
+		if opt.Pinyin {
+			py := pinyinPattern(norm)
+			pinyins = append(pinyins, py)
+			m.pinyinLen = append(m.pinyinLen, len(py))
+		}
+	}
+	m.plain = newAutomaton(patterns)
+	if opt.Pinyin {
+		m.pinyin = newAutomaton(pinyins)
+	}
+	return m
+}
+
+// Words 用同一处理方式把词表转为规则
+func Words(words []string, action Action) []Rule {
+	rules := make([]Rule, 0, len(words))
+	for _, w := range words {
+		rules = append(rules, Rule{Pattern: w, Action: action})
+	}
+	return rules
+}
+
+// pinyinPattern 词的拼音形式，汉字少于 minPinyinRunes 个时返回空 (不参与拼音匹配)
+func pinyinPattern(norm []rune) []rune {
+	n := normalized{text: norm, pos: make([]int, len(norm))}
+	han := 0
+	for _, r := range norm {
+		if _, ok := pinyinOf[r]; ok {
+			han++
+		}
+	}
+	if han < minPinyinRunes {
+		return nil
+	}
+	return n.toPinyin().text
+}
+
+// Len 有效规则数
+func (m *Matcher) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.rules) + len(m.regexps)
+}
+
+// Find 返回文本中的全部命中，按位置排序；同一位置同一个词只报告一次。
+// 正则规则直接匹配原文
+func (m *Matcher) Find(text string) []Hit {
+	if m.Len() == 0 || text == "" {
+		return nil
+	}
+	runes := []rune(text)
+	norm := normalize(text)
+	type key struct{ word, start, end int }
+	seen := make(map[key]bool)
+	var hits []Hit
+	collect := func(n normalized, a *automaton, lengths []int, latin func(int) bool) {
+		for _, mt := range a.find(n.text) {
+			if !n.aligned(mt.end-lengths[mt.pattern], mt.end) {
+				continue
+			}
+			start := n.pos[mt.end-lengths[mt.pattern]]
+			end := n.pos[mt.end-1] + 1
+			k := key{mt.pattern, start, end}
+			if seen[k] || latin(mt.pattern) && !wordBoundary(runes, start, end) {
+				continue
+			}
+			seen[k] = true
+			r := m.rules[mt.pattern]
+			hits = append(hits, Hit{Word: r.Pattern, Action: r.Action, Start: start, End: end, Text: string(runes[start:end])})
+		}
+	}
+	collect(norm, m.plain, m.plainLen, func(i int) bool { return m.latin[i] })
+	if m.pinyin != nil {
+		collect(norm.toPinyin(), m.pinyin, m.pinyinLen, func(int) bool { return true })
+	}
+	for _, rr := range m.regexps {
+		for _, loc := range rr.re.FindAllStringIndex(text, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+			start := utf8.RuneCountInString(text[:loc[0]])
+			end := start + utf8.RuneCountInString(text[loc[0]:loc[1]])
+			hits = append(hits, Hit{Word: "/" + rr.rule.Pattern + "/", Action: rr.rule.Action, Start: start, End: end, Text: text[loc[0]:loc[1]]})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Start != hits[j].Start {
+			return hits[i].Start < hits[j].Start
+		}
+		return hits[i].End > hits[j].End
+	})
+	return hits
+}
+
+// isLatin 是否全是英文字母
+func isLatin(norm []rune) bool {
+	for _, r := range norm {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// wordBoundary 原文 [start, end) 前后是否都不是英文字母。
+// 去掉分隔符后英文词容易跨词误伤，例如 "it's big" 中的 "sb"
+func wordBoundary(runes []rune, start, end int) bool {
+	letter := func(r rune) bool {
+		r = fold(r)
+		return r >= 'a' && r <= 'z'
+	}
+	return (start == 0 || !letter(runes[start-1])) && (end == len(runes) || !letter(runes[end]))
+}
+
+// LoadWords 加载敏感词列表（配置中的词 + 文件，文件每行一个词，# 开头为注释）
+func LoadWords(words []string, filePath string) []string {
+	result := make([]string, 0, len(words))
+	for _, w := range words {
+		if w = strings.TrimSpace(w); w != "" {
+			result = append(result, w)
+		}
+	}
+	if filePath == "" {
+		return result
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		return result
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		w := strings.TrimSpace(sc.Text())
+		if w != "" && !strings.HasPrefix(w, "#") && utf8.ValidString(w) {
+			result = append(result, w)
+		}
+	}
+	return result
+}
@@ -0,0 +1,133 @@
+package censor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFindEvasions(t *testing.T) {
+	m := New(Words([]string{"微信", "代写", "广告", "qq"}, ActionBlock), Options{})
+	cases := map[string]string{
+		"加我微信":           "微信",
+		"加我 微 - 信":       "微信",
+		"加我微\u200b信":     "微信",
+		"專業代寫論文":         "代写",
+		"承接廣告":           "广告",
+		"加ＱＱ１２３":         "qq",
+		"加 𝐪𝐪 123":       "qq",
+		"加 ԛԛ 123":       "qq",
+		"Q.Q 联系":         "qq",
+		"today qq me":    "qq",
+		"没有问题的正常投稿":      "",
+		"unique quality": "",
+	}
+	for text, want := range cases {
+		hits := m.Find(text)
+		got := ""
+		if len(hits) > 0 {
+			got = hits[0].Word
+		}
+		if got != want {
+			t.Errorf("Find(%q) = %+v; want %q", text, hits, want)
+		}
+	}
+}
+
+func TestFindPositions(t *testing.T) {
+	m := New(Words([]string{"代写", "代写论文", "论文"}, ActionBlock), Options{})
+	hits := m.Find("承接 代 写论文，联系我")
+	want := []Hit{
+		{Word: "代写论文", Action: ActionBlock, Start: 3, End: 8, Text: "代 写论文"},
+		{Word: "代写", Action: ActionBlock, Start: 3, End: 6, Text: "代 写"},
+		{Word: "论文", Action: ActionBlock, Start: 6, End: 8, Text: "论文"},
+	}
+	if fmt.Sprint(hits) != fmt.Sprint(want) {
+		t.Errorf("hits = %+v; want %+v", hits, want)
+	}
+}
+
+func TestFindPinyin(t *testing.T) {
+	words := []string{"微信", "代"}
+	if hits := New(Words(words, ActionBlock), Options{}).Find("加 wei xin"); len(hits) != 0 {
+		t.Errorf("pinyin matching should be off by default: %+v", hits)
+	}
+	m := New(Words(words, ActionBlock), Options{Pinyin: true})
+	hits := m.Find("加 wei xin 或者威信")
+	if len(hits) != 2 || hits[0].Text != "wei xin" || hits[1].Text != "威信" {
+		t.Errorf("pinyin hits = %+v", hits)
+	}
+	// 拼音只按完整音节匹配："担心" 的 danxin 中包含 anxin，但不是 "安心"
+	m2 := New(Words([]string{"安心"}, ActionBlock), Options{Pinyin: true})
+	if hits := m2.Find("我很担心你"); len(hits) != 0 {
+		t.Errorf("pinyin should not match across syllables: %+v", hits)
+	}
+	if hits := m2.Find("心肝心"); len(hits) != 0 {
+		t.Errorf("pinyin should not match part of a syllable: %+v", hits)
+	}
+	if hits := m2.Find("祝你岸心"); len(hits) != 1 || hits[0].Text != "岸心" {
+		t.Errorf("homophone hits = %+v", hits)
+	}
+	// 单字不按拼音匹配
+	if hits := m.Find("dai"); len(hits) != 0 {
+		t.Errorf("single characters should not match by pinyin: %+v", hits)
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	cases := map[string]Rule{
+		"兼职":            {Pattern: "兼职", Action: ActionBlock},
+		"review:刷单":     {Pattern: "刷单", Action: ActionReview},
+		"打码： 傻瓜":        {Pattern: "傻瓜", Action: ActionMask},
+		`mask:/\d{11}/`: {Pattern: `\d{11}`, Action: ActionMask, Regex: true},
+		"http://a.cn":   {Pattern: "http://a.cn", Action: ActionBlock},
+	}
+	for line, want := range cases {
+		if got, err := ParseRule(line, ActionBlock); err != nil || got != want {
+			t.Errorf("ParseRule(%q) = %+v, %v; want %+v", line, got, err, want)
+		}
+	}
+	for _, line := range []string{"", "review:", "/(/"} {
+		if _, err := ParseRule(line, ActionBlock); err == nil {
+			t.Errorf("ParseRule(%q) should fail", line)
+		}
+	}
+}
+
+func TestRuleActions(t *testing.T) {
+	m := New([]Rule{
+		{Pattern: "兼职", Action: ActionReview},
+		{Pattern: "兼 职", Action: ActionBlock},
+		{Pattern: "笨蛋", Action: ActionMask},
+		{Pattern: `1\d{10}`, Action: ActionReview, Regex: true},
+	}, Options{})
+	hits := m.Find("笨蛋兼职，电话13800138000")
+	want := []Hit{
+		{Word: "笨蛋", Action: ActionMask, Start: 0, End: 2, Text: "笨蛋"},
+		{Word: "兼职", Action: ActionBlock, Start: 2, End: 4, Text: "兼职"},
+		{Word: `/1\d{10}/`, Action: ActionReview, Start: 7, End: 18, Text: "13800138000"},
+	}
+	if fmt.Sprint(hits) != fmt.Sprint(want) {
+		t.Errorf("hits = %+v; want %+v", hits, want)
+	}
+}
+
+func TestNilMatcher(t *testing.T) {
+	var m *Matcher
+	if m.Find("微信") != nil || m.Len() != 0 {
+		t.Error("nil matcher should not match")
+	}
+}
+
+func BenchmarkFind(b *testing.B) {
+	words := make([]string, 5000)
+	for i := range words {
+		words[i] = fmt.Sprintf("敏感词%d号", i)
+	}
+	m := New(Words(words, ActionBlock), Options{Pinyin: true})
+	text := strings.Repeat("今天在食堂看到一个很可爱的同学，想认识一下，有没有人知道是谁。", 20)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Find(text)
+	}
+}
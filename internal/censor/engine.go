@@ -0,0 +1,314 @@
+package censor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/config"
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	"github.com/guohuiyuan/qzonewall-go/internal/store"
+)
+
+// watchInterval 检查规则文件是否变化的间隔
+const watchInterval = 5 * time.Second
+
+// 配置和规则文件中的规则的分类
+const (
+	CategoryConfig = "配置"
+	CategoryFile   = "规则文件"
+)
+
+// Engine 运行中的敏感词引擎：合并配置、规则文件和数据库中的规则，
+// 规则变化后重新构建匹配器并原子替换，正在进行的匹配不受影响。nil 时不命中任何内容
+type Engine struct {
+	cfg     config.CensorConfig
+	def     Action
+	db      *store.Store
+	matcher atomic.Pointer[Matcher]
+	static  atomic.Pointer[[]Rule] // 配置和规则文件中的规则
+	images  atomic.Pointer[[]imageEntry]
+
+	mu     sync.Mutex // 串行化 Reload
+	stamp  fileStamp
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// imageEntry 解析后的图片黑名单
+type imageEntry struct {
+	hash  ImageHash
+	block *model.ImageBlock
+}
+
+// ImageHit 图片命中黑名单
+type ImageHit struct {
+	Block    *model.ImageBlock
+	Action   Action
+	Distance int
+}
+
+// fileStamp 规则文件的修改时间和大小，用于发现变化
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewEngine 创建引擎并加载规则
+func NewEngine(cfg config.CensorConfig, db *store.Store) (*Engine, error) {
+	def, err := ParseAction(cfg.Action)
+	if err != nil {
+		return nil, fmt.Errorf("censor.action: %w", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &Engine{cfg: cfg, def: def, db: db, ctx: ctx, cancel: cancel}
+	if err := e.Reload(); err != nil {
+		cancel()
+		return nil, err
+	}
+	return e, nil
+}
+
+// Find 用当前的匹配器查找命中
+func (e *Engine) Find(text string) []Hit {
+	if e == nil {
+		return nil
+	}
+	return e.matcher.Load().Find(text)
+}
+
+// Len 当前生效的规则数
+func (e *Engine) Len() int {
+	if e == nil {
+		return 0
+	}
+	return e.matcher.Load().Len()
+}
+
+// DefaultAction 未写处理方式的规则使用的默认处理 (censor.action)
+func (e *Engine) DefaultAction() Action {
+	if e == nil {
+		return ActionBlock
+	}
+	return e.def
+}
+
+// StaticRules 配置和规则文件中的规则，只能通过修改配置或文件变更
+func (e *Engine) StaticRules() []Rule {
+	if e == nil {
+		return nil
+	}
+	if p := e.static.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// Reload 重新读取全部规则并替换匹配器；读取数据库失败时保留原匹配器
+func (e *Engine) Reload() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var stamp fileStamp
+	static := ParseRules(e.cfg.Words, e.def)
+	for i := range static {
+		static[i].Category = CategoryConfig
+	}
+	if e.cfg.WordsFile != "" {
+		// 先记录文件状态再读取，读取期间的修改留给下一轮检查
+		stamp = statFile(e.cfg.WordsFile)
+		fileRules := ParseRules(LoadWords(nil, e.cfg.WordsFile), e.def)
+		for i := range fileRules {
+			fileRules[i].Category = CategoryFile
+		}
+		static = append(static, fileRules...)
+	}
+
+	rules := append([]Rule(nil), static...)
+	var images []imageEntry
+	if e.db != nil {
+		words, err := e.db.ListCensorWords()
+		if err != nil {
+			return fmt.Errorf("读取敏感词失败: %w", err)
+		}
+		for _, w := range words {
+			rules = append(rules, Rule{Pattern: w.Pattern, Action: Action(w.Action), Regex: w.Regex, Category: w.Category})
+		}
+		blocks, err := e.db.ListImageBlocks()
+		if err != nil {
+			return fmt.Errorf("读取图片黑名单失败: %w", err)
+		}
+		for _, b := range blocks {
+			h, err := ParseImageHash(b.Hash)
+			if err != nil {
+				log.Printf("[Censor] 跳过图片黑名单 #%d: %v", b.ID, err)
+				continue
+			}
+			images = append(images, imageEntry{hash: h, block: b})
+		}
+	}
+
+	m := New(rules, Options{Pinyin: e.cfg.Pinyin})
+	e.stamp = stamp
+	e.static.Store(&static)
+	e.matcher.Store(m)
+	e.images.Store(&images)
+	log.Printf("[Censor] loaded %d rules, %d blocked images", m.Len(), len(images))
+	return nil
+}
+
+// FindImage 在图片黑名单中查找与 h 最相近且距离不超过 censor.image_distance 的一项
+func (e *Engine) FindImage(h ImageHash) *ImageHit {
+	if e == nil || e.cfg.ImageDistance < 0 {
+		return nil
+	}
+	var hit *ImageHit
+	for _, entry := range *e.images.Load() {
+		d := entry.hash.Distance(h)
+		if d <= e.cfg.ImageDistance && (hit == nil || d < hit.Distance) {
+			hit = &ImageHit{Block: entry.block, Action: Action(entry.block.Action), Distance: d}
+		}
+	}
+	return hit
+}
+
+// ImageBlocks 当前生效的图片黑名单数
+func (e *Engine) ImageBlocks() int {
+	if e == nil {
+		return 0
+	}
+	return len(*e.images.Load())
+}
+
+// AddWord 校验并保存一条规则，然后重新加载
+func (e *Engine) AddWord(w *model.CensorWord) error {
+	if e == nil || e.db == nil {
+		return fmt.Errorf("未连接数据库")
+	}
+	w.Pattern = strings.TrimSpace(w.Pattern)
+	if w.Pattern == "" {
+		return fmt.Errorf("敏感词不能为空")
+	}
+	if _, err := ParseAction(w.Action); err != nil {
+		return err
+	}
+	if w.Regex {
+		if _, err := ParseRule("/"+w.Pattern+"/", e.def); err != nil {
+			return err
+		}
+	} else if len(normalize(w.Pattern).text) == 0 {
+		return fmt.Errorf("敏感词不能只包含符号或空白")
+	}
+	if w.Category = strings.TrimSpace(w.Category); w.Category == "" {
+		w.Category = model.DefaultCensorCategory
+	}
+	if err := e.db.SaveCensorWord(w); err != nil {
+		return err
+	}
+	return e.Reload()
+}
+
+// RemoveWord 删除数据库中的规则 ("/正则/" 表示正则规则) 并重新加载，返回是否存在
+func (e *Engine) RemoveWord(pattern string) (bool, error) {
+	if e == nil || e.db == nil {
+		return false, fmt.Errorf("未连接数据库")
+	}
+	pattern = strings.TrimSpace(pattern)
+	regex := len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/")
+	if regex {
+		pattern = pattern[1 : len(pattern)-1]
+	}
+	ok, err := e.db.RemoveCensorWord(pattern, regex)
+	if err != nil || !ok {
+		return ok, err
+	}
+	return true, e.Reload()
+}
+
+// AddImage 校验并保存一条图片黑名单，然后重新加载。图片只能拒收或复核
+func (e *Engine) AddImage(b *model.ImageBlock) error {
+	if e == nil || e.db == nil {
+		return fmt.Errorf("未连接数据库")
+	}
+	h, err := ParseImageHash(b.Hash)
+	if err != nil {
+		return err
+	}
+	// 纯色、空白图片的指纹为 0，拉黑会误伤所有类似的图片
+	if h == 0 {
+		return fmt.Errorf("图片过于单调，无法加入黑名单")
+	}
+	a, err := ParseAction(b.Action)
+	if err != nil {
+		return err
+	}
+	if a == ActionMask {
+		return fmt.Errorf("图片不支持打码，可选 block/review")
+	}
+	b.Action = string(a)
+	b.Note = strings.TrimSpace(b.Note)
+	if err := e.db.SaveImageBlock(b); err != nil {
+		return err
+	}
+	return e.Reload()
+}
+
+// RemoveImage 删除一条图片黑名单并重新加载，返回是否存在
+func (e *Engine) RemoveImage(id int64) (bool, error) {
+	if e == nil || e.db == nil {
+		return false, fmt.Errorf("未连接数据库")
+	}
+	ok, err := e.db.RemoveImageBlock(id)
+	if err != nil || !ok {
+		return ok, err
+	}
+	return true, e.Reload()
+}
+
+// Start 开始监视规则文件，文件变化后自动重新加载
+func (e *Engine) Start() {
+	if e.cfg.WordsFile == "" {
+		return
+	}
+	go e.watch()
+	log.Printf("[Censor] watching %s", e.cfg.WordsFile)
+}
+
+// Stop 停止监视
+func (e *Engine) Stop() { e.cancel() }
+
+func (e *Engine) watch() {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+			e.mu.Lock()
+			changed := statFile(e.cfg.WordsFile) != e.stamp
+			e.mu.Unlock()
+			if !changed {
+				continue
+			}
+			log.Printf("[Censor] %s changed, reloading", e.cfg.WordsFile)
+			if err := e.Reload(); err != nil {
+				log.Printf("[Censor] reload failed: %v", err)
+			}
+		}
+	}
+}
+
+// statFile 文件不存在时返回零值，之后再创建也能发现
+func statFile(path string) fileStamp {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: fi.ModTime(), size: fi.Size()}
+}
@@ -0,0 +1,117 @@
+package censor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/config"
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	"github.com/guohuiyuan/qzonewall-go/internal/store"
+)
+
+func TestEngineReload(t *testing.T) {
+	dir := t.TempDir()
+	db, err := store.New(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	file := filepath.Join(dir, "words.txt")
+	if err := os.WriteFile(file, []byte("# 注释\n代写\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := NewEngine(config.CensorConfig{Words: []string{"review:兼职"}, WordsFile: file, Action: "block"}, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Stop()
+	if e.Len() != 2 || len(e.Find("兼职代写")) != 2 {
+		t.Fatalf("static rules not loaded: %d", e.Len())
+	}
+
+	// 后台添加的规则立即生效
+	if err := e.AddWord(&model.CensorWord{Pattern: "刷单", Action: "mask"}); err != nil {
+		t.Fatal(err)
+	}
+	hits := e.Find("刷单")
+	if len(hits) != 1 || hits[0].Action != ActionMask || hits[0].Word != "刷单" {
+		t.Errorf("hits after add = %+v", hits)
+	}
+	if err := e.AddWord(&model.CensorWord{Pattern: "(", Action: "block", Regex: true}); err == nil {
+		t.Error("invalid regex should be rejected")
+	}
+	if ok, err := e.RemoveWord("刷单"); !ok || err != nil {
+		t.Errorf("RemoveWord = %v, %v", ok, err)
+	}
+	if hits := e.Find("刷单"); len(hits) != 0 {
+		t.Errorf("hits after remove = %+v", hits)
+	}
+	if ok, _ := e.RemoveWord("代写"); ok {
+		t.Error("rules from the words file cannot be removed")
+	}
+
+	// 规则文件变化后重新加载
+	if err := os.WriteFile(file, []byte("代写\n论文\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if len(e.Find("论文")) != 1 {
+		t.Error("words file change not picked up")
+	}
+	for _, r := range e.StaticRules() {
+		if r.Pattern == "论文" && r.Category != CategoryFile {
+			t.Errorf("file rule category = %q", r.Category)
+		}
+	}
+}
+
+func TestEngineImages(t *testing.T) {
+	db, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	e, err := NewEngine(config.CensorConfig{Action: "block", ImageDistance: 4}, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := ImageHash(0xf0f0f0f0f0f0f0f0)
+	if err := e.AddImage(&model.ImageBlock{Hash: h.String(), Action: "复核", Note: "二维码"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range []*model.ImageBlock{
+		{Hash: ImageHash(0).String(), Action: "block"},
+		{Hash: h.String(), Action: "mask"},
+		{Hash: "xyz", Action: "block"},
+	} {
+		if err := e.AddImage(b); err == nil {
+			t.Errorf("AddImage(%+v) should fail", b)
+		}
+	}
+
+	hit := e.FindImage(h ^ 0b111)
+	if hit == nil || hit.Action != ActionReview || hit.Distance != 3 || hit.Block.Note != "二维码" {
+		t.Fatalf("FindImage = %+v", hit)
+	}
+	if hit := e.FindImage(h ^ 0b11111); hit != nil {
+		t.Errorf("distance 5 should not match: %+v", hit)
+	}
+	if ok, err := e.RemoveImage(hit.Block.ID); !ok || err != nil {
+		t.Errorf("RemoveImage = %v, %v", ok, err)
+	}
+	if e.FindImage(h) != nil || e.ImageBlocks() != 0 {
+		t.Error("removed image should not match")
+	}
+}
+
+func TestNilEngine(t *testing.T) {
+	var e *Engine
+	if e.Find("微信") != nil || e.Len() != 0 || e.StaticRules() != nil || e.FindImage(1) != nil {
+		t.Error("nil engine should not match")
+	}
+}
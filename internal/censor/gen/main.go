@@ -0,0 +1,91 @@
+// Package main generates the censor normalization tables (t2s.txt and pinyin.txt).
+//
+// 数据来自 ICU 的转写规则，需要安装 uconv (Debian/Ubuntu: icu-devtools)：
+//   - t2s.txt: 繁体到简体的单字映射，每行 "繁 简"
+//   - pinyin.txt: GB2312 汉字的无声调拼音，每行 "拼音 字字字…"
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// CJK 统一表意文字基本区
+const first, last = 0x4E00, 0x9FFF
+
+func main() {
+	var all strings.Builder
+	for r := rune(first); r <= last; r++ {
+		all.WriteRune(r)
+		all.WriteByte('\n')
+	}
+	chars := lines(all.String())
+
+	simplified := lines(uconv(all.String(), "-x", "Traditional-Simplified"))
+	var t2s strings.Builder
+	t2s.WriteString("# 由 go generate 生成，请勿手动修改\n")
+	for i, c := range chars {
+		if s := simplified[i]; s != c && utf8.RuneCountInString(s) == 1 {
+			fmt.Fprintf(&t2s, "%s %s\n", c, s)
+		}
+	}
+	write("t2s.txt", t2s.String())
+
+	// 先繁转简再匹配，只需覆盖 GB2312 中的简体字
+	gb := lines(uconv(uconv(all.String(), "-t", "GB2312", "-c"), "-f", "GB2312"))
+	readings := lines(uconv(all.String(), "-x", "Han-Latin; Latin-ASCII; Lower"))
+	groups := make(map[string][]string)
+	for i, c := range chars {
+		py := readings[i]
+		if gb[i] != c || py == c || strings.Trim(py, "abcdefghijklmnopqrstuvwxyz") != "" {
+			continue
+		}
+		groups[py] = append(groups[py], c)
+	}
+	syllables := make([]string, 0, len(groups))
+	for py := range groups {
+		syllables = append(syllables, py)
+	}
+	sort.Strings(syllables)
+	var pinyin strings.Builder
+	pinyin.WriteString("# 由 go generate 生成，请勿手动修改\n")
+	for _, py := range syllables {
+		fmt.Fprintf(&pinyin, "%s %s\n", py, strings.Join(groups[py], ""))
+	}
+	write("pinyin.txt", pinyin.String())
+}
+
+// uconv 调用 ICU 的 uconv 转换文本
+func uconv(input string, args ...string) string {
+	cmd := exec.Command("uconv", args...)
+	cmd.Stdin = strings.NewReader(input)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		log.Fatalf("uconv %v: %v", args, err)
+	}
+	return out.String()
+}
+
+// lines 按行拆分，转换结果与输入逐行对应
+func lines(s string) []string {
+	out := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	if len(out) != last-first+1 {
+		log.Fatalf("unexpected line count %d", len(out))
+	}
+	return out
+}
+
+func write(name, content string) {
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %s", name)
+}
@@ -0,0 +1,79 @@
+package censor
+
+import (
+	"fmt"
+	"image"
+	"math/bits"
+	"strconv"
+)
+
+// ImageHash 图片的感知哈希 (dHash)：缩放、重新压缩、轻微调色后基本不变，
+// 两张图片的哈希按汉明距离比较，距离越小越相似
+type ImageHash uint64
+
+const (
+	hashW = 9 // 每行 9 个采样格，相邻两格比较得到 8 位
+	hashH = 8
+	// samples 每个采样格在每个方向上的取样点数，避免遍历大图的全部像素
+	samples = 12
+)
+
+// HashImage 计算 dHash：将图片缩小为 9×8 的灰度图，每行相邻两格右侧更亮时记 1
+func HashImage(img image.Image) ImageHash {
+	var gray [hashH][hashW]float64
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return 0
+	}
+	for gy := 0; gy < hashH; gy++ {
+		for gx := 0; gx < hashW; gx++ {
+			var sum float64
+			for sy := 0; sy < samples; sy++ {
+				// 取样点落在采样格内均匀分布的位置上
+				y := b.Min.Y + ((gy*samples+sy)*2+1)*h/(hashH*samples*2)
+				for sx := 0; sx < samples; sx++ {
+					x := b.Min.X + ((gx*samples+sx)*2+1)*w/(hashW*samples*2)
+					sum += luminance(img, x, y)
+				}
+			}
+			gray[gy][gx] = sum
+		}
+	}
+	var hash ImageHash
+	for y := 0; y < hashH; y++ {
+		for x := 0; x < hashW-1; x++ {
+			hash <<= 1
+			if gray[y][x] < gray[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// luminance 像素的亮度，透明部分按白色背景计算
+func luminance(img image.Image, x, y int) float64 {
+	r, g, b, a := img.At(x, y).RGBA()
+	white := float64(0xffff - a)
+	return 0.299*(float64(r)+white) + 0.587*(float64(g)+white) + 0.114*(float64(b)+white)
+}
+
+// Distance 两个哈希的汉明距离 (0~64)
+func (h ImageHash) Distance(o ImageHash) int {
+	return bits.OnesCount64(uint64(h ^ o))
+}
+
+// String 16 位十六进制
+func (h ImageHash) String() string {
+	return fmt.Sprintf("%016x", uint64(h))
+}
+
+// ParseImageHash 解析 String 的结果
+func ParseImageHash(s string) (ImageHash, error) {
+	v, err := strconv.ParseUint(s, 16, 64)
+	if err != nil || len(s) != 16 {
+		return 0, fmt.Errorf("无效的图片指纹 %q", s)
+	}
+	return ImageHash(v), nil
+}
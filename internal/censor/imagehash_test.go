@@ -0,0 +1,64 @@
+package censor
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"golang.org/x/image/draw"
+)
+
+// testPattern 生成带渐变和方块的测试图片，seed 不同时图案不同
+func testPattern(w, h, seed int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8((x*255/w + seed*y*255/h) % 256)
+			if (x*8/w+y*8/h+seed)%3 == 0 {
+				v = 255 - v
+			}
+			img.Set(x, y, color.RGBA{v, v / 2, 255 - v, 255})
+		}
+	}
+	return img
+}
+
+func TestHashImage(t *testing.T) {
+	orig := testPattern(400, 300, 1)
+	h := HashImage(orig)
+
+	// 缩小后再 JPEG 压缩
+	small := image.NewRGBA(image.Rect(0, 0, 160, 120))
+	draw.BiLinear.Scale(small, small.Bounds(), orig, orig.Bounds(), draw.Src, nil)
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, small, &jpeg.Options{Quality: 40}); err != nil {
+		t.Fatal(err)
+	}
+	recompressed, err := jpeg.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := h.Distance(HashImage(recompressed)); d > 4 {
+		t.Errorf("resized copy distance = %d", d)
+	}
+	if d := h.Distance(HashImage(testPattern(400, 300, 2))); d < 16 {
+		t.Errorf("different image distance = %d", d)
+	}
+	if HashImage(image.NewGray(image.Rect(0, 0, 50, 50))) != 0 {
+		t.Error("uniform image should hash to 0")
+	}
+}
+
+func TestParseImageHash(t *testing.T) {
+	h := ImageHash(0x0123456789abcdef)
+	if got, err := ParseImageHash(h.String()); err != nil || got != h {
+		t.Errorf("ParseImageHash(%q) = %v, %v", h.String(), got, err)
+	}
+	for _, s := range []string{"", "123", "zz23456789abcdef"} {
+		if _, err := ParseImageHash(s); err == nil {
+			t.Errorf("ParseImageHash(%q) should fail", s)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package censor
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+//go:embed t2s.txt
+var t2sData string
+
+//go:embed pinyin.txt
+var pinyinData string
+
+var (
+	tablesOnce sync.Once
+	t2s        map[rune]rune
+	pinyinOf   map[rune]string
+)
+
+// loadTables 解析内置的繁简与拼音表
+func loadTables() {
+	tablesOnce.Do(func() {
+		t2s = make(map[rune]rune, 2900)
+		pinyinOf = make(map[rune]string, 6800)
+		for _, line := range strings.Split(t2sData, "\n") {
+			if f := strings.Fields(line); len(f) == 2 && f[0] != "#" {
+				t2s[[]rune(f[0])[0]] = []rune(f[1])[0]
+			}
+		}
+		for _, line := range strings.Split(pinyinData, "\n") {
+			if f := strings.Fields(line); len(f) == 2 && f[0] != "#" {
+				for _, r := range f[1] {
+					pinyinOf[r] = f[0]
+				}
+			}
+		}
+	})
+}
+
+// homoglyphs 形似拉丁字母的西里尔、希腊字母等
+var homoglyphs = map[rune]rune{
+	'а': 'a', 'в': 'b', 'с': 'c', 'ԁ': 'd', 'е': 'e', 'һ': 'h', 'і': 'i', 'ј': 'j',
+	'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p', 'ѕ': 's', 'т': 't', 'у': 'y',
+	'х': 'x', 'ү': 'y', 'ԛ': 'q', 'ԝ': 'w',
+	'α': 'a', 'β': 'b', 'ε': 'e', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o', 'ρ': 'p',
+	'τ': 't', 'υ': 'u', 'χ': 'x', 'ω': 'w',
+	'ı': 'i', 'ℓ': 'l', '〇': '0',
+}
+
+// fold 将单个字符规范化：全角转半角、花体字母和带圈字符转普通字符、
+// 形近字母替换、转小写、繁体转简体。返回 0 表示应当忽略的分隔符或不可见字符
+func fold(r rune) rune {
+	switch {
+	case r >= 0xFF01 && r <= 0xFF5E: // 全角 ASCII
+		r -= 0xFEE0
+	case r >= 0x1D400 && r <= 0x1D6A3: // 数学花体字母，每种字体 52 个
+		r = 'a' + (r-0x1D400)%52%26
+	case r >= 0x1D7CE && r <= 0x1D7FF: // 数学花体数字
+		r = '0' + (r-0x1D7CE)%10
+	case r >= 0x24B6 && r <= 0x24CF: // Ⓐ-Ⓩ
+		r = 'a' + r - 0x24B6
+	case r >= 0x24D0 && r <= 0x24E9: // ⓐ-ⓩ
+		r = 'a' + r - 0x24D0
+	case r >= 0x2460 && r <= 0x2468: // ①-⑨
+		r = '1' + r - 0x2460
+	case r >= 0x2474 && r <= 0x247C: // ⑴-⑼
+		r = '1' + r - 0x2474
+	case r >= 0x2488 && r <= 0x2490: // ⒈-⒐
+		r = '1' + r - 0x2488
+	case r == 0x24EA || r == 0x24FF: // ⓪ ⓿
+		r = '0'
+	}
+	if separator(r) {
+		return 0
+	}
+	r = unicode.ToLower(r)
+	if h, ok := homoglyphs[r]; ok {
+		return h
+	}
+	if s, ok := t2s[r]; ok {
+		return s
+	}
+	return r
+}
+
+// separator 空白、标点、符号、组合附加符和不可见字符，匹配时跳过。
+// 垃圾广告常用 "加 微-信" 或在字间插入零宽字符之类的写法绕过关键词
+func separator(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) ||
+		unicode.In(r, unicode.Cc, unicode.Cf, unicode.Mn, unicode.Me)
+}
+
+// normalized 规范化后的文本，pos[i] 为 text[i] 在原文中的字符下标。
+// 拼音形式中 inside[i] 表示 text[i] 与前一个字母属于同一个汉字的拼音，匹配不能从这里开始或断开
+type normalized struct {
+	text   []rune
+	pos    []int
+	inside []bool
+}
+
+// aligned [start, end) 是否落在完整的音节上，例如 "担心" 的 "danxin" 中不能匹配 "安心" 的 "anxin"
+func (n normalized) aligned(start, end int) bool {
+	if n.inside == nil {
+		return true
+	}
+	return !n.inside[start] && (end == len(n.text) || !n.inside[end])
+}
+
+// normalize 规范化文本，记录每个字符在原文中的位置
+func normalize(s string) normalized {
+	loadTables()
+	n := normalized{text: make([]rune, 0, len(s)), pos: make([]int, 0, len(s))}
+	i := 0
+	for _, r := range s {
+		if f := fold(r); f != 0 {
+			n.text = append(n.text, f)
+			n.pos = append(n.pos, i)
+		}
+		i++
+	}
+	return n
+}
+
+// toPinyin 将规范化文本中的汉字展开为拼音字母，其他字符原样保留
+func (n normalized) toPinyin() normalized {
+	size := len(n.text) * 3
+	out := normalized{text: make([]rune, 0, size), pos: make([]int, 0, size), inside: make([]bool, 0, size)}
+	for i, r := range n.text {
+		py, ok := pinyinOf[r]
+		if !ok {
+			out.text = append(out.text, r)
+			out.pos = append(out.pos, n.pos[i])
+			out.inside = append(out.inside, false)
+			continue
+		}
+		for j, c := range py {
+			out.text = append(out.text, c)
+			out.pos = append(out.pos, n.pos[i])
+			out.inside = append(out.inside, j > 0)
+		}
+	}
+	return out
+}
+
+// Normalize 返回匹配时使用的规范化文本，便于排查某个写法为何命中或漏过
+func Normalize(s string) string {
+	return string(normalize(s).text)
+}
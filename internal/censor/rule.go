@@ -0,0 +1,112 @@
+package censor
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Action 命中规则后的处理方式
+type Action string
+
+const (
+	ActionBlock  Action = "block"  // 拒收投稿，只提示包含不允许的内容
+	ActionReview Action = "review" // 正常收稿，标记给审核员复核
+	ActionMask   Action = "mask"   // 正常收稿，发布时用 ＊ 遮盖
+)
+
+// actionNames 规则前缀可用的写法
+var actionNames = map[string]Action{
+	"block": ActionBlock, "屏蔽": ActionBlock,
+	"review": ActionReview, "复核": ActionReview, "审核": ActionReview,
+	"mask": ActionMask, "打码": ActionMask,
+}
+
+// ParseAction 解析处理方式，支持英文和中文写法
+func ParseAction(s string) (Action, error) {
+	if a, ok := actionNames[strings.ToLower(strings.TrimSpace(s))]; ok {
+		return a, nil
+	}
+	return "", fmt.Errorf("未知的处理方式 %q，可选 block/review/mask", s)
+}
+
+// severity 同一个词出现在多条规则中时取最严格的处理
+func (a Action) severity() int {
+	switch a {
+	case ActionBlock:
+		return 3
+	case ActionReview:
+		return 2
+	}
+	return 1
+}
+
+// Text 处理方式的中文名称
+func (a Action) Text() string {
+	switch a {
+	case ActionBlock:
+		return "屏蔽"
+	case ActionReview:
+		return "复核"
+	case ActionMask:
+		return "打码"
+	}
+	return string(a)
+}
+
+// Rule 一条敏感词规则
+type Rule struct {
+	Pattern  string // 敏感词，或 Regex 时的正则表达式
+	Action   Action
+	Regex    bool   // 正则规则直接匹配原文，不做规范化
+	Category string // 分类；配置和规则文件中的规则为 CategoryConfig、CategoryFile
+}
+
+// ParseRule 解析一行规则，格式为 "[处理方式:]词" 或 "[处理方式:]/正则/"，
+// 省略处理方式时使用 def，例如 "兼职"、"review:刷单"、"打码：/\d{3}-\d{4}/"
+func ParseRule(line string, def Action) (Rule, error) {
+	r := Rule{Pattern: strings.TrimSpace(line), Action: def}
+	if i := strings.IndexAny(r.Pattern, ":："); i > 0 {
+		if a, err := ParseAction(r.Pattern[:i]); err == nil {
+			r.Action = a
+			_, size := utf8.DecodeRuneInString(r.Pattern[i:])
+			r.Pattern = strings.TrimSpace(r.Pattern[i+size:])
+		}
+	}
+	if len(r.Pattern) > 2 && strings.HasPrefix(r.Pattern, "/") && strings.HasSuffix(r.Pattern, "/") {
+		r.Pattern = r.Pattern[1 : len(r.Pattern)-1]
+		r.Regex = true
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return r, fmt.Errorf("正则 %q 无效: %w", r.Pattern, err)
+		}
+	}
+	if r.Pattern == "" {
+		return r, fmt.Errorf("规则为空")
+	}
+	return r, nil
+}
+
+// ParseRules 逐行解析规则，无效的行记录日志后跳过
+func ParseRules(lines []string, def Action) []Rule {
+	rules := make([]Rule, 0, len(lines))
+	for _, line := range lines {
+		r, err := ParseRule(line, def)
+		if err != nil {
+			log.Printf("[Censor] 跳过规则 %q: %v", line, err)
+			continue
+		}
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// String 规则的文本形式，与 ParseRule 互逆
+func (r Rule) String() string {
+	p := r.Pattern
+	if r.Regex {
+		p = "/" + p + "/"
+	}
+	return string(r.Action) + ":" + p
+}
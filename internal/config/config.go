@@ -29,9 +29,11 @@ type QzoneConfig struct {
 
 // BotConfig QQ机器人配置
 type BotConfig struct {
-	Zero        ZeroBotConfig `yaml:"zero"`
-	WS          []WSConfig    `yaml:"ws"`
-	ManageGroup int64         `yaml:"manage_group"`
+	Zero         ZeroBotConfig `yaml:"zero"`
+	WS           []WSConfig    `yaml:"ws"`
+	ManageGroup  int64         `yaml:"manage_group"`
+	WallGroups   []int64       `yaml:"wall_groups"`   // 表白墙所在的群，私聊投稿需是其中某个群的成员；留空时不接受私聊投稿
+	ManageAdmins bool          `yaml:"manage_admins"` // 管理群的群主和管理员自动视为审核员，数据库中另有角色时以数据库为准
 }
 
 // ZeroBotConfig ZeroBot 核心配置
@@ -52,16 +54,60 @@ type WSConfig struct {
 
 // WallConfig 表白墙配置
 type WallConfig struct {
-	ShowAuthor   bool          `yaml:"show_author"`
-	AnonDefault  bool          `yaml:"anon_default"`
-	MaxImages    int           `yaml:"max_images"`
-	MaxTextLen   int           `yaml:"max_text_len"`
-	PublishDelay time.Duration `yaml:"publish_delay"`
+	Name          string          `yaml:"name"`
+	ShowAuthor    bool            `yaml:"show_author"`
+	AnonDefault   bool            `yaml:"anon_default"`
+	MaxImages     int             `yaml:"max_images"`
+	MaxTextLen    int             `yaml:"max_text_len"`
+	PublishDelay  time.Duration   `yaml:"publish_delay"`
+	ApproveQuorum int             `yaml:"approve_quorum"` // 稿件需几名审核员同意才通过，任一人拒绝即否决；所有者可直接通过
+	Render        RenderConfig    `yaml:"render"`
+	Anon          AnonConfig      `yaml:"anon"`
+	RateLimit     RateLimitConfig `yaml:"rate_limit"`
+}
+
+// RateLimitConfig 投稿频率限制 (滑动窗口)，0 为不限制；审核团队成员不受限
+type RateLimitConfig struct {
+	UserPerHour    int `yaml:"user_per_hour"`    // 每人每小时
+	UserPerDay     int `yaml:"user_per_day"`     // 每人每天
+	GroupPerDay    int `yaml:"group_per_day"`    // 每个群每天 (群内投稿)
+	IPPerHour      int `yaml:"ip_per_hour"`      // 网页投稿每个 IP 每小时
+	PreviewPerHour int `yaml:"preview_per_hour"` // 网页投稿预览每人每小时 (未登录按 IP)，默认 30，负数不限制
+}
+
+// AnonConfig 匿名稿件的假名配置
+type AnonConfig struct {
+	Rotate string `yaml:"rotate"` // 假名轮换周期: day / week / wall (每面墙固定)
+	Secret string `yaml:"secret"` // 加盐密钥，留空时自动生成并保存在数据库
+}
+
+// RenderConfig 截图渲染配置
+type RenderConfig struct {
+	Theme  string                 `yaml:"theme"`
+	Output OutputConfig           `yaml:"output"`
+	Themes map[string]ThemeConfig `yaml:"themes"` // 按主题名覆盖
+
+	AttachGIF bool `yaml:"attach_gif"` // 发布时在截图后附带动图原文件 (截图中只显示第一帧)
+}
+
+// ThemeConfig 单个主题的覆盖配置
+type ThemeConfig struct {
+	Output  OutputConfig `yaml:"output"`
+	Justify bool         `yaml:"justify"` // 文字两端对齐
+}
+
+// OutputConfig 截图输出编码配置
+type OutputConfig struct {
+	Format   string  `yaml:"format"`    // jpeg / png / webp / webp-lossless
+	Quality  int     `yaml:"quality"`   // 有损格式质量 1-100
+	MaxBytes int     `yaml:"max_bytes"` // 目标最大字节数，0 表示不限制
+	Scale    float64 `yaml:"scale"`     // HiDPI 倍率
 }
 
 // DatabaseConfig 数据库配置
 type DatabaseConfig struct {
-	Path string `yaml:"path"`
+	Path     string `yaml:"path"`
+	MediaDir string `yaml:"media_dir"` // 发布截图等渲染产物的保存目录
 }
 
 // WebConfig 网页配置
@@ -70,13 +116,19 @@ type WebConfig struct {
 	Addr      string `yaml:"addr"`
 	AdminUser string `yaml:"admin_user"`
 	AdminPass string `yaml:"admin_pass"`
+	// TrustedProxies 可信反向代理的 IP 或网段 (如 "127.0.0.1"、"10.0.0.0/8")。
+	// 只有来自这些地址的请求才按 X-Forwarded-For / X-Real-IP 识别客户端 IP；留空则一律使用连接地址
+	TrustedProxies []string `yaml:"trusted_proxies"`
 }
 
 // CensorConfig 敏感词过滤配置
 type CensorConfig struct {
-	Enable    bool     `yaml:"enable"`
-	Words     []string `yaml:"words"`
-	WordsFile string   `yaml:"words_file"`
+	Enable        bool     `yaml:"enable"`
+	Words         []string `yaml:"words"`          // 每项为一条规则，如 "兼职"、"review:刷单"、"mask:/\d{11}/"
+	WordsFile     string   `yaml:"words_file"`     // 规则文件，每行一条，# 开头为注释
+	Action        string   `yaml:"action"`         // 未写处理方式的规则默认如何处理：block/review/mask
+	Pinyin        bool     `yaml:"pinyin"`         // 同时按拼音匹配 ("wei xin"、同音字)，可能误伤同音词
+	ImageDistance int      `yaml:"image_distance"` // 图片指纹与黑名单的汉明距离不超过该值视为同一张图 (1~64，默认 10)，负数关闭
 }
 
 // WorkerConfig 任务调度配置
@@ -131,9 +183,30 @@ func (c *Config) setDefaults() {
 	if c.Wall.MaxTextLen == 0 {
 		c.Wall.MaxTextLen = 2000
 	}
+	if c.Wall.Name == "" {
+		c.Wall.Name = "表白墙"
+	}
+	if c.Wall.Render.Theme == "" {
+		c.Wall.Render.Theme = "default"
+	}
+	if c.Wall.Anon.Rotate == "" {
+		c.Wall.Anon.Rotate = "day"
+	}
+	if c.Censor.Action == "" {
+		c.Censor.Action = "block"
+	}
+	if c.Wall.RateLimit.PreviewPerHour == 0 {
+		c.Wall.RateLimit.PreviewPerHour = 30
+	}
+	if c.Censor.ImageDistance == 0 {
+		c.Censor.ImageDistance = 10
+	}
 	if c.Database.Path == "" {
 		c.Database.Path = "data.db"
 	}
+	if c.Database.MediaDir == "" {
+		c.Database.MediaDir = "renders"
+	}
 	if c.Web.Addr == "" {
 		c.Web.Addr = ":8080"
 	}
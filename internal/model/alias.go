@@ -0,0 +1,43 @@
+package model
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// aliasNumbers 假名末尾的编号个数。词表只有 48×48 种组合，同一天投稿的人一多就会重名，
+// 加上三位编号后共约 230 万种
+const aliasNumbers = 1000
+
+// 匿名假名词表：形容词 + 动物
+var (
+	aliasAdjectives = []string{
+		"安静", "勇敢", "害羞", "快乐", "认真", "温柔", "机智", "慵懒",
+		"迷糊", "好奇", "淡定", "热情", "腼腆", "活泼", "倔强", "乖巧",
+		"爱笑", "失眠", "早起", "熬夜", "路过", "深情", "靠谱", "无名",
+		"沉默", "勤劳", "佛系", "元气", "文艺", "忧郁", "冷静", "调皮",
+		"憨厚", "细心", "贪吃", "爱哭", "神秘", "孤独", "阳光", "健忘",
+		"骄傲", "纯真", "稳重", "浪漫", "幸运", "执着", "坦率", "悠闲",
+	}
+	aliasAnimals = []string{
+		"水獭", "企鹅", "刺猬", "狐狸", "考拉", "熊猫", "海豹", "仓鼠",
+		"松鼠", "鲸鱼", "海豚", "兔子", "柴犬", "橘猫", "猫头鹰", "小鹿",
+		"浣熊", "树懒", "羊驼", "海獭", "河马", "长颈鹿", "斑马", "袋鼠",
+		"白鹭", "麻雀", "燕子", "锦鲤", "水母", "海星", "章鱼", "螃蟹",
+		"蜗牛", "萤火虫", "蝴蝶", "蜜蜂", "小熊", "小象", "狸花猫", "布偶猫",
+		"金毛", "二哈", "鹦鹉", "天鹅", "鸭子", "大鹅", "青蛙", "乌龟",
+	}
+)
+
+// AliasName 由匿名标识生成假名，例如 "安静的水獭·042"。
+// 同一标识总是得到同一假名；标识无效时返回 "匿名用户"
+func AliasName(key string) string {
+	b, err := hex.DecodeString(key)
+	if err != nil || len(b) < 6 {
+		return "匿名用户"
+	}
+	adj := (int(b[0])<<8 | int(b[1])) % len(aliasAdjectives)
+	animal := (int(b[2])<<8 | int(b[3])) % len(aliasAnimals)
+	num := (int(b[4])<<8 | int(b[5])) % aliasNumbers
+	return fmt.Sprintf("%s的%s·%03d", aliasAdjectives[adj], aliasAnimals[animal], num)
+}
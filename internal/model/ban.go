@@ -0,0 +1,75 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ──────────────────────────────────────────
+// Ban 投稿黑名单
+// ──────────────────────────────────────────
+
+// Ban 一条封禁记录。Target 为 QQ 号、account:用户名 或 ip:地址 (与网页投稿者身份标识一致)
+type Ban struct {
+	Target     string `json:"target"`
+	Reason     string `json:"reason"`
+	ExpireTime int64  `json:"expire_time"` // 0 表示永久
+	Operator   string `json:"operator"`
+	CreateTime int64  `json:"create_time"`
+}
+
+// QQBanTarget QQ 用户的封禁标识
+func QQBanTarget(uin int64) string {
+	return strconv.FormatInt(uin, 10)
+}
+
+// Active 封禁在 now 时是否仍有效
+func (b *Ban) Active(now time.Time) bool {
+	return b.ExpireTime == 0 || now.Unix() < b.ExpireTime
+}
+
+// Until 封禁期限的描述
+func (b *Ban) Until() string {
+	if b.ExpireTime == 0 {
+		return "永久"
+	}
+	return "至 " + time.Unix(b.ExpireTime, 0).Format("2006-01-02 15:04")
+}
+
+// Notice 告知被封禁用户的提示
+func (b *Ban) Notice() string {
+	msg := "🚫 你已被禁止投稿 (" + b.Until() + ")"
+	if b.Reason != "" {
+		msg += "\n理由: " + b.Reason
+	}
+	return msg
+}
+
+// banUnits 封禁时长单位
+var banUnits = map[string]time.Duration{
+	"m": time.Minute, "分": time.Minute, "分钟": time.Minute,
+	"h": time.Hour, "时": time.Hour, "小时": time.Hour,
+	"d": 24 * time.Hour, "天": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "周": 7 * 24 * time.Hour,
+}
+
+// ParseBanDuration 解析封禁时长，如 30m、12h、7d、3天、2周；空、"永久" 或 0 返回 0 (永久)
+func ParseBanDuration(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" || s == "永久" || s == "0" {
+		return 0, nil
+	}
+	i := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) })
+	if i <= 0 {
+		return 0, fmt.Errorf("时长格式不正确: %s", s)
+	}
+	n, err := strconv.Atoi(s[:i])
+	unit, ok := banUnits[s[i:]]
+	if err != nil || !ok || n <= 0 {
+		return 0, fmt.Errorf("时长格式不正确: %s", s)
+	}
+	return time.Duration(n) * unit, nil
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseBanDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"":    0,
+		"永久":  0,
+		"30m": 30 * time.Minute,
+		"12H": 12 * time.Hour,
+		"7d":  7 * 24 * time.Hour,
+		"3天":  3 * 24 * time.Hour,
+		"2周":  14 * 24 * time.Hour,
+	}
+	for in, want := range cases {
+		if got, err := ParseBanDuration(in); err != nil || got != want {
+			t.Errorf("ParseBanDuration(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"d", "7", "7x", "-1d", "垃圾广告"} {
+		if _, err := ParseBanDuration(in); err == nil {
+			t.Errorf("ParseBanDuration(%q) should fail", in)
+		}
+	}
+}
+
+func TestBanActive(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	if !(&Ban{}).Active(now) {
+		t.Error("permanent ban should be active")
+	}
+	if (&Ban{ExpireTime: now.Unix()}).Active(now) {
+		t.Error("ban should end at its expire time")
+	}
+}
@@ -0,0 +1,30 @@
+package model
+
+// ──────────────────────────────────────────
+// CensorWord 后台维护的敏感词规则
+// ──────────────────────────────────────────
+
+// CensorWord 数据库中的一条敏感词规则，与配置文件中的规则合并使用
+type CensorWord struct {
+	ID         int64  `json:"id"`
+	Pattern    string `json:"pattern"`  // 敏感词，Regex 时为正则表达式
+	Action     string `json:"action"`   // block / review / mask
+	Regex      bool   `json:"regex"`    // 是否为正则规则
+	Category   string `json:"category"` // 分类，如 "广告"、"辱骂"
+	AddedBy    string `json:"added_by"` // 添加人，格式同投票人 (QQ 123 / 网页 admin)
+	CreateTime int64  `json:"create_time"`
+}
+
+// DefaultCensorCategory 未填写分类时使用的分类
+const DefaultCensorCategory = "未分类"
+
+// ImageBlock 图片黑名单中的一项，按感知哈希匹配相似图片
+type ImageBlock struct {
+	ID         int64  `json:"id"`
+	Hash       string `json:"hash"`    // 图片指纹，16 位十六进制
+	Action     string `json:"action"`  // block / review
+	Note       string `json:"note"`    // 说明，如 "广告二维码"
+	PostID     int64  `json:"post_id"` // 来源稿件，0 表示手动添加
+	AddedBy    string `json:"added_by"`
+	CreateTime int64  `json:"create_time"`
+}
@@ -0,0 +1,144 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Flag 投稿时校验留下的标记，如命中需复核或需打码的敏感词
+type Flag struct {
+	Action string `json:"action"` // FlagReview 或 FlagMask
+	Word   string `json:"word"`   // 命中的规则
+	Text   string `json:"text"`   // 原文中的片段
+	Start  int    `json:"start"`  // 片段在 Post.Text 中的位置 (按字符)
+	End    int    `json:"end"`
+	Image  int    `json:"image,omitempty"` // 命中图片黑名单时为第几张图片 (从 1 开始)
+}
+
+const (
+	FlagReview = "review" // 需审核员留意
+	FlagMask   = "mask"   // 发布时用 ＊ 遮盖
+)
+
+// maskRune 打码使用的全角星号
+const maskRune = "＊"
+
+// FlagText 给审核员的标记说明，没有标记时返回空
+func (p *Post) FlagText() string {
+	var review, mask []string
+	seen := make(map[string]bool)
+	for _, f := range p.Flags {
+		key := f.Action + "\x00" + f.Word
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		switch f.Action {
+		case FlagReview:
+			review = append(review, f.Word)
+		case FlagMask:
+			mask = append(mask, f.Word)
+		}
+	}
+	var parts []string
+	if len(review) > 0 {
+		parts = append(parts, "需复核："+strings.Join(review, "、"))
+	}
+	if len(mask) > 0 {
+		parts = append(parts, "已打码："+strings.Join(mask, "、"))
+	}
+	return strings.Join(parts, "；")
+}
+
+// Masked 返回发布用的副本，打码片段替换为 ＊；没有打码标记时返回自身
+func (p *Post) Masked() *Post {
+	var frags []string
+	for _, f := range p.Flags {
+		if f.Action == FlagMask && f.Text != "" {
+			frags = append(frags, f.Text, strings.Repeat(maskRune, utf8.RuneCountInString(f.Text)))
+		}
+	}
+	if len(frags) == 0 {
+		return p
+	}
+	r := strings.NewReplacer(frags...)
+	cp := *p
+	cp.Text = r.Replace(p.Text)
+	cp.Segments = maskSegments(p.Segments, r)
+	if len(p.Chat) > 0 {
+		cp.Chat = make([]ChatMessage, len(p.Chat))
+		for i, m := range p.Chat {
+			m.Segments = maskSegments(m.Segments, r)
+			cp.Chat[i] = m
+		}
+	}
+	return &cp
+}
+
+// summaryLen 合并发布时每篇稿件摘要的字数
+const summaryLen = 20
+
+// PublishLine 合并发布时说说正文中的一行摘要，取打码后正文的前 20 字
+func (p *Post) PublishLine() string {
+	text := []rune(p.Masked().Text)
+	switch {
+	case len(text) == 0:
+		return fmt.Sprintf("#%d: [图片]", p.ID)
+	case len(text) > summaryLen:
+		return fmt.Sprintf("#%d: %s...", p.ID, string(text[:summaryLen]))
+	}
+	return fmt.Sprintf("#%d: %s", p.ID, string(text))
+}
+
+func maskSegments(segs []Segment, r *strings.Replacer) []Segment {
+	if segs == nil {
+		return nil
+	}
+	out := make([]Segment, len(segs))
+	for i, seg := range segs {
+		if seg.Type == SegText {
+			seg.Text = r.Replace(seg.Text)
+		}
+		out[i] = seg
+	}
+	return out
+}
+
+// ImageFlag 第 i 张图片 (从 0 开始) 的标记，没有时返回空
+func (p *Post) ImageFlag(i int) string {
+	for _, f := range p.Flags {
+		if f.Image == i+1 {
+			return f.Action
+		}
+	}
+	return ""
+}
+
+// TextSpan 正文中的一段，Action 非空表示该段带有标记
+type TextSpan struct {
+	Text   string
+	Action string
+}
+
+// FlagSpans 按标记切分正文，供管理后台高亮命中片段；复核优先于打码
+func (p *Post) FlagSpans() []TextSpan {
+	runes := []rune(p.Text)
+	marks := make([]string, len(runes))
+	for _, f := range p.Flags {
+		for i := max(f.Start, 0); i < f.End && i < len(runes); i++ {
+			if marks[i] != FlagReview {
+				marks[i] = f.Action
+			}
+		}
+	}
+	var spans []TextSpan
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i == len(runes) || marks[i] != marks[start] {
+			spans = append(spans, TextSpan{Text: string(runes[start:i]), Action: marks[start]})
+			start = i
+		}
+	}
+	return spans
+}
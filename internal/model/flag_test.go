@@ -0,0 +1,50 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFlags(t *testing.T) {
+	p := &Post{
+		Text: "招兼职，不要笨蛋@小明",
+		Segments: []Segment{
+			{Type: SegText, Text: "招兼职，不要笨蛋"},
+			{Type: SegAt, Text: "小明", ID: "10001"},
+		},
+		Flags: []Flag{
+			{Action: FlagReview, Word: "兼职", Text: "兼职", Start: 1, End: 3},
+			{Action: FlagMask, Word: "笨蛋", Text: "笨蛋", Start: 6, End: 8},
+		},
+	}
+	want := "[{招 } {兼职 review} {，不要 } {笨蛋 mask} {@小明 }]"
+	if got := fmt.Sprint(p.FlagSpans()); got != want {
+		t.Errorf("spans = %s; want %s", got, want)
+	}
+	m := p.Masked()
+	if m.Text != "招兼职，不要＊＊@小明" || m.Segments[0].Text != "招兼职，不要＊＊" || m.Segments[1].Text != "小明" {
+		t.Errorf("masked = %q %+v", m.Text, m.Segments)
+	}
+	if p.Segments[0].Text != "招兼职，不要笨蛋" {
+		t.Error("Masked should not modify the original post")
+	}
+	if p.FlagText() != "需复核：兼职；已打码：笨蛋" {
+		t.Errorf("flag text = %q", p.FlagText())
+	}
+}
+
+func TestPublishLineMasked(t *testing.T) {
+	p := &Post{ID: 3, Text: "笨蛋才不来", Flags: []Flag{{Action: FlagMask, Word: "笨蛋", Text: "笨蛋", Start: 0, End: 2}}}
+	if got := p.PublishLine(); got != "#3: ＊＊才不来" {
+		t.Errorf("publish line = %q", got)
+	}
+	p.Text = "今天食堂的饭很好吃，但是打饭的阿姨说我是笨蛋"
+	p.Flags[0].Start, p.Flags[0].End = 20, 22
+	if got := p.PublishLine(); strings.Contains(got, "笨") || got != "#3: 今天食堂的饭很好吃，但是打饭的阿姨说我是..." {
+		t.Errorf("publish line = %q", got)
+	}
+	if got := (&Post{ID: 4}).PublishLine(); got != "#4: [图片]" {
+		t.Errorf("publish line = %q", got)
+	}
+}
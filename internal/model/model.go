@@ -20,29 +20,142 @@ const (
 	StatusPublished PostStatus = "published" // 已发布到QQ空间
 )
 
+var statusText = map[PostStatus]string{
+	StatusPending:   "待审核",
+	StatusApproved:  "已通过",
+	StatusRejected:  "已拒绝",
+	StatusFailed:    "失败",
+	StatusPublished: "已发布",
+}
+
+// Text 状态的中文名称
+func (s PostStatus) Text() string {
+	if v, ok := statusText[s]; ok {
+		return v
+	}
+	return string(s)
+}
+
 // ──────────────────────────────────────────
 // Post 投稿/说说
 // ──────────────────────────────────────────
 
 type Post struct {
-	ID         int64      `json:"id"`
-	TID        string     `json:"tid,omitempty"`      // QQ空间说说ID（发布后回填）
-	UIN        int64      `json:"uin"`                // 投稿者QQ
-	Name       string     `json:"name"`               // 投稿者昵称
-	GroupID    int64      `json:"group_id,omitempty"` // 来源群号
-	Text       string     `json:"text"`               // 文字内容
-	Images     []string   `json:"images,omitempty"`   // 图片URL列表
-	Anon       bool       `json:"anon"`               // 是否匿名
-	Status     PostStatus `json:"status"`
-	Reason     string     `json:"reason,omitempty"`     // 拒绝理由
-	AvatarURL  string     `json:"avatar_url,omitempty"` // 头像URL
-	CreateTime int64      `json:"create_time"`
-	UpdateTime int64      `json:"update_time,omitempty"`
-}
-
-// ShowName 显示名称
+	ID          int64         `json:"id"`
+	TID         string        `json:"tid,omitempty"`             // QQ空间说说ID（发布后回填）
+	UIN         int64         `json:"uin"`                       // 投稿者QQ
+	Name        string        `json:"name"`                      // 投稿者昵称
+	GroupID     int64         `json:"group_id,omitempty"`        // 来源群号
+	Text        string        `json:"text"`                      // 文字内容
+	Images      []string      `json:"images,omitempty"`          // 图片URL列表
+	ImageHashes []string      `json:"image_hashes,omitempty"`    // 与 Images 一一对应的图片指纹，计算失败时为空
+	Segments    []Segment     `json:"segments,omitempty"`        // 结构化消息段 (QQ投稿)
+	Chat        []ChatMessage `json:"chat,omitempty"`            // 聊天记录投稿的逐条消息
+	Anon        bool          `json:"anon"`                      // 是否匿名
+	AnonKey     string        `json:"-"`                         // 匿名标识 (加盐哈希)，用于生成假名和头像
+	Warning     bool          `json:"content_warning,omitempty"` // 内容警告：配图模糊显示，清晰原图附在截图之后
+	WarnReason  string        `json:"warn_reason,omitempty"`     // 内容警告说明 (可为空)
+	Flags       []Flag        `json:"flags,omitempty"`           // 校验标记：需复核或打码的敏感词
+	Status      PostStatus    `json:"status"`
+	Reason      string        `json:"reason,omitempty"`     // 拒绝理由
+	AvatarURL   string        `json:"avatar_url,omitempty"` // 头像URL
+	CreateTime  int64         `json:"create_time"`
+	UpdateTime  int64         `json:"update_time,omitempty"`
+}
+
+// ──────────────────────────────────────────
+// Segment 消息段
+// ──────────────────────────────────────────
+
+const (
+	SegText  = "text"  // 文字
+	SegFace  = "face"  // QQ 系统表情
+	SegAt    = "at"    // @某人
+	SegReply = "reply" // 回复引用
+)
+
+// Segment 投稿中的结构化消息段，图片仍保存在 Post.Images
+type Segment struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"` // text: 文字; at: 显示名; reply: 原消息文字
+	ID   string `json:"id,omitempty"`   // face: 表情ID; at: QQ号; reply: 原消息ID
+	Name string `json:"name,omitempty"` // face: 表情名; reply: 原消息发送者
+}
+
+// PlainText 将消息段转为纯文本 (表情为 [名称]，@ 为 @名称，回复不计入)
+func PlainText(segs []Segment) string {
+	var b strings.Builder
+	for _, seg := range segs {
+		switch seg.Type {
+		case SegText:
+			b.WriteString(seg.Text)
+		case SegFace:
+			b.WriteString("[" + seg.Name + "]")
+		case SegAt:
+			b.WriteString("@" + seg.Text)
+		}
+	}
+	return b.String()
+}
+
+// Reply 返回投稿引用的回复段
+func (p *Post) Reply() *Segment {
+	for i := range p.Segments {
+		if p.Segments[i].Type == SegReply {
+			return &p.Segments[i]
+		}
+	}
+	return nil
+}
+
+// ──────────────────────────────────────────
+// ChatMessage 聊天记录
+// ──────────────────────────────────────────
+
+// ChatMessage 聊天记录投稿中的一条消息
+type ChatMessage struct {
+	Speaker  string    `json:"speaker"`        // 发送者别名
+	UIN      int64     `json:"uin,omitempty"`  // 发送者QQ，用于显示头像
+	Self     bool      `json:"self,omitempty"` // 投稿者一方，显示在右侧
+	Segments []Segment `json:"segments,omitempty"`
+	Images   []int     `json:"images,omitempty"` // 引用 Post.Images 的下标
+}
+
+// ChatText 将聊天记录转为 "别名: 内容" 形式的纯文本
+func ChatText(msgs []ChatMessage) string {
+	lines := make([]string, 0, len(msgs))
+	for _, m := range msgs {
+		text := PlainText(m.Segments)
+		if text == "" && len(m.Images) > 0 {
+			text = "[图片]"
+		}
+		lines = append(lines, m.Speaker+": "+text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// IsChat 是否为聊天记录投稿
+func (p *Post) IsChat() bool {
+	return len(p.Chat) > 0
+}
+
+// WarningLabel 内容警告提示文字，未标记时返回空
+func (p *Post) WarningLabel() string {
+	if !p.Warning {
+		return ""
+	}
+	if p.WarnReason != "" {
+		return "内容警告：" + p.WarnReason
+	}
+	return "内容警告"
+}
+
+// ShowName 显示名称，匿名稿件显示假名
 func (p *Post) ShowName() string {
 	if p.Anon {
+		if p.AnonKey != "" {
+			return AliasName(p.AnonKey)
+		}
 		return "匿名用户"
 	}
 	return p.Name
@@ -64,6 +177,9 @@ func (p *Post) Summary() string {
 	t := time.Unix(p.CreateTime, 0).Format("01-02 15:04")
 	var b strings.Builder
 	fmt.Fprintf(&b, "#%d %s [%s] %s\n", p.ID, p.ShowName(), p.Status, t)
+	if label := p.WarningLabel(); label != "" {
+		b.WriteString("⚠️" + label + "\n")
+	}
 	if p.Text != "" {
 		text := p.Text
 		if len([]rune(text)) > 60 {
@@ -101,6 +217,21 @@ func (p *Post) String() string {
 	return b.String()
 }
 
+// ──────────────────────────────────────────
+// Render 渲染产物
+// ──────────────────────────────────────────
+
+// Render 稿件某个渲染版本中的一张图 (截图或附带的原图)
+type Render struct {
+	ID         int64  `json:"id"`
+	PostID     int64  `json:"post_id"`
+	Version    int    `json:"version"`   // 同一稿件的渲染版本，从 1 开始
+	Index      int    `json:"index"`     // 版本内的顺序，0 为截图
+	File       string `json:"file"`      // 按内容哈希命名的文件名
+	Published  bool   `json:"published"` // 是否为实际发布到空间的版本
+	CreateTime int64  `json:"create_time"`
+}
+
 // ──────────────────────────────────────────
 // Account 网页账号
 // ──────────────────────────────────────────
@@ -110,11 +241,16 @@ type Account struct {
 	Username     string `json:"username"`
 	PasswordHash string `json:"-"`
 	Salt         string `json:"-"`
-	Role         string `json:"role"` // "admin" or "user"
+	Role         Role   `json:"role"`
 	CreateTime   int64  `json:"create_time"`
 }
 
-// IsAdmin 是否为管理员
+// IsAdmin 是否为审核团队成员 (可登录管理后台)
 func (a *Account) IsAdmin() bool {
-	return a.Role == "admin"
+	return a.Role.IsStaff()
+}
+
+// Can 账号是否拥有指定权限
+func (a *Account) Can(p Permission) bool {
+	return a.Role.Can(p)
 }
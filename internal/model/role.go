@@ -0,0 +1,79 @@
+package model
+
+// ──────────────────────────────────────────
+// Role 审核团队角色 (QQ 审核员与网页账号共用)
+// ──────────────────────────────────────────
+
+type Role string
+
+const (
+	RoleOwner     Role = "owner"     // 所有者：全部权限，可管理团队
+	RoleModerator Role = "moderator" // 审核员：审核稿件、发布
+	RoleViewer    Role = "viewer"    // 观察员：只能查看稿件
+	RoleUser      Role = "user"      // 普通用户：只能投稿
+)
+
+// Permission 管理操作的权限
+type Permission string
+
+const (
+	PermView    Permission = "view"    // 查看待审核、稿件详情、截图与预览
+	PermReview  Permission = "review"  // 过稿、拒稿、删稿、内容警告、重新渲染
+	PermPublish Permission = "publish" // 精选合集、直接发说说
+	PermAccount Permission = "account" // 扫码登录、刷新 cookie
+	PermTeam    Permission = "team"    // 添加/移除审核员、修改账号角色
+	PermCensor  Permission = "censor"  // 增删敏感词规则
+)
+
+// rolePerms 各角色拥有的权限
+var rolePerms = map[Role][]Permission{
+	RoleOwner:     {PermView, PermReview, PermPublish, PermAccount, PermTeam, PermCensor},
+	RoleModerator: {PermView, PermReview, PermPublish, PermCensor},
+	RoleViewer:    {PermView},
+}
+
+// Can 角色是否拥有指定权限
+func (r Role) Can(p Permission) bool {
+	for _, perm := range rolePerms[r] {
+		if perm == p {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStaff 是否为审核团队成员 (可进入管理后台)
+func (r Role) IsStaff() bool {
+	return r.Can(PermView)
+}
+
+// Text 角色的中文名
+func (r Role) Text() string {
+	switch r {
+	case RoleOwner:
+		return "所有者"
+	case RoleModerator:
+		return "审核员"
+	case RoleViewer:
+		return "观察员"
+	}
+	return "用户"
+}
+
+// ParseRole 解析角色名，支持英文与中文名
+func ParseRole(s string) (Role, bool) {
+	for _, r := range []Role{RoleOwner, RoleModerator, RoleViewer, RoleUser} {
+		if s == string(r) || s == r.Text() {
+			return r, true
+		}
+	}
+	return "", false
+}
+
+// Moderator QQ 审核团队成员
+type Moderator struct {
+	UIN        int64 `json:"uin"`
+	Role       Role  `json:"role"`
+	AddedBy    int64 `json:"added_by"`
+	CreateTime int64 `json:"create_time"`
+}
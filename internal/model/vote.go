@@ -0,0 +1,71 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ──────────────────────────────────────────
+// Vote 多人审核投票
+// ──────────────────────────────────────────
+
+// Vote 一名审核员对稿件的一票，同一审核员重复投票时覆盖
+type Vote struct {
+	PostID     int64  `json:"post_id"`
+	Voter      string `json:"voter"` // QQVoter / WebVoter
+	Approve    bool   `json:"approve"`
+	Reason     string `json:"reason"`
+	CreateTime int64  `json:"create_time"`
+}
+
+// QQVoter QQ 审核员的投票身份
+func QQVoter(uin int64) string {
+	return fmt.Sprintf("QQ %d", uin)
+}
+
+// WebVoter 网页账号的投票身份
+func WebVoter(username string) string {
+	return "网页 " + username
+}
+
+// VoteTally 稿件的投票统计
+type VoteTally struct {
+	Quorum    int      `json:"quorum"`    // 通过所需的同意票数
+	Approvers []string `json:"approvers"` // 已同意的审核员
+	Vetoed    bool     `json:"vetoed"`    // 有人否决
+}
+
+// TallyVotes 统计投票，quorum 小于 1 时按 1 处理
+func TallyVotes(votes []*Vote, quorum int) *VoteTally {
+	t := &VoteTally{Quorum: max(quorum, 1)}
+	for _, v := range votes {
+		if v.Approve {
+			t.Approvers = append(t.Approvers, v.Voter)
+		} else {
+			t.Vetoed = true
+		}
+	}
+	return t
+}
+
+// Passed 是否已达到通过所需票数且无人否决
+func (t *VoteTally) Passed() bool {
+	return !t.Vetoed && len(t.Approvers) >= t.Quorum
+}
+
+// Remaining 还需要的同意票数
+func (t *VoteTally) Remaining() int {
+	return max(t.Quorum-len(t.Approvers), 0)
+}
+
+// Text 投票进度，如 "同意 1/2 (QQ 10001)"
+func (t *VoteTally) Text() string {
+	if t.Vetoed {
+		return "已被否决"
+	}
+	s := fmt.Sprintf("同意 %d/%d", len(t.Approvers), t.Quorum)
+	if len(t.Approvers) > 0 {
+		s += " (" + strings.Join(t.Approvers, "、") + ")"
+	}
+	return s
+}
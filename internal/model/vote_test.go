@@ -0,0 +1,21 @@
+package model
+
+import "testing"
+
+func TestTallyVotes(t *testing.T) {
+	yes := func(voter string) *Vote { return &Vote{Voter: voter, Approve: true} }
+
+	tally := TallyVotes([]*Vote{yes("QQ 1")}, 2)
+	if tally.Passed() || tally.Remaining() != 1 || tally.Text() != "同意 1/2 (QQ 1)" {
+		t.Errorf("one of two: passed=%v remaining=%d text=%q", tally.Passed(), tally.Remaining(), tally.Text())
+	}
+	if tally = TallyVotes([]*Vote{yes("QQ 1"), yes("网页 a")}, 2); !tally.Passed() {
+		t.Error("two approvals should pass a quorum of two")
+	}
+	if tally = TallyVotes([]*Vote{yes("QQ 1"), yes("QQ 2"), {Voter: "QQ 3"}}, 2); tally.Passed() || tally.Text() != "已被否决" {
+		t.Error("a veto should block approval")
+	}
+	if tally = TallyVotes([]*Vote{yes("QQ 1")}, 0); !tally.Passed() || tally.Quorum != 1 {
+		t.Error("quorum below one should behave as one")
+	}
+}
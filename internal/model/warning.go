@@ -0,0 +1,21 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+// warningTag 投稿开头的内容警告标记：[CW]、[cw 剧透]、【内容警告：血腥】
+var warningTag = regexp.MustCompile(`^\s*[\[【](?i:cw|内容警告)(?:[\s:：]+([^\]】]*))?[\]】]\s*`)
+
+// ParseWarningTag 识别并去掉文字开头的内容警告标记，返回剩余文字、是否标记及说明
+func ParseWarningTag(text string) (rest string, ok bool, reason string) {
+	m := warningTag.FindStringSubmatchIndex(text)
+	if m == nil {
+		return text, false, ""
+	}
+	if m[2] >= 0 {
+		reason = strings.TrimSpace(text[m[2]:m[3]])
+	}
+	return text[m[1]:], true, reason
+}
@@ -0,0 +1,169 @@
+// Package quota 投稿频率限制：滑动窗口计数，记录保存在数据库中，重启后仍然有效
+package quota
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/config"
+	"github.com/guohuiyuan/qzonewall-go/internal/store"
+)
+
+// retention 记录保留时长，需不小于最长的窗口
+const retention = 24 * time.Hour
+
+// Rule 一条限流规则：Key 在 Window 内最多 Limit 次
+type Rule struct {
+	Key    string
+	Limit  int
+	Window time.Duration
+	Desc   string // 规则说明，如 "每人每小时"
+	Verb   string // 计数的操作，为空时为投稿
+}
+
+// Rules 按配置生成规则；user、group、ip 为空的维度不限制
+func Rules(cfg config.RateLimitConfig, user, group, ip string) []Rule {
+	var rules []Rule
+	add := func(key string, limit int, window time.Duration, desc string) {
+		if key != "" && limit > 0 {
+			rules = append(rules, Rule{Key: key, Limit: limit, Window: window, Desc: desc})
+		}
+	}
+	add(user, cfg.UserPerHour, time.Hour, "每人每小时")
+	add(user, cfg.UserPerDay, 24*time.Hour, "每人每天")
+	add(group, cfg.GroupPerDay, 24*time.Hour, "本群每天")
+	add(ip, cfg.IPPerHour, time.Hour, "同一网络每小时")
+	return rules
+}
+
+// PreviewRules 投稿预览的限流规则，与投稿分开计数；user 为空时只按 IP 计
+func PreviewRules(cfg config.RateLimitConfig, user, ip string) []Rule {
+	key := user
+	desc := "每人每小时"
+	if key == "" {
+		key, desc = ip, "同一网络每小时"
+	}
+	if key == "" || cfg.PreviewPerHour <= 0 {
+		return nil
+	}
+	return []Rule{{Key: "preview:" + key, Limit: cfg.PreviewPerHour, Window: time.Hour, Desc: desc, Verb: "预览"}}
+}
+
+// Exceeded 超出限制的规则及可以再次投稿的时间
+type Exceeded struct {
+	Rule    Rule
+	RetryAt time.Time
+}
+
+// Message 拒绝投稿时的提示
+func (e *Exceeded) Message(now time.Time) string {
+	layout := "15:04"
+	if e.RetryAt.YearDay() != now.YearDay() || e.RetryAt.Year() != now.Year() {
+		layout = "01-02 15:04"
+	}
+	if e.Rule.Verb != "" {
+		return fmt.Sprintf("%s太频繁：%s最多%s %d 次，请在 %s 后再试", e.Rule.Verb, e.Rule.Desc, e.Rule.Verb, e.Rule.Limit, e.RetryAt.Format(layout))
+	}
+	return fmt.Sprintf("投稿太频繁：%s最多投稿 %d 篇，请在 %s 后再试", e.Rule.Desc, e.Rule.Limit, e.RetryAt.Format(layout))
+}
+
+// Limiter 滑动窗口限流器，nil 时不限制。
+// 检查与记录在同一把锁内完成 (Take)，并发投稿不会同时通过检查
+type Limiter struct {
+	mu sync.Mutex
+	db *store.Store
+}
+
+// New 创建限流器
+func New(db *store.Store) *Limiter {
+	return &Limiter{db: db}
+}
+
+// Check 检查是否超出任一规则，超出多条时返回最晚可再投稿的一条。只用于提前提示，
+// 真正计数时用 Take
+func (l *Limiter) Check(rules []Rule, now time.Time) (*Exceeded, error) {
+	if l == nil {
+		return nil, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.check(rules, now)
+}
+
+// Take 检查并计入一次：未超出时记录并返回 nil，超出时不记录
+func (l *Limiter) Take(rules []Rule, now time.Time) (*Exceeded, error) {
+	if l == nil {
+		return nil, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ex, err := l.check(rules, now)
+	if err != nil || ex != nil {
+		return ex, err
+	}
+	return nil, l.record(rules, now)
+}
+
+// Record 记录一次投稿，并清理过期记录
+func (l *Limiter) Record(rules []Rule, now time.Time) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.record(rules, now)
+}
+
+// Release 撤销 Take 在 now 计入的一次 (例如投稿保存失败)
+func (l *Limiter) Release(rules []Rule, now time.Time) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.db.DeleteEvents(ruleKeys(rules), now.Unix())
+}
+
+func (l *Limiter) check(rules []Rule, now time.Time) (*Exceeded, error) {
+	var worst *Exceeded
+	for _, r := range rules {
+		times, err := l.db.EventTimes(r.Key, now.Add(-r.Window).Unix())
+		if err != nil {
+			return nil, err
+		}
+		if len(times) < r.Limit {
+			continue
+		}
+		// 最早的 len-limit+1 条记录都滑出窗口后才有空位
+		retry := time.Unix(times[len(times)-r.Limit], 0).Add(r.Window)
+		if worst == nil || retry.After(worst.RetryAt) {
+			worst = &Exceeded{Rule: r, RetryAt: retry}
+		}
+	}
+	return worst, nil
+}
+
+func (l *Limiter) record(rules []Rule, now time.Time) error {
+	keys := ruleKeys(rules)
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := l.db.RecordEvents(keys, now.Unix()); err != nil {
+		return err
+	}
+	return l.db.PruneEvents(now.Add(-retention).Unix())
+}
+
+// ruleKeys 规则涉及的计数键 (去重)
+func ruleKeys(rules []Rule) []string {
+	seen := make(map[string]bool, len(rules))
+	var keys []string
+	for _, r := range rules {
+		if !seen[r.Key] {
+			seen[r.Key] = true
+			keys = append(keys, r.Key)
+		}
+	}
+	return keys
+}
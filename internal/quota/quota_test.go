@@ -0,0 +1,141 @@
+package quota
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/config"
+	"github.com/guohuiyuan/qzonewall-go/internal/store"
+)
+
+func newTestLimiter(t *testing.T) *Limiter {
+	t.Helper()
+	db, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return New(db)
+}
+
+func TestSlidingWindow(t *testing.T) {
+	l := newTestLimiter(t)
+	rules := Rules(config.RateLimitConfig{UserPerHour: 2, UserPerDay: 3}, "qq:1", "", "")
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local)
+
+	// 10:00 和 10:20 各投一篇，第三篇超出每小时限制，要等 11:00 第一篇滑出窗口
+	for _, m := range []int{0, 20} {
+		now := start.Add(time.Duration(m) * time.Minute)
+		if ex, err := l.Check(rules, now); err != nil || ex != nil {
+			t.Fatalf("post at +%dm should pass: %v, %v", m, ex, err)
+		}
+		if err := l.Record(rules, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := start.Add(30 * time.Minute)
+	ex, err := l.Check(rules, now)
+	if err != nil || ex == nil {
+		t.Fatalf("third post within the hour should be limited: %v", err)
+	}
+	if !ex.RetryAt.Equal(start.Add(time.Hour)) || !strings.Contains(ex.Message(now), "11:00") {
+		t.Errorf("retry at %v, message %q", ex.RetryAt, ex.Message(now))
+	}
+
+	// 11:00 后可以再投，之后触发每天 3 篇的限制，要等到次日 10:00
+	now = start.Add(time.Hour + time.Second)
+	if ex, _ := l.Check(rules, now); ex != nil {
+		t.Fatalf("window should have slid: %+v", ex)
+	}
+	_ = l.Record(rules, now)
+	ex, _ = l.Check(rules, now.Add(time.Hour))
+	if ex == nil || ex.Rule.Desc != "每人每天" || !ex.RetryAt.Equal(start.Add(24*time.Hour)) {
+		t.Fatalf("daily limit: %+v", ex)
+	}
+	if !strings.Contains(ex.Message(now), "05-02 10:00") {
+		t.Errorf("message should include the date: %q", ex.Message(now))
+	}
+
+	// 其他用户不受影响
+	if ex, _ := l.Check(Rules(config.RateLimitConfig{UserPerHour: 2}, "qq:2", "", ""), now); ex != nil {
+		t.Error("limits should be per key")
+	}
+}
+
+func TestRulesSkipDisabled(t *testing.T) {
+	rules := Rules(config.RateLimitConfig{UserPerHour: 1, GroupPerDay: 5, IPPerHour: 3}, "qq:1", "", "ip:1.2.3.4")
+	if len(rules) != 2 || rules[0].Key != "qq:1" || rules[1].Key != "ip:1.2.3.4" {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+	var l *Limiter
+	if ex, err := l.Check(rules, time.Now()); ex != nil || err != nil {
+		t.Error("nil limiter should not limit")
+	}
+}
+
+func TestPreviewRules(t *testing.T) {
+	cfg := config.RateLimitConfig{UserPerHour: 1, PreviewPerHour: 2}
+	if rules := PreviewRules(cfg, "", "ip:1.2.3.4"); len(rules) != 1 || rules[0].Key != "preview:ip:1.2.3.4" {
+		t.Fatalf("anonymous preview rules = %+v", rules)
+	}
+	if rules := PreviewRules(config.RateLimitConfig{PreviewPerHour: -1}, "account:a", "ip:1.2.3.4"); rules != nil {
+		t.Errorf("negative limit should disable preview limiting: %+v", rules)
+	}
+
+	// 预览与投稿分开计数
+	l := newTestLimiter(t)
+	rules := PreviewRules(cfg, "account:a", "ip:1.2.3.4")
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local)
+	for i := 0; i < 2; i++ {
+		if err := l.Record(rules, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ex, _ := l.Check(Rules(cfg, "account:a", "", ""), now); ex != nil {
+		t.Errorf("previews should not count as posts: %+v", ex)
+	}
+	ex, err := l.Check(rules, now)
+	if err != nil || ex == nil {
+		t.Fatalf("third preview should be limited: %v, %v", ex, err)
+	}
+	if msg := ex.Message(now); !strings.HasPrefix(msg, "预览太频繁：每人每小时最多预览 2 次") {
+		t.Errorf("message = %q", msg)
+	}
+}
+
+// TestTakeConcurrent 并发投稿时检查与计数不可分割，不会超出限额；Release 归还额度
+func TestTakeConcurrent(t *testing.T) {
+	l := newTestLimiter(t)
+	rules := Rules(config.RateLimitConfig{UserPerHour: 3}, "qq:1", "", "")
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local)
+
+	var wg sync.WaitGroup
+	var passed atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ex, err := l.Take(rules, now); err == nil && ex == nil {
+				passed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if passed.Load() != 3 {
+		t.Fatalf("%d concurrent submissions passed, want 3", passed.Load())
+	}
+
+	if err := l.Release(rules, now); err != nil {
+		t.Fatal(err)
+	}
+	if ex, err := l.Take(rules, now); err != nil || ex != nil {
+		t.Errorf("released slot should be available: %v, %v", ex, err)
+	}
+	if ex, _ := l.Take(rules, now); ex == nil {
+		t.Error("limit should apply again after the slot is taken")
+	}
+}
@@ -0,0 +1,264 @@
+package render
+
+import (
+	"fmt"
+	"hash/fnv"
+	"image"
+	"math"
+
+	"github.com/fogleman/gg"
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+)
+
+// aliasColors 没有头像时别名圆形的底色，按别名哈希选取
+var aliasColors = []string{"#F0A35E", "#6FB3D2", "#8FC46B", "#C98BD8", "#E57373", "#4DB6AC", "#7986CB", "#A1887F"}
+
+// chatBlock 单条消息的排版结果
+type chatBlock struct {
+	msg      model.ChatMessage
+	showName bool
+	lines    [][]inlineRun
+	bubbleW  float64
+	bubbleH  float64
+	images   []image.Image // 已缩放，nil 表示加载失败
+	height   float64
+}
+
+// drawChat 绘制聊天记录投稿：投稿者一方在右，其他人在左
+func (r *Renderer) drawChat(post *model.Post, theme Theme, k float64) image.Image {
+	var (
+		CanvasWidth = 800.0 * k
+		Padding     = 40.0 * k
+		SizeTitle   = 28.0 * k
+		SizeText    = 30.0 * k
+		SizeName    = 22.0 * k
+		SizeMeta    = 22.0 * k
+		AvatarSize  = 64.0 * k
+		AvatarGap   = 16.0 * k
+		BubblePadH  = 24.0 * k
+		BubblePadV  = 18.0 * k
+		NameGap     = 8.0 * k
+		MsgGap      = 24.0 * k
+		ImgGap      = 8.0 * k
+		ImgMax      = 300.0 * k
+		HeaderH     = SizeTitle + 40*k
+		BottomSpace = 50.0 * k
+	)
+	const LineHeight = 1.4
+
+	// 气泡最大宽度：两侧各留出头像位
+	bubbleMaxW := CanvasWidth - 2*Padding - 2*(AvatarSize+AvatarGap)
+
+	measureDc := gg.NewContext(1, 1)
+	textFace := r.getFace(SizeText)
+	measureDc.SetFontFace(textFace)
+	fontH := measureDc.FontHeight()
+
+	// ── 1. 逐条排版 ──
+	blocks := make([]chatBlock, len(post.Chat))
+	totalH := Padding + HeaderH
+	for i, m := range post.Chat {
+		b := chatBlock{msg: m, showName: i == 0 || post.Chat[i-1].Speaker != m.Speaker || post.Chat[i-1].Self != m.Self}
+		if len(m.Segments) > 0 {
+			b.lines = layoutSegments(textFace, m.Segments, bubbleMaxW-BubblePadH*2, fontH, theme.Justify)
+		}
+		if len(b.lines) > 0 {
+			for _, line := range b.lines {
+				if n := len(line); n > 0 {
+					b.bubbleW = math.Max(b.bubbleW, line[n-1].x+line[n-1].w)
+				}
+			}
+			b.bubbleW += BubblePadH * 2
+			b.bubbleH = float64(len(b.lines))*fontH*LineHeight + BubblePadV*2
+		}
+
+		contentH := b.bubbleH
+		for _, idx := range m.Images {
+			if idx < 0 || idx >= len(post.Images) {
+				continue
+			}
+			img := r.loadImage(post.Images[idx])
+			if img != nil {
+				bd := img.Bounds()
+				scale := math.Min(ImgMax/float64(bd.Dx()), ImgMax/float64(bd.Dy()))
+				if scale > k {
+					scale = k
+				}
+				img = resizeImage(img, int(float64(bd.Dx())*scale), int(float64(bd.Dy())*scale))
+				if post.Warning {
+					img = blurImage(img)
+				}
+			}
+			b.images = append(b.images, img)
+			if contentH > 0 {
+				contentH += ImgGap
+			}
+			if img != nil {
+				contentH += float64(img.Bounds().Dy())
+			} else {
+				contentH += ImgMax / 2
+			}
+		}
+		if b.showName {
+			contentH += SizeName + NameGap
+		}
+		b.height = math.Max(AvatarSize, contentH)
+		blocks[i] = b
+		totalH += b.height + MsgGap
+	}
+	totalH += BottomSpace
+
+	// ── 2. 开始绘制 ──
+	dc := gg.NewContext(int(CanvasWidth), int(totalH))
+	dc.SetHexColor(theme.Background)
+	dc.Clear()
+
+	// 2.1 标题
+	dc.SetFontFace(r.getFace(SizeTitle))
+	dc.SetHexColor(theme.NameColor)
+	dc.DrawStringAnchored(post.ShowName()+" 投稿的聊天记录", CanvasWidth/2, Padding+SizeTitle/2, 0.5, 0.5)
+	dc.SetHexColor(theme.Placeholder)
+	dc.SetLineWidth(2 * k)
+	dc.DrawLine(Padding, Padding+HeaderH-16*k, CanvasWidth-Padding, Padding+HeaderH-16*k)
+	dc.Stroke()
+
+	// 2.2 消息
+	metrics := textFace.Metrics()
+	ascent := float64(metrics.Ascent.Ceil())
+	descent := float64(metrics.Descent.Ceil())
+	faceCache := make(map[image.Image]image.Image)
+	nameFace := r.getFace(SizeName)
+
+	y := Padding + HeaderH
+	for _, b := range blocks {
+		right := b.msg.Self
+		avatarX := Padding
+		if right {
+			avatarX = CanvasWidth - Padding - AvatarSize
+		}
+		r.drawChatAvatar(dc, post, b.msg, avatarX, y, AvatarSize)
+
+		// 内容区：左侧从头像右边开始，右侧以头像左边为右边界
+		edge := Padding + AvatarSize + AvatarGap
+		if right {
+			edge = CanvasWidth - Padding - AvatarSize - AvatarGap
+		}
+		alignX := func(w float64) float64 {
+			if right {
+				return edge - w
+			}
+			return edge
+		}
+
+		cy := y
+		if b.showName {
+			dc.SetFontFace(nameFace)
+			dc.SetHexColor(theme.MetaColor)
+			anchor := 0.0
+			if right {
+				anchor = 1
+			}
+			dc.DrawStringAnchored(b.msg.Speaker, edge, cy+SizeName/2, anchor, 0.5)
+			cy += SizeName + NameGap
+		}
+
+		if b.bubbleH > 0 {
+			bubbleTheme := theme
+			bubbleColor := theme.BubbleColor
+			if right {
+				bubbleColor = theme.SelfBubbleColor
+				bubbleTheme.TextColor = theme.SelfTextColor
+			}
+			bx := alignX(b.bubbleW)
+			dc.SetHexColor(bubbleColor)
+			dc.DrawRoundedRectangle(bx, cy, b.bubbleW, b.bubbleH, 14*k)
+			dc.Fill()
+
+			// 小三角指向头像
+			tipY := cy + math.Min(AvatarSize/2, b.bubbleH/2)
+			if right {
+				dc.MoveTo(edge, tipY-8*k)
+				dc.LineTo(edge+8*k, tipY)
+				dc.LineTo(edge, tipY+8*k)
+			} else {
+				dc.MoveTo(edge, tipY-8*k)
+				dc.LineTo(edge-8*k, tipY)
+				dc.LineTo(edge, tipY+8*k)
+			}
+			dc.ClosePath()
+			dc.Fill()
+
+			dc.SetFontFace(textFace)
+			textY := cy + BubblePadV + ascent
+			for i, line := range b.lines {
+				drawInlineLine(dc, line, bubbleTheme, bx+BubblePadH, textY+float64(i)*fontH*LineHeight, ascent, descent, faceCache)
+			}
+			cy += b.bubbleH + ImgGap
+		}
+
+		for _, img := range b.images {
+			if img == nil {
+				drawErrorPlaceholder(dc, theme, alignX(ImgMax/2), cy, ImgMax/2, ImgMax/2)
+				cy += ImgMax/2 + ImgGap
+				continue
+			}
+			w, h := float64(img.Bounds().Dx()), float64(img.Bounds().Dy())
+			x := alignX(w)
+			dc.Push()
+			dc.DrawRoundedRectangle(x, cy, w, h, 10*k)
+			dc.Clip()
+			dc.DrawImage(img, int(x), int(cy))
+			if post.Warning {
+				r.drawWarningOverlay(dc, post.WarningLabel(), x, cy, w, h, k)
+			}
+			dc.Pop()
+			dc.ResetClip()
+			cy += h + ImgGap
+		}
+
+		y += b.height + MsgGap
+	}
+
+	// 2.3 水印
+	wmFace := r.getFace(SizeMeta)
+	dc.SetFontFace(wmFace)
+	dc.SetHexColor(theme.MetaColor)
+	wmText := fmt.Sprintf("#%d  %s", post.ID, r.now().Format("2006-01-02 15:04"))
+	wmW, _ := dc.MeasureString(wmText)
+	wmY := totalH - 8*k - float64(wmFace.Metrics().Descent.Ceil())
+	dc.DrawString(wmText, CanvasWidth-Padding-wmW, wmY)
+
+	return dc.Image()
+}
+
+// drawChatAvatar 绘制发言人头像。匿名稿件或没有QQ号时用别名首字代替，避免暴露身份；
+// 匿名稿件中投稿者本人使用与假名对应的方块头像
+func (r *Renderer) drawChatAvatar(dc *gg.Context, post *model.Post, m model.ChatMessage, x, y, size float64) {
+	var avatar image.Image
+	switch {
+	case post.Anon && m.Self && post.AnonKey != "":
+		avatar = identicon(post.AnonKey, int(size))
+	case !post.Anon && m.UIN > 0:
+		avatar = r.loadAndCrop(fmt.Sprintf("https://q1.qlogo.cn/g?b=qq&nk=%d&s=640", m.UIN), int(size))
+	}
+
+	dc.Push()
+	dc.DrawCircle(x+size/2, y+size/2, size/2)
+	dc.Clip()
+	if avatar != nil {
+		dc.DrawImage(avatar, int(x), int(y))
+	} else {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(m.Speaker))
+		dc.SetHexColor(aliasColors[h.Sum32()%uint32(len(aliasColors))])
+		dc.DrawRectangle(x, y, size, size)
+		dc.Fill()
+		if runes := []rune(m.Speaker); len(runes) > 0 {
+			dc.SetFontFace(r.getFace(size * 0.45))
+			dc.SetHexColor("#FFFFFF")
+			dc.DrawStringAnchored(string(runes[0]), x+size/2, y+size/2, 0.5, 0.35)
+		}
+	}
+	dc.Pop()
+	dc.ResetClip()
+}
@@ -0,0 +1,213 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+)
+
+// markedImage 左上角为红色、其余为蓝色的 w×h 图片，用于检查方向
+func markedImage(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{0, 0, 255, 255}
+			if x < w/4 && y < h/4 {
+				c = color.RGBA{255, 0, 0, 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// withOrientation 在 JPEG 的 SOI 之后插入只含 Orientation 的 EXIF 段
+func withOrientation(t *testing.T, img image.Image, o uint16) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	var tiffData bytes.Buffer
+	tiffData.WriteString("MM")
+	_ = binary.Write(&tiffData, binary.BigEndian, uint16(42))
+	_ = binary.Write(&tiffData, binary.BigEndian, uint32(8))
+	_ = binary.Write(&tiffData, binary.BigEndian, uint16(1))
+	_ = binary.Write(&tiffData, binary.BigEndian, []uint16{0x0112, 3})
+	_ = binary.Write(&tiffData, binary.BigEndian, uint32(1))
+	_ = binary.Write(&tiffData, binary.BigEndian, []uint16{o, 0})
+	_ = binary.Write(&tiffData, binary.BigEndian, uint32(0))
+
+	seg := append([]byte("Exif\x00\x00"), tiffData.Bytes()...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(seg)+2))
+
+	data := buf.Bytes()
+	out := append([]byte{}, data[:2]...)
+	out = append(out, app1...)
+	out = append(out, seg...)
+	return append(out, data[2:]...)
+}
+
+func isRed(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r > 0xC000 && g < 0x4000 && b < 0x4000
+}
+
+func TestEXIFOrientation(t *testing.T) {
+	src := markedImage(80, 40)
+	cases := []struct {
+		o          uint16
+		w, h       int
+		redX, redY int // 红色标记所在的角 (0 左/上，1 右/下)
+	}{
+		{1, 80, 40, 0, 0},
+		{3, 80, 40, 1, 1},
+		{6, 40, 80, 1, 0},
+		{8, 40, 80, 0, 1},
+	}
+	for _, c := range cases {
+		data := withOrientation(t, src, c.o)
+		if got := jpegOrientation(data); got != int(c.o) {
+			t.Errorf("orientation %d: parsed %d", c.o, got)
+			continue
+		}
+		img, err := decodeImage(data)
+		if err != nil {
+			t.Fatalf("orientation %d: %v", c.o, err)
+		}
+		b := img.Bounds()
+		if b.Dx() != c.w || b.Dy() != c.h {
+			t.Errorf("orientation %d: size %dx%d, want %dx%d", c.o, b.Dx(), b.Dy(), c.w, c.h)
+			continue
+		}
+		x := b.Min.X + 2 + c.redX*(b.Dx()-5)
+		y := b.Min.Y + 2 + c.redY*(b.Dy()-5)
+		if !isRed(img.At(x, y)) {
+			t.Errorf("orientation %d: red marker not at corner (%d,%d)", c.o, c.redX, c.redY)
+		}
+	}
+}
+
+func TestDecodeFormats(t *testing.T) {
+	src := markedImage(32, 24)
+	encoders := map[string]func(*bytes.Buffer) error{
+		"png":  func(b *bytes.Buffer) error { return png.Encode(b, src) },
+		"bmp":  func(b *bytes.Buffer) error { return bmp.Encode(b, src) },
+		"tiff": func(b *bytes.Buffer) error { return tiff.Encode(b, src, nil) },
+		"gif":  func(b *bytes.Buffer) error { return gif.Encode(b, src, nil) },
+	}
+	for name, enc := range encoders {
+		var buf bytes.Buffer
+		if err := enc(&buf); err != nil {
+			t.Fatalf("%s encode: %v", name, err)
+		}
+		img, err := decodeImage(buf.Bytes())
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if img.Bounds().Dx() != 32 || img.Bounds().Dy() != 24 {
+			t.Errorf("%s: size %v", name, img.Bounds())
+		}
+	}
+}
+
+func TestDecodeLimitsSize(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4000, 1000))); err != nil {
+		t.Fatal(err)
+	}
+	img, err := decodeImage(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != maxDecodeSide || b.Dy() != 640 {
+		t.Errorf("size = %dx%d, want %dx640", b.Dx(), b.Dy(), maxDecodeSide)
+	}
+}
+
+// rawImageSource 内存中的原始图片数据
+type rawImageSource map[string][]byte
+
+func (m rawImageSource) Load(url string) (image.Image, error) {
+	data, err := m.LoadRaw(url)
+	if err != nil {
+		return nil, err
+	}
+	return decodeImage(data)
+}
+
+func (m rawImageSource) LoadRaw(url string) ([]byte, error) {
+	if data, ok := m[url]; ok {
+		return data, nil
+	}
+	return nil, fmt.Errorf("image %q not found", url)
+}
+
+func animatedGIF(t *testing.T) []byte {
+	t.Helper()
+	pal := color.Palette{color.Black, color.White}
+	g := &gif.GIF{}
+	for i := 0; i < 3; i++ {
+		frame := image.NewPaletted(image.Rect(0, 0, 20, 20), pal)
+		frame.SetColorIndex(i, i, 1)
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, 10)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestPublishAttachments(t *testing.T) {
+	r := newTestRenderer(t)
+	anim := animatedGIF(t)
+	rotated := withOrientation(t, markedImage(80, 40), 6)
+	var still bytes.Buffer
+	_ = gif.Encode(&still, markedImage(20, 20), nil)
+	r.images = rawImageSource{"mem://anim": anim, "mem://still": still.Bytes(), "mem://rotated": rotated}
+
+	post := &model.Post{ID: 1, Images: []string{"mem://anim", "mem://still", "mem://rotated"}}
+	if out, err := r.RenderForPublish(post); err != nil || len(out) != 1 {
+		t.Fatalf("attach_gif off: got %d images, err %v", len(out), err)
+	}
+
+	r.attachGIF = true
+	out, err := r.RenderForPublish(post)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 2 || !bytes.Equal(out[1], anim) {
+		t.Errorf("attach_gif on: want cover + animated gif unchanged, got %d images", len(out))
+	}
+
+	// 内容警告附带全部原图：动图原样，旋转的 JPEG 摆正后重新编码
+	post.Warning = true
+	out, err = r.RenderForPublish(post)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 4 || !bytes.Equal(out[1], anim) || !bytes.Equal(out[2], still.Bytes()) {
+		t.Fatalf("warning: unexpected originals (%d images)", len(out))
+	}
+	img, err := jpeg.Decode(bytes.NewReader(out[3]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != 40 || img.Bounds().Dy() != 80 {
+		t.Errorf("rotated original size = %v, want 40x80", img.Bounds())
+	}
+}
@@ -0,0 +1,146 @@
+package render
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/fogleman/gg"
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+)
+
+// DigestMaxHeight 合集长图的最大高度 (1 倍图像素)，超出的稿件不再收录
+const DigestMaxHeight = 16000.0
+
+// DigestTitle 合集封面信息
+type DigestTitle struct {
+	WallName string // 墙名
+	Kind     string // 例如 "每日精选" / "每周精选"
+	Issue    int    // 期号，<=0 时不显示
+	Date     string // 日期或日期范围，例如 "02/08 - 02/14"
+}
+
+// RenderDigest 将多条稿件纵向拼成一张长图 (使用当前默认主题)，
+// 同时返回实际收录的稿件 (超出 DigestMaxHeight 的稿件不收录)
+func (r *Renderer) RenderDigest(posts []*model.Post, title DigestTitle) ([]byte, []*model.Post, error) {
+	return r.RenderDigestWithTheme(posts, title, r.currentTheme())
+}
+
+// RenderDigestWithTheme 使用指定主题渲染合集长图
+func (r *Renderer) RenderDigestWithTheme(posts []*model.Post, title DigestTitle, theme Theme) ([]byte, []*model.Post, error) {
+	if !r.Available() {
+		return nil, nil, fmt.Errorf("渲染器未初始化(字体缺失)")
+	}
+	if len(posts) == 0 {
+		return nil, nil, fmt.Errorf("合集没有稿件")
+	}
+	masked := make([]*model.Post, len(posts))
+	for i, p := range posts {
+		masked[i] = p.Masked()
+	}
+	out := theme.Output.normalized()
+	img, n := r.drawDigest(masked, title, theme, out.Scale)
+	data, err := encodeImage(img, out)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, posts[:n], nil
+}
+
+// drawDigest 绘制合集长图，返回图片和收录的稿件数 (posts 的前 n 条)
+func (r *Renderer) drawDigest(posts []*model.Post, title DigestTitle, theme Theme, k float64) (image.Image, int) {
+	var (
+		CanvasWidth = 800.0 * k
+		Padding     = 40.0 * k
+		HeaderH     = 220.0 * k
+		LabelH      = 44.0 * k
+		SepGap      = 24.0 * k
+		FooterH     = 70.0 * k
+		SizeTitle   = 48.0 * k
+		SizeSub     = 28.0 * k
+		SizeLabel   = 26.0 * k
+		SizeMeta    = 22.0 * k
+		MaxH        = DigestMaxHeight * k
+	)
+
+	// ── 1. 逐条绘制，超出高度上限的稿件不再收录 ──
+	var cards []image.Image
+	totalH := HeaderH + FooterH
+	for _, p := range posts {
+		card := r.drawPost(p, theme, k)
+		h := LabelH + float64(card.Bounds().Dy()) + SepGap
+		if len(cards) > 0 && totalH+h > MaxH {
+			break
+		}
+		cards = append(cards, card)
+		totalH += h
+	}
+	omitted := len(posts) - len(cards)
+
+	dc := gg.NewContext(int(CanvasWidth), int(totalH))
+	dc.SetHexColor(theme.Background)
+	dc.Clear()
+
+	// ── 2. 封面 ──
+	dc.SetHexColor(theme.BubbleColor)
+	dc.DrawRectangle(0, 0, CanvasWidth, HeaderH)
+	dc.Fill()
+
+	wallName := title.WallName
+	if wallName == "" {
+		wallName = "表白墙"
+	}
+	dc.SetFontFace(r.getFace(SizeTitle))
+	dc.SetHexColor(theme.TextColor)
+	dc.DrawStringAnchored(wallName, CanvasWidth/2, HeaderH*0.38, 0.5, 0.5)
+
+	sub := title.Kind
+	if title.Issue > 0 {
+		if sub != "" {
+			sub += " · "
+		}
+		sub += fmt.Sprintf("第 %d 期", title.Issue)
+	}
+	if sub != "" {
+		dc.SetFontFace(r.getFace(SizeSub))
+		dc.SetHexColor(theme.NameColor)
+		dc.DrawStringAnchored(sub, CanvasWidth/2, HeaderH*0.62, 0.5, 0.5)
+	}
+	if title.Date != "" {
+		dc.SetFontFace(r.getFace(SizeMeta))
+		dc.SetHexColor(theme.MetaColor)
+		dc.DrawStringAnchored(title.Date, CanvasWidth/2, HeaderH*0.82, 0.5, 0.5)
+	}
+
+	// ── 3. 稿件 ──
+	y := HeaderH
+	labelFace := r.getFace(SizeLabel)
+	for i, card := range cards {
+		dc.SetFontFace(labelFace)
+		dc.SetHexColor(theme.NameColor)
+		dc.DrawStringAnchored(fmt.Sprintf("No.%d", i+1), Padding, y+LabelH/2+SepGap/4, 0, 0.5)
+		y += LabelH
+
+		dc.DrawImage(card, 0, int(y))
+		y += float64(card.Bounds().Dy())
+
+		// 分隔线
+		if i < len(cards)-1 {
+			dc.SetHexColor(theme.MetaColor)
+			dc.SetLineWidth(2 * k)
+			dc.DrawLine(Padding, y+SepGap/2, CanvasWidth-Padding, y+SepGap/2)
+			dc.Stroke()
+		}
+		y += SepGap
+	}
+
+	// ── 4. 页脚 ──
+	footer := fmt.Sprintf("共 %d 条", len(cards))
+	if omitted > 0 {
+		footer += fmt.Sprintf(" · 另有 %d 条未收录", omitted)
+	}
+	dc.SetFontFace(r.getFace(SizeMeta))
+	dc.SetHexColor(theme.MetaColor)
+	dc.DrawStringAnchored(footer, CanvasWidth/2, y+FooterH/2-SepGap/2, 0.5, 0.5)
+
+	return dc.Image(), len(cards)
+}
@@ -0,0 +1,167 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"strings"
+
+	"github.com/gen2brain/webp"
+)
+
+// OutputFormat 截图输出格式
+type OutputFormat string
+
+const (
+	FormatJPEG         OutputFormat = "jpeg"
+	FormatPNG          OutputFormat = "png"
+	FormatWebP         OutputFormat = "webp"          // 有损 WebP
+	FormatWebPLossless OutputFormat = "webp-lossless" // 无损 WebP
+)
+
+const (
+	minQuality  = 30 // 按体积压缩时质量的下限
+	qualityStep = 10
+	maxScale    = 3.0
+)
+
+// OutputOptions 截图编码参数
+type OutputOptions struct {
+	Format   OutputFormat
+	Quality  int     // 有损格式质量 1-100
+	MaxBytes int     // 目标最大字节数，超出时自动降低质量；0 表示不限制
+	Scale    float64 // HiDPI 倍率，按倍率放大布局后再编码
+}
+
+// DefaultOutput 默认输出：JPEG 质量 90，1 倍图
+var DefaultOutput = OutputOptions{Format: FormatJPEG, Quality: 90, Scale: 1}
+
+// ParseOutputFormat 解析格式名，支持常见别名
+func ParseOutputFormat(s string) (OutputFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "jpeg", "jpg":
+		return FormatJPEG, nil
+	case "png":
+		return FormatPNG, nil
+	case "webp":
+		return FormatWebP, nil
+	case "webp-lossless", "webp_lossless":
+		return FormatWebPLossless, nil
+	}
+	return "", fmt.Errorf("unsupported output format: %s", s)
+}
+
+// ContentType 返回格式对应的 MIME 类型
+func (f OutputFormat) ContentType() string {
+	switch f {
+	case FormatPNG:
+		return "image/png"
+	case FormatWebP, FormatWebPLossless:
+		return "image/webp"
+	}
+	return "image/jpeg"
+}
+
+// DetectFormat 由编码结果判断实际格式。设置了 MaxBytes 时无损格式可能退回有损格式，
+// 输出 Content-Type 或文件扩展名时应以实际数据为准，而不是配置的格式
+func DetectFormat(data []byte) OutputFormat {
+	switch http.DetectContentType(data) {
+	case "image/png":
+		return FormatPNG
+	case "image/webp":
+		return FormatWebP
+	}
+	return FormatJPEG
+}
+
+// merge 用 o 中的非零字段覆盖当前参数
+func (opt OutputOptions) merge(o OutputOptions) OutputOptions {
+	if o.Format != "" {
+		opt.Format = o.Format
+	}
+	if o.Quality > 0 {
+		opt.Quality = o.Quality
+	}
+	if o.MaxBytes > 0 {
+		opt.MaxBytes = o.MaxBytes
+	}
+	if o.Scale > 0 {
+		opt.Scale = o.Scale
+	}
+	return opt
+}
+
+func (opt OutputOptions) normalized() OutputOptions {
+	if opt.Format == "" {
+		opt.Format = DefaultOutput.Format
+	}
+	if opt.Quality <= 0 || opt.Quality > 100 {
+		opt.Quality = DefaultOutput.Quality
+	}
+	if opt.Scale <= 0 {
+		opt.Scale = 1
+	}
+	if opt.Scale > maxScale {
+		opt.Scale = maxScale
+	}
+	return opt
+}
+
+// encodeImage 按输出参数编码。
+// 设置了 MaxBytes 时，有损格式逐级降低质量直到满足体积；
+// 无损格式超出体积时退回对应的有损格式 (png→jpeg, webp-lossless→webp) 再压缩。
+func encodeImage(img image.Image, opt OutputOptions) ([]byte, error) {
+	opt = opt.normalized()
+
+	data, err := encodeOnce(img, opt.Format, opt.Quality)
+	if err != nil || opt.MaxBytes <= 0 || len(data) <= opt.MaxBytes {
+		return data, err
+	}
+
+	format := opt.Format
+	switch format {
+	case FormatPNG:
+		format = FormatJPEG
+	case FormatWebPLossless:
+		format = FormatWebP
+	}
+
+	best := data
+	for q := opt.Quality; q >= minQuality; q -= qualityStep {
+		out, err := encodeOnce(img, format, q)
+		if err != nil {
+			return nil, err
+		}
+		if len(out) < len(best) {
+			best = out
+		}
+		if len(out) <= opt.MaxBytes {
+			return out, nil
+		}
+	}
+	// 达到质量下限仍超出，返回体积最小的结果
+	return best, nil
+}
+
+func encodeOnce(img image.Image, format OutputFormat, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case FormatPNG:
+		enc := png.Encoder{CompressionLevel: png.BestCompression}
+		err = enc.Encode(&buf, img)
+	case FormatWebP:
+		err = webp.Encode(&buf, img, webp.Options{Quality: quality, Method: webp.DefaultMethod})
+	case FormatWebPLossless:
+		err = webp.Encode(&buf, img, webp.Options{Lossless: true, Method: webp.DefaultMethod})
+	default:
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encode %s: %w", format, err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,159 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/config"
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+)
+
+func encodeTestPost() *model.Post {
+	return &model.Post{
+		ID:        42,
+		UIN:       10001,
+		Name:      "Encoder",
+		AvatarURL: "mem://avatar",
+		Text:      "Output encoding test",
+		Images:    []string{"mem://wide"},
+	}
+}
+
+func TestRenderOutputFormats(t *testing.T) {
+	r := newTestRenderer(t)
+	cases := []struct {
+		format OutputFormat
+		mime   string
+	}{
+		{FormatJPEG, "image/jpeg"},
+		{FormatPNG, "image/png"},
+		{FormatWebP, "image/webp"},
+		{FormatWebPLossless, "image/webp"},
+	}
+	for _, c := range cases {
+		t.Run(string(c.format), func(t *testing.T) {
+			theme, _ := r.Theme("")
+			theme.Output = OutputOptions{Format: c.format, Quality: 80}
+			data, err := r.RenderPostWithTheme(encodeTestPost(), theme)
+			if err != nil {
+				t.Fatalf("render: %v", err)
+			}
+			if got := http.DetectContentType(data); got != c.mime {
+				t.Errorf("content type = %s, want %s", got, c.mime)
+			}
+			if got := c.format.ContentType(); got != c.mime {
+				t.Errorf("ContentType() = %s, want %s", got, c.mime)
+			}
+		})
+	}
+}
+
+func TestRenderScale(t *testing.T) {
+	r := newTestRenderer(t)
+	theme, _ := r.Theme("")
+	theme.Output = OutputOptions{Format: FormatPNG, Scale: 1}
+	one, err := r.RenderPostWithTheme(encodeTestPost(), theme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	theme.Output.Scale = 2
+	two, err := r.RenderPostWithTheme(encodeTestPost(), theme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c1, _, _ := image.DecodeConfig(bytes.NewReader(one))
+	c2, _, _ := image.DecodeConfig(bytes.NewReader(two))
+	if c2.Width != 2*c1.Width {
+		t.Errorf("2x width = %d, want %d", c2.Width, 2*c1.Width)
+	}
+	if diff := c2.Height - 2*c1.Height; diff < -2 || diff > 2 {
+		t.Errorf("2x height = %d, want about %d", c2.Height, 2*c1.Height)
+	}
+}
+
+func TestEncodeMaxBytes(t *testing.T) {
+	img := testImages()["mem://wide"]
+	full, err := encodeImage(img, OutputOptions{Format: FormatJPEG, Quality: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	limit := len(full) / 2
+	out, err := encodeImage(img, OutputOptions{Format: FormatJPEG, Quality: 100, MaxBytes: limit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) > limit {
+		t.Errorf("size = %d, want <= %d", len(out), limit)
+	}
+
+	// 无损格式超限时退回有损格式
+	pngFull, err := encodeImage(img, OutputOptions{Format: FormatPNG})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err = encodeImage(img, OutputOptions{Format: FormatPNG, MaxBytes: len(pngFull) / 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := DetectFormat(out); got != FormatJPEG || got.ContentType() != "image/jpeg" {
+		t.Errorf("png over budget: detected %s, want jpeg", got)
+	}
+	if got := DetectFormat(pngFull); got != FormatPNG {
+		t.Errorf("png within budget: detected %s, want png", got)
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	r := newTestRenderer(t)
+	err := r.ApplyConfig(config.RenderConfig{
+		Theme:  "dark",
+		Output: config.OutputConfig{Format: "png", MaxBytes: 500000},
+		Themes: map[string]config.ThemeConfig{
+			"default": {Output: config.OutputConfig{Format: "webp", Quality: 70}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cur, ok := r.Theme("")
+	if !ok || cur.Name != "dark" {
+		t.Fatalf("current theme = %q, want dark", cur.Name)
+	}
+	if cur.Output.Format != FormatPNG || cur.Output.MaxBytes != 500000 || cur.Output.Quality != 90 {
+		t.Errorf("dark output = %+v", cur.Output)
+	}
+	def, _ := r.Theme("default")
+	if def.Output.Format != FormatWebP || def.Output.Quality != 70 || def.Output.MaxBytes != 500000 {
+		t.Errorf("default output = %+v", def.Output)
+	}
+}
+
+// TestApplyConfigInvalid 拼错的格式名或主题名报错，而不是静默使用默认值
+func TestApplyConfigInvalid(t *testing.T) {
+	r := newTestRenderer(t)
+	if err := r.ApplyConfig(config.RenderConfig{Output: config.OutputConfig{Format: "webpp"}}); err == nil || !strings.Contains(err.Error(), "wall.render.output") {
+		t.Errorf("unknown global format: err = %v", err)
+	}
+	err := r.ApplyConfig(config.RenderConfig{
+		Theme:  "dark",
+		Themes: map[string]config.ThemeConfig{"dark": {Output: config.OutputConfig{Format: "gif"}}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "wall.render.themes.dark.output") {
+		t.Errorf("unknown theme format: err = %v", err)
+	}
+	if err := r.ApplyConfig(config.RenderConfig{Theme: "darkk"}); err == nil || !strings.Contains(err.Error(), "wall.render.theme") {
+		t.Errorf("unknown theme: err = %v", err)
+	}
+	err = r.ApplyConfig(config.RenderConfig{Themes: map[string]config.ThemeConfig{"light": {Justify: true}}})
+	if err == nil || !strings.Contains(err.Error(), "wall.render.themes.light") {
+		t.Errorf("unknown theme override: err = %v", err)
+	}
+	// 出错时保持原配置
+	if cur, _ := r.Theme(""); cur.Name != DefaultThemeName {
+		t.Errorf("current theme = %q after failed apply", cur.Name)
+	}
+}
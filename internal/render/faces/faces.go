@@ -0,0 +1,87 @@
+// Package faces 提供 QQ 系统表情的名称表与内置精灵图。
+package faces
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"image"
+	"image/draw"
+	"image/png"
+	"log"
+	"strconv"
+	"sync"
+)
+
+//go:generate go run ./gen
+
+// CellSize 精灵图中单个表情的边长 (px)
+const CellSize = 64
+
+const cols = 16
+
+//go:embed sprite.png
+var spriteData []byte
+
+//go:embed index.json
+var indexData []byte
+
+type entry struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Cell int    `json:"cell"`
+}
+
+var (
+	loadOnce sync.Once
+	sprite   image.Image
+	entries  map[int]entry
+)
+
+func load() {
+	entries = make(map[int]entry)
+	var list []entry
+	if err := json.Unmarshal(indexData, &list); err != nil {
+		log.Printf("[Faces] 解析表情索引失败: %v", err)
+		return
+	}
+	for _, e := range list {
+		entries[e.ID] = e
+	}
+	img, err := png.Decode(bytes.NewReader(spriteData))
+	if err != nil {
+		log.Printf("[Faces] 解析表情精灵图失败: %v", err)
+		return
+	}
+	sprite = img
+}
+
+// Name 返回表情名称，例如 "微笑"；未知表情返回 "表情"
+func Name(id string) string {
+	loadOnce.Do(load)
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return "表情"
+	}
+	if e, ok := entries[n]; ok {
+		return e.Name
+	}
+	return "表情"
+}
+
+// Image 返回表情图 (CellSize×CellSize)，没有对应精灵时返回 nil
+func Image(id string) image.Image {
+	loadOnce.Do(load)
+	n, err := strconv.Atoi(id)
+	if err != nil || sprite == nil {
+		return nil
+	}
+	e, ok := entries[n]
+	if !ok || e.Cell < 0 {
+		return nil
+	}
+	x, y := e.Cell%cols*CellSize, e.Cell/cols*CellSize
+	dst := image.NewRGBA(image.Rect(0, 0, CellSize, CellSize))
+	draw.Draw(dst, dst.Bounds(), sprite, image.Pt(x, y), draw.Src)
+	return dst
+}
@@ -0,0 +1,402 @@
+// Package main generates the QQ system face sprite sheet (sprite.png) and index.json.
+//
+// 生成的是简笔风格的占位表情；如需替换为正式素材，保持 index.json 中的
+// 格子顺序与 Cell 尺寸不变，直接覆盖 sprite.png 即可。
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"os"
+
+	"github.com/fogleman/gg"
+)
+
+const (
+	cell = 64 // 单个表情边长 (px)
+	cols = 16
+)
+
+// 表情的绘制样式
+const (
+	none = iota // 无精灵图，仅有名称
+	smile
+	grin
+	laugh
+	sad
+	cry
+	angry
+	surprise
+	neutral
+	wink
+	tongue
+	sleep
+	cool
+	love
+	sweat
+	dizzy
+	shy
+	heart
+	brokenHeart
+	sun
+	moon
+)
+
+type face struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Cell  int    `json:"cell"` // sprite.png 中的格子序号，-1 表示无图
+	style int
+}
+
+var table = []face{
+	{ID: 0, Name: "惊讶", style: surprise},
+	{ID: 1, Name: "撇嘴", style: sad},
+	{ID: 2, Name: "色", style: love},
+	{ID: 3, Name: "发呆", style: neutral},
+	{ID: 4, Name: "得意", style: cool},
+	{ID: 5, Name: "流泪", style: cry},
+	{ID: 6, Name: "害羞", style: shy},
+	{ID: 7, Name: "闭嘴", style: neutral},
+	{ID: 8, Name: "睡", style: sleep},
+	{ID: 9, Name: "大哭", style: cry},
+	{ID: 10, Name: "尴尬", style: sweat},
+	{ID: 11, Name: "发怒", style: angry},
+	{ID: 12, Name: "调皮", style: tongue},
+	{ID: 13, Name: "呲牙", style: grin},
+	{ID: 14, Name: "微笑", style: smile},
+	{ID: 15, Name: "难过", style: sad},
+	{ID: 16, Name: "酷", style: cool},
+	{ID: 18, Name: "抓狂", style: angry},
+	{ID: 19, Name: "吐"},
+	{ID: 20, Name: "偷笑", style: shy},
+	{ID: 21, Name: "可爱", style: shy},
+	{ID: 22, Name: "白眼", style: neutral},
+	{ID: 23, Name: "傲慢", style: cool},
+	{ID: 24, Name: "饥饿"},
+	{ID: 25, Name: "困", style: sleep},
+	{ID: 26, Name: "惊恐", style: surprise},
+	{ID: 27, Name: "流汗", style: sweat},
+	{ID: 28, Name: "憨笑", style: laugh},
+	{ID: 29, Name: "悠闲", style: cool},
+	{ID: 30, Name: "奋斗"},
+	{ID: 31, Name: "咒骂", style: angry},
+	{ID: 32, Name: "疑问", style: neutral},
+	{ID: 33, Name: "嘘"},
+	{ID: 34, Name: "晕", style: dizzy},
+	{ID: 35, Name: "折磨", style: dizzy},
+	{ID: 36, Name: "衰", style: sad},
+	{ID: 37, Name: "骷髅"},
+	{ID: 38, Name: "敲打"},
+	{ID: 39, Name: "再见", style: smile},
+	{ID: 41, Name: "发抖", style: surprise},
+	{ID: 42, Name: "爱情", style: heart},
+	{ID: 43, Name: "跳跳"},
+	{ID: 46, Name: "猪头"},
+	{ID: 49, Name: "拥抱"},
+	{ID: 53, Name: "蛋糕"},
+	{ID: 54, Name: "闪电"},
+	{ID: 55, Name: "炸弹"},
+	{ID: 56, Name: "刀"},
+	{ID: 57, Name: "足球"},
+	{ID: 59, Name: "便便"},
+	{ID: 60, Name: "咖啡"},
+	{ID: 61, Name: "饭"},
+	{ID: 63, Name: "玫瑰"},
+	{ID: 64, Name: "凋谢"},
+	{ID: 66, Name: "爱心", style: heart},
+	{ID: 67, Name: "心碎", style: brokenHeart},
+	{ID: 69, Name: "礼物"},
+	{ID: 74, Name: "太阳", style: sun},
+	{ID: 75, Name: "月亮", style: moon},
+	{ID: 76, Name: "赞"},
+	{ID: 77, Name: "踩"},
+	{ID: 78, Name: "握手"},
+	{ID: 79, Name: "胜利"},
+	{ID: 85, Name: "飞吻", style: wink},
+	{ID: 86, Name: "怄火", style: angry},
+	{ID: 89, Name: "西瓜"},
+	{ID: 96, Name: "冷汗", style: sweat},
+	{ID: 97, Name: "擦汗", style: sweat},
+	{ID: 98, Name: "抠鼻", style: neutral},
+	{ID: 99, Name: "鼓掌", style: grin},
+	{ID: 100, Name: "糗大了", style: sweat},
+	{ID: 101, Name: "坏笑", style: wink},
+	{ID: 102, Name: "左哼哼", style: angry},
+	{ID: 103, Name: "右哼哼", style: angry},
+	{ID: 104, Name: "哈欠", style: sleep},
+	{ID: 105, Name: "鄙视", style: neutral},
+	{ID: 106, Name: "委屈", style: sad},
+	{ID: 107, Name: "快哭了", style: cry},
+	{ID: 108, Name: "阴险", style: wink},
+	{ID: 109, Name: "左亲亲", style: wink},
+	{ID: 110, Name: "吓", style: surprise},
+	{ID: 111, Name: "可怜", style: sad},
+	{ID: 112, Name: "菜刀"},
+	{ID: 113, Name: "啤酒"},
+	{ID: 114, Name: "篮球"},
+	{ID: 115, Name: "乒乓"},
+	{ID: 116, Name: "示爱", style: heart},
+	{ID: 117, Name: "瓢虫"},
+	{ID: 118, Name: "抱拳"},
+	{ID: 119, Name: "勾引"},
+	{ID: 120, Name: "拳头"},
+	{ID: 121, Name: "差劲"},
+	{ID: 122, Name: "爱你"},
+	{ID: 123, Name: "NO"},
+	{ID: 124, Name: "OK"},
+	{ID: 172, Name: "眨眼睛", style: wink},
+	{ID: 173, Name: "泪奔", style: cry},
+	{ID: 174, Name: "无奈", style: neutral},
+	{ID: 175, Name: "卖萌", style: tongue},
+	{ID: 176, Name: "小纠结", style: sweat},
+	{ID: 177, Name: "喷血"},
+	{ID: 178, Name: "斜眼笑", style: wink},
+	{ID: 179, Name: "doge"},
+	{ID: 180, Name: "惊喜", style: surprise},
+	{ID: 181, Name: "骚扰"},
+	{ID: 182, Name: "笑哭", style: laugh},
+	{ID: 183, Name: "我最美", style: shy},
+	{ID: 212, Name: "托腮", style: neutral},
+	{ID: 264, Name: "捂脸", style: shy},
+	{ID: 265, Name: "辣眼睛", style: dizzy},
+	{ID: 266, Name: "哦哟", style: surprise},
+	{ID: 267, Name: "头秃", style: sweat},
+	{ID: 268, Name: "问号脸", style: neutral},
+	{ID: 269, Name: "暗中观察", style: neutral},
+	{ID: 270, Name: "emm", style: neutral},
+	{ID: 271, Name: "吃瓜", style: smile},
+	{ID: 272, Name: "呵呵哒", style: smile},
+	{ID: 273, Name: "我酸了", style: sad},
+	{ID: 277, Name: "汪汪"},
+	{ID: 281, Name: "无眼笑", style: laugh},
+	{ID: 282, Name: "敬礼", style: smile},
+	{ID: 283, Name: "狂笑", style: laugh},
+	{ID: 284, Name: "面无表情", style: neutral},
+	{ID: 285, Name: "摸鱼", style: smile},
+	{ID: 287, Name: "哦", style: surprise},
+	{ID: 289, Name: "睁眼", style: surprise},
+	{ID: 290, Name: "敲开心", style: grin},
+	{ID: 294, Name: "期待", style: love},
+	{ID: 297, Name: "拜谢", style: smile},
+	{ID: 299, Name: "牛啊", style: cool},
+	{ID: 305, Name: "右亲亲", style: wink},
+	{ID: 307, Name: "喵喵"},
+	{ID: 311, Name: "打call", style: grin},
+	{ID: 318, Name: "崇拜", style: love},
+	{ID: 319, Name: "比心", style: heart},
+	{ID: 320, Name: "庆祝", style: grin},
+	{ID: 322, Name: "拒绝", style: angry},
+	{ID: 323, Name: "嫌弃", style: neutral},
+	{ID: 326, Name: "生气", style: angry},
+}
+
+func main() {
+	n := 0
+	for _, f := range table {
+		if f.style != none {
+			n++
+		}
+	}
+	rows := (n + cols - 1) / cols
+	dc := gg.NewContext(cols*cell, rows*cell)
+
+	idx := 0
+	out := make([]face, 0, len(table))
+	for _, f := range table {
+		f.Cell = -1
+		if f.style != none {
+			f.Cell = idx
+			x := float64(idx%cols*cell) + cell/2
+			y := float64(idx/cols*cell) + cell/2
+			drawFace(dc, x, y, f.style)
+			idx++
+		}
+		out = append(out, f)
+	}
+
+	if err := dc.SavePNG("sprite.png"); err != nil {
+		log.Fatal(err)
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("index.json", append(data, '\n'), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func drawFace(dc *gg.Context, x, y float64, style int) {
+	const r = cell/2 - 4
+	switch style {
+	case heart:
+		drawHeart(dc, x, y, "#E8374A")
+		return
+	case brokenHeart:
+		drawHeart(dc, x, y, "#A0A0A0")
+		dc.SetHexColor("#FFFFFF")
+		dc.SetLineWidth(3)
+		dc.MoveTo(x, y-14)
+		dc.LineTo(x-5, y-2)
+		dc.LineTo(x+4, y+6)
+		dc.LineTo(x, y+18)
+		dc.Stroke()
+		return
+	case sun:
+		dc.SetHexColor("#FFB300")
+		for i := 0; i < 8; i++ {
+			a := float64(i) * math.Pi / 4
+			dc.DrawLine(x+math.Cos(a)*16, y+math.Sin(a)*16, x+math.Cos(a)*27, y+math.Sin(a)*27)
+		}
+		dc.SetLineWidth(4)
+		dc.Stroke()
+		dc.DrawCircle(x, y, 13)
+		dc.Fill()
+		return
+	case moon:
+		// 用反向蒙版挖掉右上角，得到月牙
+		dc.DrawCircle(x+12, y-8, r-6)
+		dc.Clip()
+		dc.InvertMask()
+		dc.SetHexColor("#F4D03F")
+		dc.DrawCircle(x, y, r-2)
+		dc.Fill()
+		dc.ResetClip()
+		return
+	}
+
+	// 脸
+	dc.SetHexColor("#FFD23F")
+	dc.DrawCircle(x, y, r)
+	dc.Fill()
+	dc.SetHexColor("#C99A00")
+	dc.SetLineWidth(2)
+	dc.DrawCircle(x, y, r)
+	dc.Stroke()
+
+	ink := "#5A3A00"
+	dc.SetHexColor(ink)
+	dc.SetLineWidth(3)
+	ex, ey := 10.0, -6.0
+
+	// 眼睛
+	switch style {
+	case laugh, grin:
+		dc.DrawArc(x-ex, y+ey+2, 5, math.Pi, 2*math.Pi)
+		dc.Stroke()
+		dc.DrawArc(x+ex, y+ey+2, 5, math.Pi, 2*math.Pi)
+		dc.Stroke()
+	case sleep:
+		dc.DrawLine(x-ex-5, y+ey, x-ex+5, y+ey)
+		dc.DrawLine(x+ex-5, y+ey, x+ex+5, y+ey)
+		dc.Stroke()
+	case wink:
+		dc.DrawCircle(x-ex, y+ey, 3.5)
+		dc.Fill()
+		dc.DrawArc(x+ex, y+ey+2, 5, math.Pi, 2*math.Pi)
+		dc.Stroke()
+	case cool:
+		dc.DrawRoundedRectangle(x-ex-8, y+ey-5, 16, 10, 3)
+		dc.DrawRoundedRectangle(x+ex-8, y+ey-5, 16, 10, 3)
+		dc.Fill()
+		dc.DrawLine(x-ex+8, y+ey-2, x+ex-8, y+ey-2)
+		dc.Stroke()
+	case love:
+		drawHeartSized(dc, x-ex, y+ey, 0.3, "#E8374A")
+		drawHeartSized(dc, x+ex, y+ey, 0.3, "#E8374A")
+	case dizzy:
+		for _, cx := range []float64{x - ex, x + ex} {
+			dc.DrawLine(cx-4, y+ey-4, cx+4, y+ey+4)
+			dc.DrawLine(cx-4, y+ey+4, cx+4, y+ey-4)
+		}
+		dc.Stroke()
+	case angry:
+		dc.DrawLine(x-ex-6, y+ey-8, x-ex+4, y+ey-4)
+		dc.DrawLine(x+ex+6, y+ey-8, x+ex-4, y+ey-4)
+		dc.Stroke()
+		dc.DrawCircle(x-ex, y+ey+1, 3)
+		dc.DrawCircle(x+ex, y+ey+1, 3)
+		dc.Fill()
+	case surprise:
+		dc.DrawCircle(x-ex, y+ey, 4.5)
+		dc.DrawCircle(x+ex, y+ey, 4.5)
+		dc.Fill()
+	default:
+		dc.DrawCircle(x-ex, y+ey, 3.5)
+		dc.DrawCircle(x+ex, y+ey, 3.5)
+		dc.Fill()
+	}
+
+	// 嘴
+	my := y + 10
+	switch style {
+	case smile, wink, cool, love, shy:
+		dc.DrawArc(x, my-6, 10, 0.2*math.Pi, 0.8*math.Pi)
+		dc.Stroke()
+	case grin, laugh:
+		dc.MoveTo(x-12, my-4)
+		dc.LineTo(x+12, my-4)
+		dc.DrawArc(x, my-4, 12, 0, math.Pi)
+		dc.ClosePath()
+		dc.Fill()
+	case sad, cry, angry:
+		dc.DrawArc(x, my+8, 9, 1.2*math.Pi, 1.8*math.Pi)
+		dc.Stroke()
+	case surprise:
+		dc.DrawEllipse(x, my, 5, 7)
+		dc.Fill()
+	case tongue:
+		dc.DrawLine(x-9, my-2, x+9, my-2)
+		dc.Stroke()
+		dc.SetHexColor("#E8374A")
+		dc.DrawEllipse(x+3, my+3, 5, 6)
+		dc.Fill()
+	case sleep:
+		dc.DrawEllipse(x, my, 4, 3)
+		dc.Stroke()
+	default:
+		dc.DrawLine(x-9, my, x+9, my)
+		dc.Stroke()
+	}
+
+	// 装饰
+	switch style {
+	case cry:
+		dc.SetHexColor("#4FA3F7")
+		dc.DrawEllipse(x-ex-2, y+6, 3, 8)
+		dc.DrawEllipse(x+ex+2, y+6, 3, 8)
+		dc.Fill()
+	case sweat:
+		dc.SetHexColor("#4FA3F7")
+		dc.DrawEllipse(x+r-8, y-r+12, 4, 7)
+		dc.Fill()
+	case shy:
+		dc.SetRGBA255(255, 110, 110, 140)
+		dc.DrawEllipse(x-ex-6, y+4, 6, 3.5)
+		dc.DrawEllipse(x+ex+6, y+4, 6, 3.5)
+		dc.Fill()
+	case sleep:
+		dc.SetHexColor("#5A6FD6")
+		dc.SetLineWidth(2)
+		dc.MoveTo(x+14, y-24)
+		dc.LineTo(x+24, y-24)
+		dc.LineTo(x+14, y-14)
+		dc.LineTo(x+24, y-14)
+		dc.Stroke()
+	}
+}
+
+func drawHeart(dc *gg.Context, x, y float64, hex string) {
+	drawHeartSized(dc, x, y, 1, hex)
+}
+
+func drawHeartSized(dc *gg.Context, x, y, s float64, hex string) {
+	dc.SetHexColor(hex)
+	dc.MoveTo(x, y+22*s)
+	dc.CubicTo(x-30*s, y+2*s, x-18*s, y-24*s, x, y-10*s)
+	dc.CubicTo(x+18*s, y-24*s, x+30*s, y+2*s, x, y+22*s)
+	dc.ClosePath()
+	dc.Fill()
+}
@@ -0,0 +1,353 @@
+// Package main generates testdata/cjk.ttf, the font used by the golden tests.
+//
+// 在 Go Regular 后追加占位字形，覆盖渲染包与 model 包字符串字面量中出现的中日韩字符与全角符号，
+// 使基准图里的中文不再是缺字方框。汉字与假名由码位哈希决定的横竖笔画组成，不同的字互不相同；
+// 句读符号画在左下角，其余符号为居中的小方块，保证避头尾等排版效果在基准图中可见。
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"go/scanner"
+	"go/token"
+	"hash/fnv"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+const (
+	em     = 2048 // Go Regular 的 unitsPerEm
+	stroke = 130
+	out    = "testdata/cjk.ttf"
+)
+
+// keepTables 原样保留的表；其余表 (字形名、GSUB 等) 与新字形数不匹配，直接丢弃
+var keepTables = []string{"OS/2", "cvt ", "fpgm", "gasp", "name", "prep"}
+
+// rect 占位字形的一笔，字体单位
+type rect struct{ x0, y0, x1, y1 int }
+
+func main() {
+	base, err := truetype.Parse(goregular.TTF)
+	if err != nil {
+		log.Fatalf("parse goregular: %v", err)
+	}
+	runes, err := collectRunes(base)
+	if err != nil {
+		log.Fatal(err)
+	}
+	data := build(base, runes)
+	if _, err := truetype.Parse(data); err != nil {
+		log.Fatalf("generated font is invalid: %v", err)
+	}
+	if err := os.WriteFile(out, data, 0644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %s: %d glyphs added, %d bytes", out, len(runes), len(data))
+}
+
+// collectRunes 渲染包及 model 包 .go 文件的字符串字面量中，Go Regular 缺少的 BMP 中日韩字符与全角符号
+func collectRunes(base *truetype.Font) ([]rune, error) {
+	var files []string
+	for _, pattern := range []string{"*.go", "../model/*.go"} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	seen := map[rune]bool{}
+	var runes []rune
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		var s scanner.Scanner
+		s.Init(token.NewFileSet().AddFile(name, -1, len(data)), data, nil, 0)
+		for {
+			_, tok, lit := s.Scan()
+			if tok == token.EOF {
+				break
+			}
+			if tok != token.STRING && tok != token.CHAR {
+				continue
+			}
+			for _, r := range lit {
+				if r < 0x2E80 || r > 0xFFFF || seen[r] || !unicode.IsGraphic(r) || base.Index(r) != 0 {
+					continue
+				}
+				seen[r] = true
+				runes = append(runes, r)
+			}
+		}
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	return runes, nil
+}
+
+// placeholder 占位字形的笔画
+func placeholder(r rune) []rect {
+	switch {
+	case r == 0x3000: // 全角空格
+		return nil
+	case strings.ContainsRune("、。，．", r):
+		return []rect{{240, -120, 480, 120}}
+	case !unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+		return []rect{{em/2 - 200, 500, em/2 + 200, 900}}
+	}
+
+	h := fnv.New64a()
+	_ = binary.Write(h, binary.BigEndian, int32(r))
+	bits := h.Sum64()
+	next := func(n uint) int {
+		v := int(bits & (1<<n - 1))
+		bits >>= n
+		return v
+	}
+	// 字面框内 4 条横向、4 条纵向通道，每条通道是否落笔及笔画两端的缩进由哈希决定；
+	// 第一条横笔和最后一条竖笔总会落笔，避免出现空白字形
+	const x0, x1, y0, y1, lanes = 160, em - 160, -140, 1640, 4
+	w, ht := x1-x0, y1-y0
+	var rs []rect
+	for i := 0; i < 2*lanes; i++ {
+		if next(1) == 0 && i != 0 && i != 2*lanes-1 {
+			continue
+		}
+		a, b := next(2), next(2)
+		if i < lanes {
+			y := y0 + ht*(2*i+1)/(2*lanes) - stroke/2
+			rs = append(rs, rect{x0 + a*w/8, y, x1 - b*w/8, y + stroke})
+		} else {
+			x := x0 + w*(2*(i-lanes)+1)/(2*lanes) - stroke/2
+			rs = append(rs, rect{x, y0 + a*ht/8, x + stroke, y1 - b*ht/8})
+		}
+	}
+	return rs
+}
+
+// ──────────────────────────────────────────
+// 字体文件写出
+// ──────────────────────────────────────────
+
+// build 在 Go Regular 的字形之后追加 runes 的占位字形，重写 cmap、loca、hmtx 及相关计数
+func build(base *truetype.Font, runes []rune) []byte {
+	tables := readTables(goregular.TTF)
+	head, hhea, maxp := clone(tables["head"]), clone(tables["hhea"]), clone(tables["maxp"])
+	numOld, numHM := int(u16(maxp[4:])), int(u16(hhea[34:]))
+
+	// 原有字形原样保留，loca 改为长格式，度量展开为完整的 longHorMetric
+	glyf := bytes.NewBuffer(clone(tables["glyf"]))
+	var loca, hmtx bytes.Buffer
+	for i := 0; i < numOld; i++ {
+		if u16(head[50:]) == 0 {
+			put32(&loca, 2*int(u16(tables["loca"][2*i:])))
+		} else {
+			put32(&loca, int(binary.BigEndian.Uint32(tables["loca"][4*i:])))
+		}
+		if i < numHM {
+			hmtx.Write(tables["hmtx"][4*i : 4*i+4])
+		} else {
+			hmtx.Write(tables["hmtx"][4*(numHM-1) : 4*numHM-2])
+			hmtx.Write(tables["hmtx"][4*numHM+2*(i-numHM):][:2])
+		}
+	}
+
+	maxPts, maxCont := int(u16(maxp[6:])), int(u16(maxp[8:]))
+	for _, r := range runes {
+		pad4(glyf)
+		put32(&loca, glyf.Len())
+		rs := placeholder(r)
+		put16(&hmtx, em)
+		put16(&hmtx, writeGlyph(glyf, rs))
+		maxPts, maxCont = max(maxPts, 4*len(rs)), max(maxCont, len(rs))
+	}
+	pad4(glyf)
+	put32(&loca, glyf.Len())
+
+	numGlyphs := numOld + len(runes)
+	binary.BigEndian.PutUint16(head[50:], 1) // long loca
+	binary.BigEndian.PutUint32(head[8:], 0)  // checkSumAdjustment，最后回填
+	binary.BigEndian.PutUint16(hhea[10:], uint16(max(int(u16(hhea[10:])), em)))
+	binary.BigEndian.PutUint16(hhea[34:], uint16(numGlyphs))
+	binary.BigEndian.PutUint16(maxp[4:], uint16(numGlyphs))
+	binary.BigEndian.PutUint16(maxp[6:], uint16(maxPts))
+	binary.BigEndian.PutUint16(maxp[8:], uint16(maxCont))
+
+	newTables := map[string][]byte{
+		"cmap": buildCmap(base, runes, numOld),
+		"glyf": glyf.Bytes(),
+		"head": head,
+		"hhea": hhea,
+		"hmtx": hmtx.Bytes(),
+		"loca": loca.Bytes(),
+		"maxp": maxp,
+	}
+	for _, tag := range keepTables {
+		newTables[tag] = tables[tag]
+	}
+	return writeFont(newTables)
+}
+
+// writeGlyph 写出由矩形组成的简单字形 (顺时针，无指令)，返回 xMin；没有笔画时写出空字形
+func writeGlyph(w *bytes.Buffer, rs []rect) int {
+	if len(rs) == 0 {
+		return 0
+	}
+	bx := rs[0]
+	for _, r := range rs[1:] {
+		bx = rect{min(bx.x0, r.x0), min(bx.y0, r.y0), max(bx.x1, r.x1), max(bx.y1, r.y1)}
+	}
+	put16(w, len(rs))
+	put16(w, bx.x0)
+	put16(w, bx.y0)
+	put16(w, bx.x1)
+	put16(w, bx.y1)
+	for i := range rs {
+		put16(w, 4*i+3)
+	}
+	put16(w, 0)
+	w.Write(bytes.Repeat([]byte{0x01}, 4*len(rs))) // 全部为在线点
+	// 坐标按 16 位增量写出，先全部 x 再全部 y
+	prev := 0
+	for _, r := range rs {
+		for _, x := range []int{r.x0, r.x0, r.x1, r.x1} {
+			put16(w, x-prev)
+			prev = x
+		}
+	}
+	prev = 0
+	for _, r := range rs {
+		for _, y := range []int{r.y0, r.y1, r.y1, r.y0} {
+			put16(w, y-prev)
+			prev = y
+		}
+	}
+	return bx.x0
+}
+
+// buildCmap 生成 (3,10) format 12 子表：Go Regular 原有的 BMP 映射加上新增字符
+func buildCmap(base *truetype.Font, runes []rune, first int) []byte {
+	gid := map[int]int{}
+	for r := 0; r <= 0xFFFF; r++ {
+		if idx := base.Index(rune(r)); idx != 0 {
+			gid[r] = int(idx)
+		}
+	}
+	for i, r := range runes {
+		gid[int(r)] = first + i
+	}
+	codes := make([]int, 0, len(gid))
+	for c := range gid {
+		codes = append(codes, c)
+	}
+	sort.Ints(codes)
+
+	// 码位与字形序号同时连续的字符合并为一组
+	type group struct{ start, end, glyph int }
+	var groups []group
+	for _, c := range codes {
+		if n := len(groups); n > 0 && groups[n-1].end == c-1 && gid[c-1] == gid[c]-1 {
+			groups[n-1].end = c
+			continue
+		}
+		groups = append(groups, group{c, c, gid[c]})
+	}
+
+	var cmap bytes.Buffer
+	put16(&cmap, 0) // version
+	put16(&cmap, 1)
+	put16(&cmap, 3)  // Windows
+	put16(&cmap, 10) // Unicode full
+	put32(&cmap, 12) // 子表偏移
+	put16(&cmap, 12)
+	put16(&cmap, 0)
+	put32(&cmap, 16+12*len(groups)) // length
+	put32(&cmap, 0)                 // language
+	put32(&cmap, len(groups))
+	for _, g := range groups {
+		put32(&cmap, g.start)
+		put32(&cmap, g.end)
+		put32(&cmap, g.glyph)
+	}
+	return cmap.Bytes()
+}
+
+func readTables(ttf []byte) map[string][]byte {
+	tables := map[string][]byte{}
+	for i := 0; i < int(u16(ttf[4:])); i++ {
+		e := ttf[12+16*i:]
+		off, length := binary.BigEndian.Uint32(e[8:]), binary.BigEndian.Uint32(e[12:])
+		tables[string(e[:4])] = ttf[off : off+length]
+	}
+	return tables
+}
+
+func writeFont(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	n := len(tags)
+	searchRange, entrySelector := 1, 0
+	for searchRange*2 <= n {
+		searchRange *= 2
+		entrySelector++
+	}
+	var dir, body bytes.Buffer
+	put32(&dir, 0x00010000)
+	put16(&dir, n)
+	put16(&dir, searchRange*16)
+	put16(&dir, entrySelector)
+	put16(&dir, n*16-searchRange*16)
+	offset := 12 + 16*n
+	var headAt int
+	for _, tag := range tags {
+		data := tables[tag]
+		if tag == "head" {
+			headAt = offset + body.Len()
+		}
+		dir.WriteString(tag)
+		put32(&dir, int(checksum(data)))
+		put32(&dir, offset+body.Len())
+		put32(&dir, len(data))
+		body.Write(data)
+		pad4(&body)
+	}
+	font := append(dir.Bytes(), body.Bytes()...)
+	binary.BigEndian.PutUint32(font[headAt+8:], 0xB1B0AFBA-checksum(font))
+	return font
+}
+
+func checksum(b []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(b); i += 4 {
+		var word [4]byte
+		copy(word[:], b[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
+
+func clone(b []byte) []byte { return append([]byte(nil), b...) }
+
+func u16(b []byte) uint16 { return binary.BigEndian.Uint16(b) }
+
+func put16(w *bytes.Buffer, v int) { _ = binary.Write(w, binary.BigEndian, uint16(v)) }
+
+func put32(w *bytes.Buffer, v int) { _ = binary.Write(w, binary.BigEndian, uint32(v)) }
+
+func pad4(w *bytes.Buffer) {
+	for w.Len()%4 != 0 {
+		w.WriteByte(0)
+	}
+}
@@ -0,0 +1,359 @@
+package render
+
+import (
+	"bytes"
+	_ "embed"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+)
+
+// 更新基准图: go test ./internal/render/ -run TestGolden -update
+// 测试字体 testdata/cjk.ttf 由 gen 生成，基准图用到新的中文字符时需重新生成
+//
+//go:generate go run ./gen
+
+//go:embed testdata/cjk.ttf
+var testFont []byte
+
+var updateGolden = flag.Bool("update", false, "重新生成 testdata/golden 下的基准图")
+
+const (
+	goldenBlock     = 8    // 感知比较时的分块边长
+	goldenBlockDiff = 12.0 // 单块平均亮度差阈值 (0-255)
+	goldenMaxBad    = 0.005
+	goldenMaxMean   = 1.5
+)
+
+// memImageSource 内存图片源，保证测试离线可重复
+type memImageSource map[string]image.Image
+
+func (m memImageSource) Load(url string) (image.Image, error) {
+	if img, ok := m[url]; ok {
+		return img, nil
+	}
+	return nil, fmt.Errorf("image %q not found", url)
+}
+
+// newTestRenderer 使用固定字体、固定时间和内存图片源的渲染器
+func newTestRenderer(t *testing.T) *Renderer {
+	t.Helper()
+	f, err := truetype.Parse(testFont)
+	if err != nil {
+		t.Fatalf("parse test font: %v", err)
+	}
+	return &Renderer{
+		font:   f,
+		images: testImages(),
+		now:    func() time.Time { return time.Date(2024, 2, 14, 13, 14, 0, 0, time.UTC) },
+	}
+}
+
+func testImages() memImageSource {
+	return memImageSource{
+		"mem://avatar": gradientImage(160, 160, color.RGBA{255, 153, 102, 255}, color.RGBA{102, 51, 204, 255}),
+		"mem://wide":   gradientImage(640, 360, color.RGBA{30, 144, 255, 255}, color.RGBA{240, 248, 255, 255}),
+		"mem://tall":   gradientImage(300, 600, color.RGBA{46, 139, 87, 255}, color.RGBA{255, 215, 0, 255}),
+		"mem://square": gradientImage(400, 400, color.RGBA{220, 20, 60, 255}, color.RGBA{255, 228, 225, 255}),
+	}
+}
+
+// gradientImage 生成带对角渐变和方格的测试图，裁剪/缩放错误会直接体现在像素上
+func gradientImage(w, h int, from, to color.RGBA) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			t := float64(x+y) / float64(w+h)
+			c := color.RGBA{
+				R: uint8(float64(from.R) + (float64(to.R)-float64(from.R))*t),
+				G: uint8(float64(from.G) + (float64(to.G)-float64(from.G))*t),
+				B: uint8(float64(from.B) + (float64(to.B)-float64(from.B))*t),
+				A: 255,
+			}
+			if (x/40+y/40)%2 == 0 {
+				c.R, c.G, c.B = c.R/2, c.G/2, c.B/2
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func goldenPosts() map[string]*model.Post {
+	base := func(id int64) *model.Post {
+		return &model.Post{
+			ID:         id,
+			UIN:        10001,
+			Name:       "Golden Tester",
+			AvatarURL:  "mem://avatar",
+			Status:     model.StatusPending,
+			CreateTime: 1707916440,
+		}
+	}
+
+	textOnly := base(1)
+	textOnly.Text = "Hello wall!\nThis is a text-only post that is long enough to wrap across more than one line inside the bubble."
+
+	single := base(2)
+	single.Text = "One picture"
+	single.Images = []string{"mem://wide"}
+
+	grid2 := base(3)
+	grid2.Images = []string{"mem://square", "mem://tall"}
+
+	grid4 := base(4)
+	grid4.Text = "Four pictures"
+	grid4.Images = []string{"mem://square", "mem://tall", "mem://wide", "mem://square"}
+
+	grid9 := base(5)
+	grid9.Text = "Nine pictures, one of them missing"
+	grid9.Images = []string{
+		"mem://square", "mem://tall", "mem://wide",
+		"mem://wide", "mem://missing", "mem://tall",
+		"mem://tall", "mem://wide", "mem://square",
+	}
+
+	anon := base(6)
+	anon.Anon = true
+	anon.Text = "Anonymous posts have no avatar."
+	anon.Images = []string{"mem://tall"}
+
+	alias := base(10)
+	alias.Anon = true
+	alias.AnonKey = "3f9a1c5e7b2d4608"
+	alias.Text = "Anonymous posts with a key get a pseudonym and an identicon."
+
+	cjk := base(7)
+	cjk.Name = "测试用户"
+	cjk.Text = "这是一条测试内容，包含中文标点。\nHello World! 👋\nEmoji测试：🚀 😄 🐛"
+
+	segments := base(8)
+	segments.Segments = []model.Segment{
+		{Type: model.SegReply, ID: "12345", Name: "Someone", Text: "the original message that is being replied to, long enough to be truncated at the edge"},
+		{Type: model.SegText, Text: "Thanks "},
+		{Type: model.SegAt, ID: "10002", Text: "Friend"},
+		{Type: model.SegText, Text: " for everything "},
+		{Type: model.SegFace, ID: "14", Name: "微笑"},
+		{Type: model.SegFace, ID: "66", Name: "爱心"},
+		{Type: model.SegText, Text: "\nUnknown face: "},
+		{Type: model.SegFace, ID: "99999", Name: "表情"},
+	}
+	segments.Text = model.PlainText(segments.Segments)
+
+	chat := base(9)
+	chat.Anon = true
+	chat.Images = []string{"mem://wide"}
+	chat.Chat = []model.ChatMessage{
+		{Speaker: "Alice", Segments: []model.Segment{{Type: model.SegText, Text: "Are you coming to the library tonight?"}}},
+		{Speaker: "Me", Self: true, Segments: []model.Segment{{Type: model.SegText, Text: "Yes! "}, {Type: model.SegFace, ID: "14", Name: "微笑"}}},
+		{Speaker: "Me", Self: true, Segments: []model.Segment{{Type: model.SegText, Text: "Saving you a seat by the window, it is the one with the best view of the whole campus."}}},
+		{Speaker: "Alice", Images: []int{0}},
+		{Speaker: "Bob", UIN: 10002, Segments: []model.Segment{{Type: model.SegText, Text: "ok"}}},
+	}
+	chat.Text = model.ChatText(chat.Chat)
+
+	warned := base(11)
+	warned.Text = "Spoilers ahead"
+	warned.Warning = true
+	warned.WarnReason = "spoiler"
+	warned.Images = []string{"mem://wide"}
+
+	warnedGrid := base(12)
+	warnedGrid.Warning = true
+	warnedGrid.Images = []string{"mem://square", "mem://tall", "mem://wide", "mem://square"}
+
+	markup := base(13)
+	markup.Text = "Dear **you**,\n> the *quiet* one by the window\n- coffee every morning\n- notes in the margin\n---\nLiteral \\*stars\\* stay."
+
+	return map[string]*model.Post{
+		"text_only":  textOnly,
+		"single":     single,
+		"grid2":      grid2,
+		"grid4":      grid4,
+		"grid9":      grid9,
+		"anon":       anon,
+		"cjk_emoji":  cjk,
+		"segments":   segments,
+		"chat":       chat,
+		"anon_alias": alias,
+		"warn":       warned,
+		"warn_grid":  warnedGrid,
+		"markup":     markup,
+	}
+}
+
+// TestGolden 与 testdata/golden 下的基准图做感知比较
+func TestGolden(t *testing.T) {
+	r := newTestRenderer(t)
+	for name, post := range goldenPosts() {
+		t.Run(name, func(t *testing.T) {
+			data, err := r.RenderPost(post)
+			if err != nil {
+				t.Fatalf("render: %v", err)
+			}
+			got, _, err := image.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("decode output: %v", err)
+			}
+			checkGolden(t, name, got)
+		})
+	}
+}
+
+func checkGolden(t *testing.T, name string, got image.Image) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name+".png")
+
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		// 比较只看亮度，基准图存灰度以减小体积
+		gray := image.NewGray(got.Bounds())
+		draw.Draw(gray, gray.Bounds(), got, got.Bounds().Min, draw.Src)
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, gray); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open golden (用 -update 生成): %v", err)
+	}
+	defer func() { _ = f.Close() }()
+	want, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("decode golden: %v", err)
+	}
+
+	if got.Bounds().Size() != want.Bounds().Size() {
+		t.Fatalf("尺寸不一致: got %v, want %v", got.Bounds().Size(), want.Bounds().Size())
+	}
+	bad, mean := perceptualDiff(got, want)
+	if bad > goldenMaxBad || mean > goldenMaxMean {
+		t.Errorf("与基准图差异过大: 异常块比例 %.4f (上限 %.4f), 平均亮度差 %.2f (上限 %.2f)",
+			bad, goldenMaxBad, mean, goldenMaxMean)
+	}
+}
+
+// perceptualDiff 按块比较平均亮度，忽略 JPEG 压缩带来的细微噪点。
+// 返回差异超过阈值的块所占比例，以及全部块的平均亮度差。
+func perceptualDiff(a, b image.Image) (badRatio, meanDiff float64) {
+	ab, bb := a.Bounds(), b.Bounds()
+	var blocks, bad int
+	var total float64
+	for y := 0; y < ab.Dy(); y += goldenBlock {
+		for x := 0; x < ab.Dx(); x += goldenBlock {
+			la := blockLuma(a, ab.Min.X+x, ab.Min.Y+y, ab.Max)
+			lb := blockLuma(b, bb.Min.X+x, bb.Min.Y+y, bb.Max)
+			d := math.Abs(la - lb)
+			total += d
+			if d > goldenBlockDiff {
+				bad++
+			}
+			blocks++
+		}
+	}
+	if blocks == 0 {
+		return 0, 0
+	}
+	return float64(bad) / float64(blocks), total / float64(blocks)
+}
+
+func blockLuma(img image.Image, x0, y0 int, max image.Point) float64 {
+	var sum float64
+	var n int
+	for y := y0; y < y0+goldenBlock && y < max.Y; y++ {
+		for x := x0; x < x0+goldenBlock && x < max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			sum += (0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)) / 257
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// TestGoldenJustify 两端对齐主题
+func TestGoldenJustify(t *testing.T) {
+	r := newTestRenderer(t)
+	theme, _ := r.Theme("")
+	theme.Justify = true
+	post := goldenPosts()["text_only"]
+	post.Text += "\n中文排版测试，标点不应出现在行首。这一行足够长，可以用来检查两端对齐与避头尾规则是否生效。"
+	data, err := r.RenderPostWithTheme(post, theme)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	got, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	checkGolden(t, "justify", got)
+}
+
+// TestGoldenDigest 合集长图的基准比较
+func TestGoldenDigest(t *testing.T) {
+	r := newTestRenderer(t)
+	posts := goldenPosts()
+	list := []*model.Post{posts["text_only"], posts["grid2"], posts["anon"]}
+	data, included, err := r.RenderDigest(list, DigestTitle{WallName: "Golden Wall", Kind: "Daily", Issue: 3, Date: "2024/02/14"})
+	if err != nil {
+		t.Fatalf("render digest: %v", err)
+	}
+	if len(included) != len(list) {
+		t.Errorf("included %d posts, want %d", len(included), len(list))
+	}
+	got, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	checkGolden(t, "digest", got)
+}
+
+func TestDigestHeightLimit(t *testing.T) {
+	r := newTestRenderer(t)
+	post := &model.Post{ID: 9, Anon: true, Text: strings.Repeat("tall line\n", 80)}
+	list := make([]*model.Post, 10)
+	for i := range list {
+		list[i] = post
+	}
+	theme, _ := r.Theme("")
+	img, n := r.drawDigest(list, DigestTitle{}, theme, 1)
+	if h := float64(img.Bounds().Dy()); h > DigestMaxHeight {
+		t.Errorf("digest height = %.0f, want <= %.0f", h, DigestMaxHeight)
+	}
+	if n == 0 || n >= len(list) {
+		t.Errorf("included %d of %d posts, want some omitted", n, len(list))
+	}
+	// 返回的稿件与实际收录的一致
+	_, included, err := r.RenderDigest(list, DigestTitle{})
+	if err != nil {
+		t.Fatalf("render digest: %v", err)
+	}
+	if len(included) != n {
+		t.Errorf("RenderDigest returned %d posts, drawn %d", len(included), n)
+	}
+	if _, _, err := r.RenderDigest(nil, DigestTitle{}); err == nil {
+		t.Error("empty digest should fail")
+	}
+}
@@ -0,0 +1,72 @@
+package render
+
+import (
+	"crypto/sha256"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+const identiconGrid = 5
+
+// identicon 由匿名标识生成对称方块头像 (GitHub 风格)，同一标识总是得到同一图案
+func identicon(key string, size int) image.Image {
+	sum := sha256.Sum256([]byte(key))
+
+	hue := float64(int(sum[0])<<8|int(sum[1])) / 65535 * 360
+	fg := hslColor(hue, 0.55, 0.55)
+	bg := color.RGBA{R: 240, G: 240, B: 240, A: 255}
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: bg}, image.Point{}, draw.Src)
+
+	// 圆形裁剪后四角不可见，图案放在内圈
+	margin := float64(size) * 0.18
+	cell := (float64(size) - 2*margin) / identiconGrid
+	half := (identiconGrid + 1) / 2
+	for row := 0; row < identiconGrid; row++ {
+		for col := 0; col < half; col++ {
+			bit := row*half + col
+			if sum[2+bit/8]>>(bit%8)&1 == 0 {
+				continue
+			}
+			for _, c := range []int{col, identiconGrid - 1 - col} {
+				x0 := int(math.Round(margin + float64(c)*cell))
+				y0 := int(math.Round(margin + float64(row)*cell))
+				x1 := int(math.Round(margin + float64(c+1)*cell))
+				y1 := int(math.Round(margin + float64(row+1)*cell))
+				draw.Draw(img, image.Rect(x0, y0, x1, y1), &image.Uniform{C: fg}, image.Point{}, draw.Src)
+			}
+		}
+	}
+	return img
+}
+
+// hslColor HSL 转 RGB，h 取 0-360，s/l 取 0-1
+func hslColor(h, s, l float64) color.RGBA {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return color.RGBA{
+		R: uint8(math.Round((r + m) * 255)),
+		G: uint8(math.Round((g + m) * 255)),
+		B: uint8(math.Round((b + m) * 255)),
+		A: 255,
+	}
+}
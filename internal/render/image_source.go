@@ -0,0 +1,116 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ImageSource 图片加载接口，RenderPost 通过它获取头像与配图
+type ImageSource interface {
+	Load(url string) (image.Image, error)
+}
+
+// RawImageSource 可选接口：返回未解码的原始数据，用于发布时原样附带原图和动图
+type RawImageSource interface {
+	LoadRaw(url string) ([]byte, error)
+}
+
+// maxDownloadBytes 单张图片的下载上限
+const maxDownloadBytes = 50 << 20
+
+// HTTPImageSource 默认图片源：本地上传文件走文件系统，其余走 HTTP 下载
+type HTTPImageSource struct {
+	Client *http.Client
+}
+
+// NewHTTPImageSource 创建默认图片源
+func NewHTTPImageSource() *HTTPImageSource {
+	return &HTTPImageSource{Client: &http.Client{Timeout: 8 * time.Second}}
+}
+
+// Load 加载并解码图片 (见 decodeImage)
+func (s *HTTPImageSource) Load(url string) (image.Image, error) {
+	data, err := s.LoadRaw(url)
+	if err != nil {
+		return nil, err
+	}
+	return decodeImage(data)
+}
+
+// LoadRaw 读取图片原始数据
+func (s *HTTPImageSource) LoadRaw(url string) ([]byte, error) {
+	if url == "" {
+		return nil, fmt.Errorf("empty image url")
+	}
+	if local := resolveLocalUploadPath(url); local != "" {
+		data, err := os.ReadFile(local)
+		if err != nil {
+			return nil, fmt.Errorf("open local image %s: %w", local, err)
+		}
+		return data, nil
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("download image: status %d", resp.StatusCode)
+	}
+	// 多读一个字节判断是否超限，避免把截断的图片当作完整数据
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("download image: %w", err)
+	}
+	if len(data) > maxDownloadBytes {
+		return nil, fmt.Errorf("download image: too large (over %d MB)", maxDownloadBytes>>20)
+	}
+	return data, nil
+}
+
+func resolveLocalUploadPath(raw string) string {
+	// 1. 如果是 http/https 网络链接，直接返回空，交给后续的 http 下载逻辑处理
+	if strings.HasPrefix(raw, "http://") || strings.HasPrefix(raw, "https://") {
+		return ""
+	}
+
+	// 2. 清理路径格式 (处理 Windows/Linux 分隔符差异)
+	path := filepath.Clean(raw)
+
+	// 3. 核心逻辑：直接检查文件是否存在
+	// server.go 传递过来的是绝对路径，os.Stat 能直接找到它
+	info, err := os.Stat(path)
+	if err == nil && !info.IsDir() {
+		// 文件存在且不是文件夹，返回该路径供 os.Open 使用
+		return path
+	}
+
+	// 4. (保底逻辑) 如果传进来的是相对路径，尝试拼接当前运行目录下的 uploads
+	// 这一步通常用不到，因为 server.go 已经转成绝对路径了，但留着防守
+	wd, _ := os.Getwd()
+	absPath := filepath.Join(wd, path)
+	info, err = os.Stat(absPath)
+	if err == nil && !info.IsDir() {
+		return absPath
+	}
+
+	return ""
+}
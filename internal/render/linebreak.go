@@ -0,0 +1,290 @@
+package render
+
+import (
+	"image"
+	"math"
+	"strings"
+	"unicode"
+
+	"golang.org/x/image/font"
+)
+
+// ──────────────────────────────────────────
+// 断行规则
+//
+//   - 拉丁字母/数字组成的单词不拆开，在空格和连字符后断行
+//   - 中日韩文字之间可以断行
+//   - 避头尾：闭合标点不出现在行首，开启标点不出现在行尾
+//   - 行尾的 ，。、 等标点允许悬挂出边界
+//   - 超过整行宽度的长串 (URL 等) 逐字强制断开
+//   - 制表符展开到下一个制表位
+// ──────────────────────────────────────────
+
+const tabSpaces = 4 // 制表位宽度 (空格数)
+
+// noLineStart 不能出现在行首的字符 (行首禁则)
+const noLineStart = "!%),.:;?]}¢°’”、。〉》」』】〕〗〙〛！），．：；？］｝～…‥·・ー々〆ぁぃぅぇぉっゃゅょゎァィゥェォッャュョヮヵヶ"
+
+// noLineEnd 不能出现在行尾的字符 (行尾禁则)
+const noLineEnd = "([{£¥‘“〈《「『【〔〖〘〚（［｛＄￡￥"
+
+// hangingPunct 允许悬挂在行尾之外的标点
+const hangingPunct = "，。、．,."
+
+// inlineItem 排版的最小单位：一个字符或一个表情
+type inlineItem struct {
+	r     rune        // '\n' 为硬换行，'\t' 为制表符
+	face  image.Image // 非 nil 时为表情
+	style inlineStyle
+	w     float64
+	x     float64 // 排版后相对行首的位置
+}
+
+func (it inlineItem) isSpace() bool {
+	return it.face == nil && (it.r == ' ' || it.r == '\t' || it.r == '　')
+}
+
+// isCJK 中日韩文字、全角符号与 emoji，两侧均可断行
+func isCJK(r rune) bool {
+	switch {
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+		return true
+	case r >= 0x3000 && r <= 0x303F, r >= 0xFF00 && r <= 0xFFEF:
+		return true
+	case r >= 0x1F000:
+		return true
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return !isCJK(r) && (unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_' || r == '\'')
+}
+
+func isHyphen(r rune) bool {
+	return r == '-' || r == '–' || r == '—'
+}
+
+// canBreakBetween 判断 a、b 两个相邻单位之间是否允许断行
+func canBreakBetween(a, b inlineItem) bool {
+	if b.face == nil && strings.ContainsRune(noLineStart, b.r) {
+		return false
+	}
+	if a.face == nil && strings.ContainsRune(noLineEnd, a.r) {
+		return false
+	}
+	switch {
+	case a.isSpace():
+		return true
+	case b.isSpace():
+		return false
+	case a.face != nil || b.face != nil:
+		return true
+	case isCJK(a.r) || isCJK(b.r):
+		return true
+	case isHyphen(a.r) && isWordRune(b.r):
+		return true
+	case strings.ContainsRune(noLineStart, a.r) && isWordRune(b.r):
+		// 3.14 / example.com 之类不拆开，全角标点后可以断
+		return a.r > unicode.MaxASCII
+	}
+	return false
+}
+
+// itemsOf 将文字拆为排版单位。每个字符的宽度包含与前一字符的字距调整，
+// 保证合并绘制时与逐字累加的位置一致
+func itemsOf(face font.Face, text string, style inlineStyle) []inlineItem {
+	items := make([]inlineItem, 0, len(text))
+	prev, prevW := "", 0.0
+	for _, r := range text {
+		it := inlineItem{r: r, style: style}
+		if r == '\n' || r == '\t' {
+			prev, prevW = "", 0
+			items = append(items, it)
+			continue
+		}
+		s := string(r)
+		it.w = measure(face, prev+s) - prevW
+		prevW = measure(face, s)
+		prev = s
+		items = append(items, it)
+	}
+	return items
+}
+
+// measure 测量文字宽度 (保留小数，gg.MeasureString 会截断为整数)
+func measure(face font.Face, s string) float64 {
+	return float64(font.MeasureString(face, s)) / 64
+}
+
+// breakItems 将排版单位断成行并转为绘制片段。justify 为 true 时，
+// 除段落末行外的每一行都拉伸到 maxWidth (两端对齐)。
+func breakItems(face font.Face, items []inlineItem, maxWidth float64, justify bool) [][]inlineRun {
+	tabW := measure(face, " ") * tabSpaces
+
+	var lines [][]inlineRun
+	for _, para := range splitParagraphs(items) {
+		broken := breakParagraph(para, maxWidth, tabW)
+		for i, line := range broken {
+			if justify && i < len(broken)-1 {
+				justifyLine(line, maxWidth)
+			}
+			lines = append(lines, toRuns(line))
+		}
+	}
+	return lines
+}
+
+func splitParagraphs(items []inlineItem) [][]inlineItem {
+	var paras [][]inlineItem
+	start := 0
+	for i, it := range items {
+		if it.face == nil && it.r == '\n' {
+			paras = append(paras, items[start:i])
+			start = i + 1
+		}
+	}
+	return append(paras, items[start:])
+}
+
+// breakParagraph 贪心断行：按断行机会切成不可拆分的片段，放不下时换行
+func breakParagraph(para []inlineItem, maxWidth, tabW float64) [][]inlineItem {
+	if len(para) == 0 {
+		return [][]inlineItem{nil}
+	}
+
+	var (
+		lines [][]inlineItem
+		line  []inlineItem
+		x     float64
+	)
+	place := func(it inlineItem) {
+		if it.face == nil && it.r == '\t' {
+			it.w = tabW - math.Mod(x, tabW)
+		}
+		it.x = x
+		x += it.w
+		line = append(line, it)
+	}
+	newLine := func() {
+		// 行尾空白不占宽度，直接丢弃
+		for len(line) > 0 && line[len(line)-1].isSpace() {
+			line = line[:len(line)-1]
+		}
+		lines = append(lines, line)
+		line, x = nil, 0
+	}
+
+	for start := 0; start < len(para); {
+		end := start + 1
+		for end < len(para) && !canBreakBetween(para[end-1], para[end]) {
+			end++
+		}
+		unit := para[start:end]
+		start = end
+
+		if fits(unit, x, maxWidth, tabW) {
+			for _, it := range unit {
+				place(it)
+			}
+			continue
+		}
+		if len(line) > 0 {
+			newLine()
+			if fits(unit, 0, maxWidth, tabW) {
+				for _, it := range unit {
+					place(it)
+				}
+				continue
+			}
+		}
+		// 整行都放不下的长串：逐字强制断开
+		for _, it := range unit {
+			if len(line) > 0 && !it.isSpace() && x+it.w > maxWidth {
+				newLine()
+			}
+			place(it)
+		}
+	}
+	newLine()
+	return lines
+}
+
+// fits 判断片段放在 x 处是否不超宽 (行尾空白不计，末尾的悬挂标点可超出)
+func fits(unit []inlineItem, x, maxWidth, tabW float64) bool {
+	end := len(unit)
+	for end > 0 && unit[end-1].isSpace() {
+		end--
+	}
+	var hang float64
+	for i, it := range unit[:end] {
+		w := it.w
+		if it.face == nil && it.r == '\t' {
+			w = tabW - math.Mod(x, tabW)
+		}
+		x += w
+		if i == end-1 && it.face == nil && strings.ContainsRune(hangingPunct, it.r) {
+			hang = w
+		}
+	}
+	return x-hang <= maxWidth
+}
+
+// canStretch 两端对齐时可以加宽的间隙：断行机会处，连字符后除外
+func canStretch(a, b inlineItem) bool {
+	return canBreakBetween(a, b) && (a.face != nil || !isHyphen(a.r))
+}
+
+// justifyLine 将行内剩余空间平均分配到可加宽的间隙
+func justifyLine(line []inlineItem, maxWidth float64) {
+	if len(line) < 2 {
+		return
+	}
+	last := line[len(line)-1]
+	extra := maxWidth - (last.x + last.w)
+	if extra <= 0 {
+		return
+	}
+	var gaps int
+	for i := 1; i < len(line); i++ {
+		if canStretch(line[i-1], line[i]) {
+			gaps++
+		}
+	}
+	if gaps == 0 {
+		return
+	}
+	step, shift := extra/float64(gaps), 0.0
+	for i := 1; i < len(line); i++ {
+		if canStretch(line[i-1], line[i]) {
+			shift += step
+		}
+		line[i].x += shift
+	}
+}
+
+// toRuns 合并相邻、同样式且紧挨着的字符，减少绘制次数
+func toRuns(line []inlineItem) []inlineRun {
+	var runs []inlineRun
+	for _, it := range line {
+		if it.face != nil {
+			runs = append(runs, inlineRun{face: it.face, x: it.x, w: it.w})
+			continue
+		}
+		if it.r == '\t' {
+			// 制表符只占位，不绘制
+			runs = append(runs, inlineRun{x: it.x, w: it.w})
+			continue
+		}
+		if n := len(runs); n > 0 {
+			prev := &runs[n-1]
+			if prev.text != "" && prev.style == it.style && math.Abs(prev.x+prev.w-it.x) < 0.01 {
+				prev.text += string(it.r)
+				prev.w += it.w
+				continue
+			}
+		}
+		runs = append(runs, inlineRun{text: string(it.r), style: it.style, x: it.x, w: it.w})
+	}
+	return runs
+}
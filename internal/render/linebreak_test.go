@@ -0,0 +1,157 @@
+package render
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/guohuiyuan/qzonewall-go/internal/validate"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func newTestFace(t *testing.T) font.Face {
+	t.Helper()
+	f, err := truetype.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return truetype.NewFace(f, &truetype.Options{Size: 20})
+}
+
+// width 返回 n 个 s 连写的宽度，用于按字数构造行宽
+func width(face font.Face, s string, n int) float64 {
+	return measure(face, strings.Repeat(s, n))
+}
+
+func TestWordWrapKeepsLatinWords(t *testing.T) {
+	face := newTestFace(t)
+	text := "hello wonderful world"
+	lines := WordWrap(face, text, width(face, "wonderful", 1)+1)
+	want := []string{"hello", "wonderful", "world"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+}
+
+func TestWordWrapNumbersAndHyphens(t *testing.T) {
+	face := newTestFace(t)
+	lines := WordWrap(face, "pi is 3.14159 well-known", width(face, "w", 9))
+	for _, l := range lines {
+		if strings.HasPrefix(l, ".") || strings.HasSuffix(l, "3") {
+			t.Errorf("number split across lines: %q", lines)
+		}
+	}
+	joined := strings.Join(lines, "|")
+	if !strings.Contains(joined, "well-|known") && !strings.Contains(joined, "well-known") {
+		t.Errorf("hyphenated word broken in the wrong place: %q", lines)
+	}
+}
+
+func TestWordWrapKinsoku(t *testing.T) {
+	face := newTestFace(t)
+	// 每行恰好放下 4 个汉字，第 5 个位置是逗号和句号
+	text := "一二三四，五六七八。"
+	lines := WordWrap(face, text, width(face, "一", 4))
+	for i, l := range lines {
+		if i > 0 && (strings.HasPrefix(l, "，") || strings.HasPrefix(l, "。")) {
+			t.Errorf("line %d starts with closing punctuation: %q", i, lines)
+		}
+	}
+	// 悬挂：逗号留在第一行行尾
+	if len(lines) == 0 || lines[0] != "一二三四，" {
+		t.Errorf("first line = %q, want hanging comma", lines)
+	}
+
+	lines = WordWrap(face, "一二三「四五", width(face, "一", 4))
+	for i, l := range lines {
+		if strings.HasSuffix(l, "「") {
+			t.Errorf("line %d ends with opening bracket: %q", i, lines)
+		}
+	}
+}
+
+func TestWordWrapLongToken(t *testing.T) {
+	face := newTestFace(t)
+	url := "https://example.com/a/very/long/path/that/cannot/fit/on/one/line"
+	maxW := width(face, "w", 10)
+	lines := WordWrap(face, "see "+url, maxW)
+	if len(lines) < 3 {
+		t.Fatalf("url not hard-broken: %q", lines)
+	}
+	if lines[0] != "see" {
+		t.Errorf("url should start on its own line, got %q", lines)
+	}
+	if got := strings.Join(lines[1:], ""); got != url {
+		t.Errorf("url pieces = %q, want %q", got, url)
+	}
+	for _, l := range lines {
+		if w := measure(face, l); w > maxW+0.01 {
+			t.Errorf("line %q wider than max (%.1f > %.1f)", l, w, maxW)
+		}
+	}
+}
+
+func TestWordWrapTabsAndNewlines(t *testing.T) {
+	face := newTestFace(t)
+	lines := WordWrap(face, "a\tb\n\nc", 1000)
+	if len(lines) != 3 || lines[1] != "" || lines[2] != "c" {
+		t.Fatalf("paragraphs = %q", lines)
+	}
+
+	// 制表符后的文字对齐到制表位
+	tabW := width(face, " ", tabSpaces)
+	for _, prefix := range []string{"a", "abcdefgh"} {
+		text := prefix + "\tb"
+		stop := math.Floor(measure(face, prefix)/tabW+1) * tabW
+		runs := breakItems(face, itemsOf(face, text, 0), 1000, false)[0]
+		last := runs[len(runs)-1]
+		if last.text != "b" || last.x < stop-0.01 || last.x > stop+0.01 {
+			t.Errorf("%q: b at %.2f, want tab stop %.2f", text, last.x, stop)
+		}
+	}
+}
+
+// TestValidatedIndent 经过投稿校验后，制表符与行首缩进仍能排到制表位上
+func TestValidatedIndent(t *testing.T) {
+	face := newTestFace(t)
+	text := validate.Normalize("if ok {  \n\treturn\n\t\tdone\n  }")
+	lines := breakItems(face, itemsOf(face, text, 0), 1000, false)
+	if len(lines) != 4 {
+		t.Fatalf("lines = %d, want 4 (text %q)", len(lines), text)
+	}
+	tabW := width(face, " ", tabSpaces)
+	want := []struct {
+		text string
+		x    float64
+	}{{"return", tabW}, {"done", 2 * tabW}, {"}", width(face, " ", 2)}}
+	for i, w := range want {
+		// 行首空格可能与文字合并在同一片段中
+		last := lines[i+1][len(lines[i+1])-1]
+		got := strings.TrimLeft(last.text, " ")
+		x := last.x + measure(face, last.text[:len(last.text)-len(got)])
+		if got != w.text || math.Abs(x-w.x) > 0.01 {
+			t.Errorf("line %d: %q at %.2f, want %q at %.2f", i+1, got, x, w.text, w.x)
+		}
+	}
+}
+
+func TestJustify(t *testing.T) {
+	face := newTestFace(t)
+	maxW := width(face, "w", 12)
+	lines := breakItems(face, itemsOf(face, "aa bb cc dd ee ff gg hh", 0), maxW, true)
+	if len(lines) < 2 {
+		t.Fatalf("expected wrapping, got %d lines", len(lines))
+	}
+	for i, line := range lines {
+		last := line[len(line)-1]
+		end := last.x + last.w
+		if i < len(lines)-1 && end < maxW-0.5 {
+			t.Errorf("line %d not justified: ends at %.1f, max %.1f", i, end, maxW)
+		}
+		if i == len(lines)-1 && end >= maxW-0.5 {
+			t.Errorf("last line should stay ragged, ends at %.1f", end)
+		}
+	}
+}
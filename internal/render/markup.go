@@ -0,0 +1,307 @@
+package render
+
+import (
+	"image"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fogleman/gg"
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	"golang.org/x/image/font"
+)
+
+// ──────────────────────────────────────────
+// 轻量标记 (仅用于投稿正文)
+//
+//   **粗体**、*强调*
+//   行首 "> " 引用，连续多行为同一引用块
+//   行首 "- "、"* "、"+ "、"• " 为无序列表，"1. " 为有序列表
+//   单独一行 --- / *** / ___ 为分隔线
+//   反斜杠转义 ASCII 标点：\* 输出字面星号
+// ──────────────────────────────────────────
+
+const (
+	emShear    = 0.2  // 强调文字的斜切系数
+	boldOffset = 0.04 // 粗体重绘的水平错位 (相对字高)
+)
+
+type blockKind uint8
+
+const (
+	blockPara blockKind = iota
+	blockQuote
+	blockItem
+	blockRule
+)
+
+// mdBlock 块级结构
+type mdBlock struct {
+	kind   blockKind
+	marker string          // 列表项符号："•" 或 "1."
+	segs   []model.Segment // 去掉块标记后的内容，段落和引用可含多行
+}
+
+// textLine 排版后的一行，附带所属块的装饰信息
+type textLine struct {
+	runs   []inlineRun
+	kind   blockKind
+	indent float64 // 相对内容左边缘的缩进
+	marker string  // 列表项首行绘制的符号
+}
+
+var orderedMarker = regexp.MustCompile(`^(\d{1,3})[.、)]\s+`)
+
+// splitLines 按换行符将消息段拆成行
+func splitLines(segs []model.Segment) [][]model.Segment {
+	lines := [][]model.Segment{nil}
+	for _, seg := range segs {
+		if seg.Type != model.SegText {
+			lines[len(lines)-1] = append(lines[len(lines)-1], seg)
+			continue
+		}
+		for i, part := range strings.Split(seg.Text, "\n") {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+			if part != "" {
+				lines[len(lines)-1] = append(lines[len(lines)-1], model.Segment{Type: model.SegText, Text: part})
+			}
+		}
+	}
+	return lines
+}
+
+// isRule 是否为分隔线：同一字符 (- * _) 至少三个，允许夹空格
+func isRule(text string) bool {
+	text = strings.ReplaceAll(strings.TrimSpace(text), " ", "")
+	if len(text) < 3 {
+		return false
+	}
+	c := text[0]
+	if c != '-' && c != '*' && c != '_' {
+		return false
+	}
+	return strings.Count(text, string(c)) == len(text)
+}
+
+// lineBlock 识别一行的块标记，返回块类型、列表符号和去掉标记后的内容
+func lineBlock(line []model.Segment) (blockKind, string, []model.Segment) {
+	if len(line) == 0 || line[0].Type != model.SegText {
+		return blockPara, "", line
+	}
+	text := line[0].Text
+	if len(line) == 1 && isRule(text) {
+		return blockRule, "", nil
+	}
+
+	kind, marker := blockPara, ""
+	switch {
+	case strings.HasPrefix(text, "> "), text == ">":
+		// 要求 ">" 后有空格，避免 ">_<" 之类的颜文字被当成引用
+		kind, text = blockQuote, strings.TrimPrefix(text[1:], " ")
+	case strings.HasPrefix(text, "- "), strings.HasPrefix(text, "* "),
+		strings.HasPrefix(text, "+ "), strings.HasPrefix(text, "• "):
+		_, size := utf8.DecodeRuneInString(text)
+		kind, marker, text = blockItem, "•", strings.TrimLeft(text[size:], " ")
+	default:
+		if m := orderedMarker.FindStringSubmatch(text); m != nil {
+			kind, marker, text = blockItem, m[1]+".", text[len(m[0]):]
+		}
+	}
+	if kind == blockPara {
+		return kind, "", line
+	}
+
+	rest := append([]model.Segment(nil), line...)
+	rest[0].Text = text
+	if text == "" {
+		rest = rest[1:]
+	}
+	return kind, marker, rest
+}
+
+// isEscapable 反斜杠后可转义的字符 (ASCII 标点)
+func isEscapable(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsPunct(r) || unicode.IsSymbol(r))
+}
+
+// parseBlocks 将消息段解析为块，相邻的段落行或引用行合并为一块 (保留换行)
+func parseBlocks(segs []model.Segment) []mdBlock {
+	var blocks []mdBlock
+	for _, line := range splitLines(segs) {
+		kind, marker, rest := lineBlock(line)
+		if n := len(blocks); n > 0 && blocks[n-1].kind == kind && (kind == blockPara || kind == blockQuote) {
+			b := &blocks[n-1]
+			b.segs = append(b.segs, model.Segment{Type: model.SegText, Text: "\n"})
+			b.segs = append(b.segs, rest...)
+			continue
+		}
+		blocks = append(blocks, mdBlock{kind: kind, marker: marker, segs: rest})
+	}
+	return blocks
+}
+
+// mdToken 行内解析的最小单位：一个字符、一个非文字消息段或一个星号定界符
+type mdToken struct {
+	r     rune
+	seg   *model.Segment
+	delim int // 1 为 *，2 为 **；配对后置为 -1
+	style inlineStyle
+}
+
+func (t mdToken) isSpace() bool {
+	return t.seg == nil && t.delim == 0 && unicode.IsSpace(t.r)
+}
+
+// parseInline 解析粗体、强调与转义。定界符需成对出现：
+// 开始符后面和结束符前面不能是空白，不能跨行；不成对的星号按原样输出
+func parseInline(segs []model.Segment) []styledSeg {
+	var toks []mdToken
+	for i := range segs {
+		if segs[i].Type != model.SegText {
+			toks = append(toks, mdToken{seg: &segs[i]})
+			continue
+		}
+		runes := []rune(segs[i].Text)
+		for j := 0; j < len(runes); j++ {
+			switch r := runes[j]; {
+			case r == '\\' && j+1 < len(runes) && isEscapable(runes[j+1]):
+				j++
+				toks = append(toks, mdToken{r: runes[j]})
+			case r == '*':
+				n := 1
+				for j+n < len(runes) && runes[j+n] == '*' {
+					n++
+				}
+				// 奇数个星号拆成 ** 和 *：开始处 * 在内侧 (后)，结束处 * 在内侧 (前)
+				closing := j > 0 && !unicode.IsSpace(runes[j-1])
+				j += n - 1
+				if n%2 == 1 && closing {
+					toks = append(toks, mdToken{delim: 1})
+				}
+				for ; n >= 2; n -= 2 {
+					toks = append(toks, mdToken{delim: 2})
+				}
+				if n == 1 && !closing {
+					toks = append(toks, mdToken{delim: 1})
+				}
+			default:
+				toks = append(toks, mdToken{r: r})
+			}
+		}
+	}
+
+	open := map[int][]int{}
+	for i, t := range toks {
+		if t.seg == nil && t.delim == 0 && t.r == '\n' {
+			open = map[int][]int{}
+			continue
+		}
+		if t.delim <= 0 {
+			continue
+		}
+		canClose := i > 0 && !toks[i-1].isSpace()
+		canOpen := i+1 < len(toks) && !toks[i+1].isSpace()
+		if stack := open[t.delim]; canClose && len(stack) > 0 {
+			j := stack[len(stack)-1]
+			open[t.delim] = stack[:len(stack)-1]
+			style := styleEm
+			if t.delim == 2 {
+				style = styleStrong
+			}
+			for k := j + 1; k < i; k++ {
+				toks[k].style |= style
+			}
+			toks[j].delim, toks[i].delim = -1, -1
+			// 交叉的另一种定界符不再配对
+			other := 3 - t.delim
+			for len(open[other]) > 0 && open[other][len(open[other])-1] > j {
+				open[other] = open[other][:len(open[other])-1]
+			}
+			continue
+		}
+		if canOpen {
+			open[t.delim] = append(open[t.delim], i)
+		}
+	}
+
+	var out []styledSeg
+	addText := func(s string, style inlineStyle) {
+		if n := len(out); n > 0 && out[n-1].Type == model.SegText && out[n-1].style == style {
+			out[n-1].Text += s
+			return
+		}
+		out = append(out, styledSeg{Segment: model.Segment{Type: model.SegText, Text: s}, style: style})
+	}
+	for _, t := range toks {
+		switch {
+		case t.seg != nil:
+			out = append(out, styledSeg{Segment: *t.seg, style: t.style})
+		case t.delim > 0:
+			addText(strings.Repeat("*", t.delim), t.style)
+		case t.delim == 0:
+			addText(string(t.r), t.style)
+		}
+	}
+	return out
+}
+
+// layoutMarkup 解析标记并排版正文，引用和列表按缩进后的宽度断行
+func layoutMarkup(face font.Face, segs []model.Segment, maxWidth, faceSize float64, justify bool) []textLine {
+	quoteIndent := faceSize * 0.8
+	var lines []textLine
+	for _, b := range parseBlocks(segs) {
+		indent := 0.0
+		switch b.kind {
+		case blockRule:
+			lines = append(lines, textLine{kind: blockRule})
+			continue
+		case blockQuote:
+			indent = quoteIndent
+		case blockItem:
+			indent = math.Max(faceSize*1.2, measure(face, b.marker+" "))
+		}
+		items := segmentItems(face, parseInline(b.segs), faceSize)
+		for i, runs := range breakItems(face, items, maxWidth-indent, justify) {
+			line := textLine{runs: runs, kind: b.kind, indent: indent}
+			if i == 0 {
+				line.marker = b.marker
+			}
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// drawTextLines 绘制排版后的正文。baseline 为首行基线，width 为内容宽度
+func drawTextLines(dc *gg.Context, lines []textLine, theme Theme, x, baseline, lineH, width, ascent, descent float64, faceCache map[image.Image]image.Image) {
+	quoteTheme := theme
+	quoteTheme.TextColor = theme.QuoteTextColor
+	barW := math.Max(2, ascent*0.15)
+
+	for i, line := range lines {
+		y := baseline + float64(i)*lineH
+		top := y - ascent - (lineH-ascent-descent)/2
+		switch line.kind {
+		case blockRule:
+			dc.SetHexColor(theme.RuleColor)
+			dc.DrawRectangle(x, top+lineH/2-barW/4, width, math.Max(1, barW/2))
+			dc.Fill()
+			continue
+		case blockQuote:
+			dc.SetHexColor(theme.QuoteBar)
+			dc.DrawRectangle(x, top, barW, lineH)
+			dc.Fill()
+			drawInlineLine(dc, line.runs, quoteTheme, x+line.indent, y, ascent, descent, faceCache)
+			continue
+		}
+		if line.marker != "" {
+			dc.SetHexColor(theme.BulletColor)
+			dc.DrawString(line.marker, x, y)
+		}
+		drawInlineLine(dc, line.runs, theme, x+line.indent, y, ascent, descent, faceCache)
+	}
+}
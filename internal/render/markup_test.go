@@ -0,0 +1,113 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+)
+
+func textSegs(s string) []model.Segment {
+	return []model.Segment{{Type: model.SegText, Text: s}}
+}
+
+// spans 将解析结果转为 "文字/样式" 列表便于比较
+func spans(segs []styledSeg) []string {
+	var out []string
+	for _, s := range segs {
+		tag := ""
+		if s.style&styleStrong != 0 {
+			tag += "B"
+		}
+		if s.style&styleEm != 0 {
+			tag += "I"
+		}
+		out = append(out, s.Text+"/"+tag)
+	}
+	return out
+}
+
+func TestParseInline(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []string
+	}{
+		{"plain", []string{"plain/"}},
+		{"a **bold** b", []string{"a /", "bold/B", " b/"}},
+		{"*em* and **strong *both***", []string{"em/I", " and /", "strong /B", "both/BI"}},
+		{`literal \*stars\* here`, []string{"literal *stars* here/"}},
+		{"2 * 3 * 4", []string{"2 * 3 * 4/"}},
+		{"**unclosed", []string{"**unclosed/"}},
+		{"*no\nspan*", []string{"*no\nspan*/"}},
+		{`C:\Users\me`, []string{`C:\Users\me/`}},
+	}
+	for _, c := range cases {
+		got := spans(parseInline(textSegs(c.in)))
+		if len(got) != len(c.want) {
+			t.Errorf("%q: got %q, want %q", c.in, got, c.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("%q: got %q, want %q", c.in, got, c.want)
+				break
+			}
+		}
+	}
+}
+
+func TestParseInlineKeepsSegments(t *testing.T) {
+	segs := []model.Segment{
+		{Type: model.SegText, Text: "**hi "},
+		{Type: model.SegAt, ID: "10002", Text: "Friend"},
+		{Type: model.SegText, Text: "**"},
+	}
+	got := parseInline(segs)
+	if len(got) != 2 || got[1].Type != model.SegAt || got[1].style&styleStrong == 0 {
+		t.Errorf("mention inside bold not styled: %+v", got)
+	}
+}
+
+func TestParseBlocks(t *testing.T) {
+	text := "Dear you,\n> line one\n> line two\n- apple\n* banana\n12. twelfth\n---\n>_< not a quote\n\\- not a list"
+	blocks := parseBlocks(textSegs(text))
+	want := []struct {
+		kind   blockKind
+		marker string
+		text   string
+	}{
+		{blockPara, "", "Dear you,"},
+		{blockQuote, "", "line one\nline two"},
+		{blockItem, "•", "apple"},
+		{blockItem, "•", "banana"},
+		{blockItem, "12.", "twelfth"},
+		{blockRule, "", ""},
+		{blockPara, "", ">_< not a quote\n\\- not a list"},
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("got %d blocks, want %d: %+v", len(blocks), len(want), blocks)
+	}
+	for i, w := range want {
+		b := blocks[i]
+		if b.kind != w.kind || b.marker != w.marker || model.PlainText(b.segs) != w.text {
+			t.Errorf("block %d = {%d %q %q}, want {%d %q %q}", i, b.kind, b.marker, model.PlainText(b.segs), w.kind, w.marker, w.text)
+		}
+	}
+}
+
+func TestLayoutMarkupIndent(t *testing.T) {
+	face := newTestFace(t)
+	maxW := width(face, "w", 12)
+	lines := layoutMarkup(face, textSegs("- one two three four five six seven\n> quoted"), maxW, 20, false)
+	if len(lines) < 3 {
+		t.Fatalf("expected the list item to wrap, got %d lines", len(lines))
+	}
+	if lines[0].marker != "•" || lines[1].marker != "" {
+		t.Errorf("marker should only be on the first line of the item")
+	}
+	for _, l := range lines {
+		last := l.runs[len(l.runs)-1]
+		if l.indent <= 0 || l.indent+last.x+last.w > maxW+0.01 {
+			t.Errorf("line %+v exceeds width or is not indented", l)
+		}
+	}
+}
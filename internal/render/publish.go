@@ -0,0 +1,142 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"log"
+	"strings"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+)
+
+const originQuality = 95 // 原图需要重新编码时的 JPEG 质量
+
+// MaxPublishImages QQ 空间一条说说最多附带的图片数
+const MaxPublishImages = 9
+
+// RenderForPublish 生成发布用的图片：截图在前；带内容警告的稿件在其后附上清晰原图，
+// 开启 attach_gif 时附上动图原文件 (截图里只显示第一帧)。总数不超过 MaxPublishImages
+func (r *Renderer) RenderForPublish(post *model.Post) ([][]byte, error) {
+	cover, err := r.RenderPost(post)
+	if err != nil {
+		return nil, err
+	}
+	out := [][]byte{cover}
+	switch {
+	case post.Warning:
+		out = append(out, r.Originals(post)...)
+	case r.attachGIF:
+		out = append(out, r.AnimatedGIFs(post)...)
+	}
+	if len(out) > MaxPublishImages {
+		log.Printf("[Renderer] 稿件 #%d 共 %d 张图片，超出说说上限，只发布前 %d 张", post.ID, len(out), MaxPublishImages)
+		out = out[:MaxPublishImages]
+	}
+	return out, nil
+}
+
+// PublishBatch 合并发布时的一条说说
+type PublishBatch struct {
+	Posts  []*model.Post
+	Images [][]byte
+}
+
+// SplitPublish 合并发布时按顺序将稿件分成若干条说说，每条的图片不超过 MaxPublishImages。
+// rendered 为各稿件 RenderForPublish 的结果，没有图片的稿件跳过
+func SplitPublish(posts []*model.Post, rendered map[int64][][]byte) []PublishBatch {
+	var batches []PublishBatch
+	for _, p := range posts {
+		imgs := rendered[p.ID]
+		if len(imgs) == 0 {
+			continue
+		}
+		n := len(batches)
+		if n == 0 || len(batches[n-1].Images)+len(imgs) > MaxPublishImages {
+			batches = append(batches, PublishBatch{})
+			n++
+		}
+		batches[n-1].Posts = append(batches[n-1].Posts, p)
+		batches[n-1].Images = append(batches[n-1].Images, imgs...)
+	}
+	return batches
+}
+
+// Text 说说正文：日期和每篇稿件打码后的摘要；分成 total 条发布时标注这是第 i 条 (从 1 开始)
+func (b PublishBatch) Text(date string, i, total int) string {
+	var sb strings.Builder
+	sb.WriteString("【表白墙更新】 " + date)
+	if total > 1 {
+		fmt.Fprintf(&sb, " (%d/%d)", i, total)
+	}
+	sb.WriteString("\n----------------\n")
+	for _, p := range b.Posts {
+		sb.WriteString(p.PublishLine() + "\n")
+	}
+	sb.WriteString("----------------\n")
+	sb.WriteString("详情见图 👇")
+	return sb.String()
+}
+
+// Originals 返回稿件的原图，加载失败的图片跳过
+func (r *Renderer) Originals(post *model.Post) [][]byte {
+	var out [][]byte
+	for _, url := range post.Images {
+		data, err := r.original(url)
+		if err != nil {
+			log.Printf("[Renderer] 读取原图失败: %v | url: %s", err, url)
+			continue
+		}
+		out = append(out, data)
+	}
+	return out
+}
+
+// AnimatedGIFs 返回稿件中动图的原始数据
+func (r *Renderer) AnimatedGIFs(post *model.Post) [][]byte {
+	src, ok := r.images.(RawImageSource)
+	if !ok {
+		return nil
+	}
+	var out [][]byte
+	for _, url := range post.Images {
+		data, err := src.LoadRaw(url)
+		if err == nil && isAnimatedGIF(data) {
+			out = append(out, data)
+		}
+	}
+	return out
+}
+
+// original 能原样发布的格式 (PNG、GIF、方向正常的 JPEG) 直接使用原始数据，
+// 其余格式解码摆正后重新编码为 JPEG
+func (r *Renderer) original(url string) ([]byte, error) {
+	if r.images == nil {
+		return nil, fmt.Errorf("no image source")
+	}
+	src, ok := r.images.(RawImageSource)
+	if !ok {
+		img, err := r.images.Load(url)
+		if err != nil {
+			return nil, err
+		}
+		return encodeOnce(img, FormatJPEG, originQuality)
+	}
+
+	data, err := src.LoadRaw(url)
+	if err != nil {
+		return nil, err
+	}
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image config: %w", err)
+	}
+	if format == "png" || format == "gif" || format == "jpeg" && jpegOrientation(data) == 1 {
+		return data, nil
+	}
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, err
+	}
+	return encodeOnce(img, FormatJPEG, originQuality)
+}
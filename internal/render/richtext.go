@@ -0,0 +1,145 @@
+package render
+
+import (
+	"image"
+	"math"
+
+	"github.com/fogleman/gg"
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	"github.com/guohuiyuan/qzonewall-go/internal/render/faces"
+	"golang.org/x/image/font"
+)
+
+// inlineStyle 行内样式，可组合
+type inlineStyle uint8
+
+const (
+	styleMention inlineStyle = 1 << iota // @ 提及
+	styleStrong                          // **粗体**
+	styleEm                              // *强调*
+)
+
+// inlineRun 一行中样式相同的一段内容 (文字或单个表情)
+type inlineRun struct {
+	text  string
+	style inlineStyle
+	face  image.Image // 非 nil 时为表情
+	x, w  float64     // 相对行首的位置与宽度
+}
+
+// styledSeg 带行内样式的消息段
+type styledSeg struct {
+	model.Segment
+	style inlineStyle
+}
+
+// contentSegments 返回气泡内要绘制的消息段 (不含回复引用)；
+// 没有结构化消息段的旧稿件退化为纯文本
+func contentSegments(post *model.Post) []model.Segment {
+	if len(post.Segments) == 0 {
+		if post.Text == "" {
+			return nil
+		}
+		return []model.Segment{{Type: model.SegText, Text: post.Text}}
+	}
+	segs := make([]model.Segment, 0, len(post.Segments))
+	for _, seg := range post.Segments {
+		if seg.Type != model.SegReply {
+			segs = append(segs, seg)
+		}
+	}
+	return segs
+}
+
+// layoutSegments 按最大宽度排版消息段 (断行规则见 linebreak.go)，
+// 保留原有换行符，表情按字号大小占位
+func layoutSegments(face font.Face, segs []model.Segment, maxWidth, faceSize float64, justify bool) [][]inlineRun {
+	styled := make([]styledSeg, len(segs))
+	for i, seg := range segs {
+		styled[i] = styledSeg{Segment: seg}
+	}
+	return breakItems(face, segmentItems(face, styled, faceSize), maxWidth, justify)
+}
+
+// segmentItems 将带样式的消息段拆为排版单位
+func segmentItems(face font.Face, segs []styledSeg, faceSize float64) []inlineItem {
+	var items []inlineItem
+	for _, seg := range segs {
+		switch seg.Type {
+		case model.SegFace:
+			if img := faces.Image(seg.ID); img != nil {
+				items = append(items, inlineItem{face: img, w: faceSize})
+			} else {
+				items = append(items, itemsOf(face, "["+seg.Name+"]", seg.style)...)
+			}
+		case model.SegAt:
+			items = append(items, itemsOf(face, "@"+seg.Text, seg.style|styleMention)...)
+		case model.SegReply:
+			// 回复引用单独绘制
+		default:
+			items = append(items, itemsOf(face, seg.Text, seg.style)...)
+		}
+	}
+	return items
+}
+
+// drawInlineLine 绘制一行，baseline 为文字基线
+func drawInlineLine(dc *gg.Context, line []inlineRun, theme Theme, x, baseline, ascent, descent float64, faceCache map[image.Image]image.Image) {
+	for _, run := range line {
+		if run.face != nil {
+			size := int(run.w)
+			scaled, ok := faceCache[run.face]
+			if !ok {
+				scaled = resizeImage(run.face, size, size)
+				faceCache[run.face] = scaled
+			}
+			// 表情与文字行的中心对齐
+			top := baseline + (descent-ascent)/2 - run.w/2
+			dc.DrawImage(scaled, int(x+run.x), int(top))
+			continue
+		}
+		if run.text == "" {
+			continue
+		}
+		switch {
+		case run.style&styleMention != 0:
+			dc.SetHexColor(theme.MentionColor)
+		case run.style&styleStrong != 0:
+			dc.SetHexColor(theme.StrongColor)
+		case run.style&styleEm != 0:
+			dc.SetHexColor(theme.EmphasisColor)
+		default:
+			dc.SetHexColor(theme.TextColor)
+		}
+		drawStyledString(dc, run, x+run.x, baseline, ascent)
+	}
+}
+
+// drawStyledString 绘制一段文字：只有一种字重，粗体错位重绘，强调用斜切模拟斜体
+func drawStyledString(dc *gg.Context, run inlineRun, x, baseline, ascent float64) {
+	if run.style&styleEm != 0 {
+		dc.Push()
+		defer dc.Pop()
+		dc.ShearAbout(-emShear, 0, x, baseline)
+	}
+	dc.DrawString(run.text, x, baseline)
+	if run.style&styleStrong != 0 {
+		dc.DrawString(run.text, x+math.Max(1, ascent*boldOffset), baseline)
+	}
+}
+
+// truncateToWidth 截断文字使其不超过最大宽度，超出部分用省略号表示
+func truncateToWidth(dc *gg.Context, text string, maxWidth float64) string {
+	if w, _ := dc.MeasureString(text); w <= maxWidth {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		s := string(runes) + "…"
+		if w, _ := dc.MeasureString(s); w <= maxWidth {
+			return s
+		}
+	}
+	return "…"
+}
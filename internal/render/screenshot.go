@@ -1,18 +1,12 @@
 package render
 
 import (
-	"bytes"
 	_ "embed"
 	"fmt"
 	"image"
-	"image/color"
 	"image/draw" // 标准库
-	"image/jpeg"
 	"log"
 	"math"
-	"net/http"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -21,23 +15,37 @@ import (
 	"github.com/guohuiyuan/qzonewall-go/internal/model"
 	xdraw "golang.org/x/image/draw" // 扩展库
 	"golang.org/x/image/font"
-	_ "golang.org/x/image/webp" // 【新增】引入此包以支持 image.Decode 解析 WebP 图片
 )
 
 //go:embed font.ttf
 var fontData []byte
 
 type Renderer struct {
-	font *truetype.Font
+	font   *truetype.Font
+	images ImageSource
+	themes *themeSet
+	now    func() time.Time // 水印时间，测试时可固定
+
+	attachGIF bool // 发布时附带动图原文件
 }
 
 func NewRenderer() *Renderer {
+	r := &Renderer{images: NewHTTPImageSource(), now: time.Now}
 	f, err := truetype.Parse(fontData)
 	if err != nil {
 		log.Printf("[Renderer] ❌ 严重错误: 内置字体解析失败: %v", err)
-		return &Renderer{font: nil}
+		return r
 	}
-	return &Renderer{font: f}
+	r.font = f
+	return r
+}
+
+// SetImageSource 替换图片加载源 (nil 恢复默认的 HTTP/本地文件加载)
+func (r *Renderer) SetImageSource(src ImageSource) {
+	if src == nil {
+		src = NewHTTPImageSource()
+	}
+	r.images = src
 }
 
 func (r *Renderer) Available() bool {
@@ -55,30 +63,50 @@ func (r *Renderer) getFace(size float64) font.Face {
 	})
 }
 
-// RenderPost 渲染图文合一
+// RenderPost 渲染图文合一 (使用当前默认主题)
 func (r *Renderer) RenderPost(post *model.Post) ([]byte, error) {
+	return r.RenderPostWithTheme(post, r.currentTheme())
+}
+
+// RenderPostWithTheme 使用指定主题渲染，输出格式/倍率取自 theme.Output
+func (r *Renderer) RenderPostWithTheme(post *model.Post, theme Theme) ([]byte, error) {
 	if !r.Available() {
 		return nil, fmt.Errorf("渲染器未初始化(字体缺失)")
 	}
+	out := theme.Output.normalized()
+	return encodeImage(r.drawPost(post.Masked(), theme, out.Scale), out)
+}
+
+// drawPost 绘制稿件截图，k 为 HiDPI 倍率 (所有尺寸按 k 放大，保证文字清晰)
+func (r *Renderer) drawPost(post *model.Post, theme Theme, k float64) image.Image {
+	if post.IsChat() {
+		return r.drawChat(post, theme, k)
+	}
 
 	// ── 1. 样式配置 ──
-	const (
-		CanvasWidth = 800.0
-		Padding     = 40.0
-		SizeText    = 32.0
-		SizeName    = 28.0
-		SizeMeta    = 22.0
-		AvatarSize  = 90.0
-		AvatarRight = 20.0
-		BubblePadH  = 30.0
-		BubblePadV  = 25.0
-		LineHeight  = 1.4
-		ImgGap      = 10.0
-		ImgSizeMax  = 220.0 // 九宫格单图最大尺寸
+	var (
+		CanvasWidth = 800.0 * k
+		Padding     = 40.0 * k
+		SizeText    = 32.0 * k
+		SizeName    = 28.0 * k
+		SizeMeta    = 22.0 * k
+		AvatarSize  = 90.0 * k
+		AvatarRight = 20.0 * k
+		BubblePadH  = 30.0 * k
+		BubblePadV  = 25.0 * k
+		ImgGap      = 10.0 * k
+		ImgSizeMax  = 220.0 * k // 九宫格单图最大尺寸
+		BlockGap    = 20.0 * k  // 气泡与图片区的间距
+		BottomSpace = 50.0 * k  // 底部水印区域
+		QuotePadH   = 20.0 * k  // 回复引用块内边距
+		QuotePadV   = 12.0 * k
+		QuoteGap    = 12.0 * k // 引用块与气泡的间距
 	)
+	const LineHeight = 1.4
 
 	// ── 2. 计算布局 ──
-	hasAvatar := !post.Anon
+	// 匿名稿件有匿名标识时显示对应的方块头像
+	hasAvatar := !post.Anon || post.AnonKey != ""
 	contentMaxW := CanvasWidth - (Padding * 2)
 	if hasAvatar {
 		contentMaxW -= AvatarSize + AvatarRight
@@ -88,19 +116,25 @@ func (r *Renderer) RenderPost(post *model.Post) ([]byte, error) {
 	textFace := r.getFace(SizeText)
 	measureDc.SetFontFace(textFace)
 
-	var lines []string
-	if post.Text != "" {
-		// 使用自定义的 WordWrap，传入 measureDc 以获取当前字体大小
-		lines = WordWrap(measureDc, post.Text, contentMaxW-(BubblePadH*2))
+	var lines []textLine
+	fontH := measureDc.FontHeight()
+	if segs := contentSegments(post); len(segs) > 0 {
+		lines = layoutMarkup(textFace, segs, contentMaxW-(BubblePadH*2), fontH, theme.Justify)
 	}
 
-	fontH := measureDc.FontHeight()
 	bubbleH := 0.0
 	if len(lines) > 0 {
 		textBlockH := float64(len(lines)) * fontH * LineHeight
 		bubbleH = textBlockH + (BubblePadV * 2)
 	}
 
+	// 回复引用块
+	reply := post.Reply()
+	replyH := 0.0
+	if reply != nil {
+		replyH = SizeMeta*LineHeight + QuotePadV*2
+	}
+
 	imgAreaH := 0.0
 	imgCount := len(post.Images)
 	var imgCols, imgRows int
@@ -109,7 +143,7 @@ func (r *Renderer) RenderPost(post *model.Post) ([]byte, error) {
 	if imgCount > 0 {
 		if imgCount == 1 {
 			// 单图模式
-			imgAreaH = 500.0
+			imgAreaH = 500.0 * k
 		} else {
 			// 九宫格模式
 			imgCols = 3
@@ -132,19 +166,22 @@ func (r *Renderer) RenderPost(post *model.Post) ([]byte, error) {
 	}
 
 	currentY := Padding
-	currentY += SizeName + 15
+	currentY += SizeName + 15*k
 	contentStartY := currentY
 
+	if replyH > 0 {
+		currentY += replyH + QuoteGap
+	}
 	if bubbleH > 0 {
 		currentY += bubbleH
 	}
 	if imgAreaH > 0 {
 		if bubbleH > 0 {
-			currentY += 20.0
+			currentY += BlockGap
 		}
 		currentY += imgAreaH
 	}
-	currentY += 50.0
+	currentY += BottomSpace
 
 	totalH := int(currentY)
 	minH := Padding + Padding
@@ -157,7 +194,7 @@ func (r *Renderer) RenderPost(post *model.Post) ([]byte, error) {
 
 	// ── 3. 开始绘制 ──
 	dc := gg.NewContext(int(CanvasWidth), totalH)
-	dc.SetHexColor("#F5F5F5")
+	dc.SetHexColor(theme.Background)
 	dc.Clear()
 
 	startX := Padding
@@ -166,14 +203,19 @@ func (r *Renderer) RenderPost(post *model.Post) ([]byte, error) {
 	// 3.1 绘制头像
 	contentX := startX
 	if hasAvatar {
-		avatarImg := downloadAndCrop(post.QQAvatarURL(), int(AvatarSize))
+		var avatarImg image.Image
+		if post.Anon {
+			avatarImg = identicon(post.AnonKey, int(AvatarSize))
+		} else {
+			avatarImg = r.loadAndCrop(post.QQAvatarURL(), int(AvatarSize))
+		}
 		dc.Push()
 		dc.DrawCircle(startX+AvatarSize/2, startY+AvatarSize/2, AvatarSize/2)
 		dc.Clip()
 		if avatarImg != nil {
 			dc.DrawImageAnchored(avatarImg, int(startX+AvatarSize/2), int(startY+AvatarSize/2), 0.5, 0.5)
 		} else {
-			dc.SetHexColor("#DCDCDC")
+			dc.SetHexColor(theme.Placeholder)
 			dc.DrawRectangle(startX, startY, AvatarSize, AvatarSize)
 			dc.Fill()
 		}
@@ -184,73 +226,100 @@ func (r *Renderer) RenderPost(post *model.Post) ([]byte, error) {
 
 	// 3.2 绘制昵称
 	dc.SetFontFace(r.getFace(SizeName))
-	dc.SetHexColor("#555555")
-	dc.DrawString(post.ShowName(), contentX, startY+SizeName-5)
+	dc.SetHexColor(theme.NameColor)
+	dc.DrawString(post.ShowName(), contentX, startY+SizeName-5*k)
 
 	currContentY := contentStartY
 
-	// 3.3 绘制文字气泡
+	// 3.3 绘制回复引用
+	if replyH > 0 {
+		dc.SetHexColor(theme.QuoteBackground)
+		dc.DrawRoundedRectangle(contentX, currContentY, contentMaxW, replyH, 10*k)
+		dc.Fill()
+		dc.SetHexColor(theme.QuoteBar)
+		dc.DrawRectangle(contentX, currContentY, 6*k, replyH)
+		dc.Fill()
+
+		dc.SetFontFace(r.getFace(SizeMeta))
+		dc.SetHexColor(theme.NameColor)
+		quote := reply.Text
+		if reply.Name != "" {
+			quote = reply.Name + ": " + quote
+		}
+		quote = strings.ReplaceAll(quote, "\n", " ")
+		quote = truncateToWidth(dc, quote, contentMaxW-QuotePadH*2)
+		dc.DrawStringAnchored(quote, contentX+QuotePadH, currContentY+replyH/2, 0, 0.35)
+		currContentY += replyH + QuoteGap
+	}
+
+	// 3.4 绘制文字气泡
 	if bubbleH > 0 {
-		dc.SetColor(color.White)
-		dc.DrawRoundedRectangle(contentX, currContentY, contentMaxW, bubbleH, 16)
+		dc.SetHexColor(theme.BubbleColor)
+		dc.DrawRoundedRectangle(contentX, currContentY, contentMaxW, bubbleH, 16*k)
 		dc.Fill()
 
 		// 小三角
-		dc.MoveTo(contentX, currContentY+25)
-		dc.LineTo(contentX-10, currContentY+35)
-		dc.LineTo(contentX, currContentY+45)
+		dc.MoveTo(contentX, currContentY+25*k)
+		dc.LineTo(contentX-10*k, currContentY+35*k)
+		dc.LineTo(contentX, currContentY+45*k)
 		dc.ClosePath()
 		dc.Fill()
 
 		// 文字
 		dc.SetFontFace(textFace)
-		dc.SetHexColor("#000000")
 
 		metrics := textFace.Metrics()
 		ascent := float64(metrics.Ascent.Ceil())
+		descent := float64(metrics.Descent.Ceil())
 
 		textY := currContentY + BubblePadV + ascent
-		for i, line := range lines {
-			dc.DrawString(line, contentX+BubblePadH, textY+float64(i)*fontH*LineHeight)
-		}
-		currContentY += bubbleH + 20.0
+		faceCache := make(map[image.Image]image.Image)
+		drawTextLines(dc, lines, theme, contentX+BubblePadH, textY, fontH*LineHeight, contentMaxW-BubblePadH*2, ascent, descent, faceCache)
+		currContentY += bubbleH + BlockGap
 	}
 
-	// 3.4 绘制图片
+	// 3.5 绘制图片 (内容警告稿件模糊处理并加遮罩)
+	warnLabel := post.WarningLabel()
 	if imgCount > 0 {
 		if imgCount == 1 {
 			// ── 单图模式 (Aspect Fit) ──
-			rawImg := downloadImage(post.Images[0])
+			rawImg := r.loadImage(post.Images[0])
 			if rawImg != nil {
 				b := rawImg.Bounds()
 				origW, origH := float64(b.Dx()), float64(b.Dy())
 
-				const BaseMaxW = 400.0
+				BaseMaxW := 400.0 * k
 				// 确保单图也不超出内容区域
 				maxW := BaseMaxW
 				if maxW > contentMaxW {
 					maxW = contentMaxW
 				}
-				const MaxH = 500.0
+				MaxH := 500.0 * k
 
 				scale := math.Min(maxW/origW, MaxH/origH)
-				if scale > 1.0 {
-					scale = 1.0
+				if scale > k {
+					scale = k
 				}
 
 				targetW := int(origW * scale)
 				targetH := int(origH * scale)
 
 				finalImg := resizeImage(rawImg, targetW, targetH)
+				if warnLabel != "" {
+					finalImg = blurImage(finalImg)
+				}
 
 				dc.Push()
-				dc.DrawRoundedRectangle(contentX, currContentY, float64(targetW), float64(targetH), 12)
+				dc.DrawRoundedRectangle(contentX, currContentY, float64(targetW), float64(targetH), 12*k)
 				dc.Clip()
 				dc.DrawImage(finalImg, int(contentX), int(currContentY))
+				if warnLabel != "" {
+					r.drawWarningOverlay(dc, warnLabel, contentX, currContentY, float64(targetW), float64(targetH), k)
+				}
 				dc.Pop()
 				dc.ResetClip()
 			} else {
-				drawErrorPlaceholder(dc, contentX, currContentY, 200, 200)
+				drawErrorPlaceholder(dc, theme, contentX, currContentY, 200*k, 200*k)
 			}
 		} else {
 			// ── 九宫格模式 (Aspect Fill) ──
@@ -265,26 +334,32 @@ func (r *Renderer) RenderPost(post *model.Post) ([]byte, error) {
 				ix := contentX + float64(col)*(gridItemSize+ImgGap)
 				iy := currContentY + float64(row)*(gridItemSize+ImgGap)
 
-				img := downloadAndCrop(imgUrl, int(gridItemSize))
+				img := r.loadAndCrop(imgUrl, int(gridItemSize))
 				if img != nil {
+					if warnLabel != "" {
+						img = blurImage(img)
+					}
 					dc.Push()
-					dc.DrawRoundedRectangle(ix, iy, gridItemSize, gridItemSize, 8)
+					dc.DrawRoundedRectangle(ix, iy, gridItemSize, gridItemSize, 8*k)
 					dc.Clip()
 					dc.DrawImage(img, int(ix), int(iy))
+					if warnLabel != "" {
+						r.drawWarningOverlay(dc, warnLabel, ix, iy, gridItemSize, gridItemSize, k)
+					}
 					dc.Pop()
 					dc.ResetClip()
 				} else {
-					drawErrorPlaceholder(dc, ix, iy, gridItemSize, gridItemSize)
+					drawErrorPlaceholder(dc, theme, ix, iy, gridItemSize, gridItemSize)
 				}
 			}
 		}
 	}
 
-	// 3.5 水印
+	// 3.6 水印
 	wmFace := r.getFace(SizeMeta)
 	dc.SetFontFace(wmFace)
-	dc.SetHexColor("#AAAAAA")
-	wmText := fmt.Sprintf("#%d  %s", post.ID, time.Now().Format("2006-01-02 15:04"))
+	dc.SetHexColor(theme.MetaColor)
+	wmText := fmt.Sprintf("#%d  %s", post.ID, r.now().Format("2006-01-02 15:04"))
 	wmW, _ := dc.MeasureString(wmText)
 	descent := float64(wmFace.Metrics().Descent.Ceil())
 
@@ -292,71 +367,38 @@ func (r *Renderer) RenderPost(post *model.Post) ([]byte, error) {
 	if wmX < Padding {
 		wmX = Padding
 	}
-	wmY := float64(totalH) - 8 - descent
+	wmY := float64(totalH) - 8*k - descent
 	dc.DrawString(wmText, wmX, wmY)
 
-	var buf bytes.Buffer
-	if err := jpeg.Encode(&buf, dc.Image(), &jpeg.Options{Quality: 90}); err != nil {
-		return nil, err
-	}
-	return buf.Bytes(), nil
+	return dc.Image()
 }
 
 // ─── 辅助函数 ───
 
-func drawErrorPlaceholder(dc *gg.Context, x, y, w, h float64) {
+func drawErrorPlaceholder(dc *gg.Context, theme Theme, x, y, w, h float64) {
 	dc.Push()
-	dc.SetHexColor("#E0E0E0")
+	dc.SetHexColor(theme.Placeholder)
 	dc.DrawRectangle(x, y, w, h)
 	dc.Fill()
-	dc.SetHexColor("#999999")
+	dc.SetHexColor(theme.PlaceholderText)
 	dc.DrawStringAnchored("加载失败", x+w/2, y+h/2, 0.5, 0.5)
 	dc.Pop()
 }
 
-func downloadImage(url string) image.Image {
-	if url == "" {
+func (r *Renderer) loadImage(url string) image.Image {
+	if url == "" || r.images == nil {
 		return nil
 	}
-	if local := resolveLocalUploadPath(url); local != "" {
-		f, err := os.Open(local)
-		if err != nil {
-			log.Printf("加载本地图片失败: %v | path: %s", err, local)
-			return nil
-		}
-		defer func() { _ = f.Close() }()
-		img, _, err := image.Decode(f)
-		if err != nil {
-			return nil
-		}
-		return img
-	}
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil
-	}
-	req.Header.Set("User-Agent", "Mozilla/5.0")
-
-	client := &http.Client{Timeout: 8 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != 200 {
-		return nil
-	}
-	img, _, err := image.Decode(resp.Body)
+	img, err := r.images.Load(url)
 	if err != nil {
+		log.Printf("[Renderer] 加载图片失败: %v | url: %s", err, url)
 		return nil
 	}
 	return img
 }
 
-func downloadAndCrop(url string, size int) image.Image {
-	src := downloadImage(url)
+func (r *Renderer) loadAndCrop(url string, size int) image.Image {
+	src := r.loadImage(url)
 	if src == nil {
 		return nil
 	}
@@ -395,60 +437,20 @@ func cropToSquare(src image.Image, size int) image.Image {
 	return dst
 }
 
-// WordWrap 自定义换行函数，支持中文
-func WordWrap(dc *gg.Context, text string, maxWidth float64) []string {
+// WordWrap 按断行规则拆分文字 (单词不拆开、避头尾、长串强制断开)，制表符展开为空格
+func WordWrap(face font.Face, text string, maxWidth float64) []string {
+	spaceW := measure(face, " ")
 	var lines []string
-
-	// 先按原有的换行符拆分段落
-	paragraphs := strings.Split(text, "\n")
-
-	for _, p := range paragraphs {
-		var line string
-		for _, r := range p {
-			// 预测加上当前字符后的宽度
-			s := string(r)
-			w, _ := dc.MeasureString(line + s)
-
-			if w > maxWidth {
-				// 如果超宽，先保存当前行，开启新行
-				lines = append(lines, line)
-				line = s
-			} else {
-				// 未超宽，追加字符
-				line += s
+	for _, line := range breakItems(face, itemsOf(face, text, 0), maxWidth, false) {
+		var b strings.Builder
+		for _, run := range line {
+			if run.text == "" && spaceW > 0 {
+				b.WriteString(strings.Repeat(" ", int(math.Round(run.w/spaceW))))
+				continue
 			}
+			b.WriteString(run.text)
 		}
-		// 保存段落的最后一行
-		lines = append(lines, line)
+		lines = append(lines, b.String())
 	}
 	return lines
 }
-
-func resolveLocalUploadPath(raw string) string {
-	// 1. 如果是 http/https 网络链接，直接返回空，交给后续的 http 下载逻辑处理
-	if strings.HasPrefix(raw, "http://") || strings.HasPrefix(raw, "https://") {
-		return ""
-	}
-
-	// 2. 清理路径格式 (处理 Windows/Linux 分隔符差异)
-	path := filepath.Clean(raw)
-
-	// 3. 核心逻辑：直接检查文件是否存在
-	// server.go 传递过来的是绝对路径，os.Stat 能直接找到它
-	info, err := os.Stat(path)
-	if err == nil && !info.IsDir() {
-		// 文件存在且不是文件夹，返回该路径供 os.Open 使用
-		return path
-	}
-
-	// 4. (保底逻辑) 如果传进来的是相对路径，尝试拼接当前运行目录下的 uploads
-	// 这一步通常用不到，因为 server.go 已经转成绝对路径了，但留着防守
-	wd, _ := os.Getwd()
-	absPath := filepath.Join(wd, path)
-	info, err = os.Stat(absPath)
-	if err == nil && !info.IsDir() {
-		return absPath
-	}
-
-	return ""
-}
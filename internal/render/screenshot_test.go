@@ -1,7 +1,12 @@
 package render
 
 import (
+	"bytes"
+	"fmt"
+	"image/jpeg"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -19,20 +24,20 @@ func TestRenderPost(t *testing.T) {
 		t.Fatal("❌ 渲染器不可用，请检查 font.ttf 是否正确嵌入")
 	}
 
-	// 2. 构造模拟投稿数据
-	// ★★★ 修改点：使用 QQ 头像作为图片源，保证下载成功 ★★★
-	stableImgURL := "https://q1.qlogo.cn/g?b=qq&nk=10001&s=640"
+	// 2. 使用内存图片源，测试不依赖网络
+	r.SetImageSource(testImages())
 
 	post := &model.Post{
-		ID:      10086,
-		UIN:     10001,
-		Name:    "测试用户(Test)",
-		GroupID: 123456,
+		ID:        10086,
+		UIN:       10001,
+		Name:      "测试用户(Test)",
+		GroupID:   123456,
+		AvatarURL: "mem://avatar",
 		// 测试 Emoji (注意：需使用微软雅黑等支持Emoji的字体，且显示为黑白)
-		Text: "这是一条测试内容。\nHello World! 👋\nEmoji测试：🚀 😄 🐛\n下面应该是两张一模一样的头像图片 👇",
+		Text: "这是一条测试内容。\nHello World! 👋\nEmoji测试：🚀 😄 🐛\n下面应该是两张图片 👇",
 		Images: []string{
-			stableImgURL, // 图1：头像
-			stableImgURL, // 图2：头像
+			"mem://square",
+			"mem://tall",
 		},
 		Anon:       false,
 		Status:     model.StatusPending,
@@ -51,19 +56,92 @@ func TestRenderPost(t *testing.T) {
 		t.Fatalf("❌ 渲染失败: %v", err)
 	}
 
-	if len(data) == 0 {
-		t.Fatal("❌ 渲染结果为空 (0 bytes)")
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("❌ 渲染结果不是有效的 JPEG: %v", err)
+	}
+	if w := img.Bounds().Dx(); w != 800 {
+		t.Errorf("❌ 画布宽度 = %d, 期望 800", w)
 	}
 
-	// 5. 保存图片到本地
-	outputFile := "test_render_result.jpg"
-	err = os.WriteFile(outputFile, data, 0644)
-	if err != nil {
+	// 5. 保存图片到临时目录，便于 -v 时人工查看
+	outputFile := filepath.Join(t.TempDir(), "test_render_result.jpg")
+	if err := os.WriteFile(outputFile, data, 0644); err != nil {
 		t.Fatalf("❌ 保存测试图片失败: %v", err)
 	}
 
 	t.Logf("✅ 渲染成功！")
 	t.Logf("⏱️ 耗时: %v", duration)
-	t.Logf("📂 图片已保存为: %s/%s", "internal/render", outputFile)
-	t.Logf("👉 请务必使用「微软雅黑」作为 font.ttf 以支持 Emoji 显示。")
+	t.Logf("📂 图片已保存为: %s", outputFile)
+}
+
+// TestRenderForPublish 内容警告稿件在截图后附带清晰原图
+func TestRenderForPublish(t *testing.T) {
+	r := NewRenderer()
+	if !r.Available() {
+		t.Skip("font.ttf 不可用")
+	}
+	r.SetImageSource(testImages())
+
+	post := &model.Post{ID: 1, Text: "cw", Images: []string{"mem://square", "mem://missing", "mem://wide"}}
+	out, err := r.RenderForPublish(post)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("unflagged post: got %d images, want 1", len(out))
+	}
+
+	post.Warning = true
+	out, err = r.RenderForPublish(post)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	// 截图 + 两张可加载的原图
+	if len(out) != 3 {
+		t.Fatalf("flagged post: got %d images, want 3", len(out))
+	}
+	orig, err := jpeg.Decode(bytes.NewReader(out[1]))
+	if err != nil {
+		t.Fatalf("decode original: %v", err)
+	}
+	if orig.Bounds().Dx() != 400 {
+		t.Errorf("original width = %d, want 400 (not resized)", orig.Bounds().Dx())
+	}
+}
+
+// TestSplitPublish 合并发布时图片超出说说上限拆成多条
+func TestSplitPublish(t *testing.T) {
+	imgs := func(n int) [][]byte { return make([][]byte, n) }
+	posts := []*model.Post{{ID: 1, Text: "a"}, {ID: 2}, {ID: 3, Text: "c"}, {ID: 4}, {ID: 5, Text: "e"}}
+	rendered := map[int64][][]byte{
+		1: imgs(1),
+		2: imgs(7),
+		3: imgs(2), // 1+7+2 > 9，另起一条
+		// 4 渲染失败
+		5: imgs(MaxPublishImages),
+	}
+	batches := SplitPublish(posts, rendered)
+	if len(batches) != 3 {
+		t.Fatalf("got %d batches, want 3", len(batches))
+	}
+	want := [][]int64{{1, 2}, {3}, {5}}
+	for i, b := range batches {
+		var ids []int64
+		for _, p := range b.Posts {
+			ids = append(ids, p.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(want[i]) {
+			t.Errorf("batch %d posts = %v, want %v", i, ids, want[i])
+		}
+		if len(b.Images) > MaxPublishImages {
+			t.Errorf("batch %d has %d images", i, len(b.Images))
+		}
+	}
+	if text := batches[1].Text("01/02", 2, 3); !strings.HasPrefix(text, "【表白墙更新】 01/02 (2/3)\n") || !strings.Contains(text, "#3: c\n") {
+		t.Errorf("text = %q", text)
+	}
+	if text := batches[0].Text("01/02", 1, 1); strings.Contains(text, "(1/1)") {
+		t.Errorf("single batch text should not be numbered: %q", text)
+	}
 }
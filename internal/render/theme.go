@@ -0,0 +1,186 @@
+package render
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/config"
+)
+
+// Theme 渲染主题：配色与输出参数
+type Theme struct {
+	Name            string
+	Background      string
+	NameColor       string
+	BubbleColor     string
+	TextColor       string
+	MetaColor       string
+	Placeholder     string // 图片加载失败占位底色
+	PlaceholderText string
+	MentionColor    string // @ 提及高亮
+	QuoteBackground string // 回复引用块底色
+	QuoteBar        string // 回复引用块左侧竖条
+	SelfBubbleColor string // 聊天记录中投稿者一方的气泡
+	SelfTextColor   string
+	StrongColor     string // **粗体**
+	EmphasisColor   string // *强调*
+	QuoteTextColor  string // "> " 引用块文字
+	BulletColor     string // 列表项目符号
+	RuleColor       string // 分隔线
+	Justify         bool   // 文字两端对齐
+	Output          OutputOptions
+}
+
+// DefaultThemeName 默认主题名
+const DefaultThemeName = "default"
+
+var builtinThemes = map[string]Theme{
+	"default": {
+		Name:            "default",
+		Background:      "#F5F5F5",
+		NameColor:       "#555555",
+		BubbleColor:     "#FFFFFF",
+		TextColor:       "#000000",
+		MetaColor:       "#AAAAAA",
+		Placeholder:     "#E0E0E0",
+		PlaceholderText: "#999999",
+		MentionColor:    "#1E6FD9",
+		QuoteBackground: "#EAEAEA",
+		QuoteBar:        "#C8C8C8",
+		SelfBubbleColor: "#95EC69",
+		SelfTextColor:   "#000000",
+		StrongColor:     "#000000",
+		EmphasisColor:   "#C2185B",
+		QuoteTextColor:  "#666666",
+		BulletColor:     "#999999",
+		RuleColor:       "#DDDDDD",
+		Output:          DefaultOutput,
+	},
+	"dark": {
+		Name:            "dark",
+		Background:      "#1E1E1E",
+		NameColor:       "#B0B0B0",
+		BubbleColor:     "#2D2D2D",
+		TextColor:       "#EDEDED",
+		MetaColor:       "#777777",
+		Placeholder:     "#3A3A3A",
+		PlaceholderText: "#888888",
+		MentionColor:    "#5AA9FF",
+		QuoteBackground: "#262626",
+		QuoteBar:        "#555555",
+		SelfBubbleColor: "#3B6E2A",
+		SelfTextColor:   "#EDEDED",
+		StrongColor:     "#FFFFFF",
+		EmphasisColor:   "#F48FB1",
+		QuoteTextColor:  "#A0A0A0",
+		BulletColor:     "#888888",
+		RuleColor:       "#444444",
+		Output:          DefaultOutput,
+	},
+}
+
+// themeSet 渲染器持有的主题表 (内置主题 + 配置覆盖)，构建后只读
+type themeSet struct {
+	themes   map[string]Theme
+	selected string
+}
+
+func newThemeSet() *themeSet {
+	ts := &themeSet{themes: make(map[string]Theme, len(builtinThemes)), selected: DefaultThemeName}
+	for name, t := range builtinThemes {
+		ts.themes[name] = t
+	}
+	return ts
+}
+
+// ApplyConfig 按墙配置设置默认主题和输出参数，应在开始渲染前调用。
+// 覆盖顺序: 主题内置值 < wall.render.output < wall.render.themes.<name>.output。
+// 主题名或输出格式无法识别时返回错误，不修改当前配置
+func (r *Renderer) ApplyConfig(cfg config.RenderConfig) error {
+	ts := newThemeSet()
+	if _, ok := ts.themes[cfg.Theme]; cfg.Theme != "" && !ok {
+		return fmt.Errorf("wall.render.theme: unknown theme %q", cfg.Theme)
+	}
+	base, err := outputFromConfig(cfg.Output)
+	if err != nil {
+		return fmt.Errorf("wall.render.output: %w", err)
+	}
+	overrides := make(map[string]OutputOptions, len(cfg.Themes))
+	for name, tc := range cfg.Themes {
+		if _, ok := ts.themes[name]; !ok {
+			return fmt.Errorf("wall.render.themes.%s: unknown theme", name)
+		}
+		if overrides[name], err = outputFromConfig(tc.Output); err != nil {
+			return fmt.Errorf("wall.render.themes.%s.output: %w", name, err)
+		}
+	}
+
+	for name, t := range ts.themes {
+		t.Output = t.Output.merge(base)
+		if tc, ok := cfg.Themes[name]; ok {
+			t.Output = t.Output.merge(overrides[name])
+			if tc.Justify {
+				t.Justify = true
+			}
+		}
+		ts.themes[name] = t
+	}
+	if cfg.Theme != "" {
+		ts.selected = cfg.Theme
+	}
+	r.themes = ts
+	r.attachGIF = cfg.AttachGIF
+	return nil
+}
+
+// Theme 返回指定名称的主题，名称为空时返回当前默认主题
+func (r *Renderer) Theme(name string) (Theme, bool) {
+	ts := r.themeSet()
+	if name == "" {
+		name = ts.selected
+	}
+	t, ok := ts.themes[name]
+	return t, ok
+}
+
+// ThemeNames 返回所有可用主题名
+func (r *Renderer) ThemeNames() []string {
+	ts := r.themeSet()
+	names := make([]string, 0, len(ts.themes))
+	for name := range ts.themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var fallbackThemes = newThemeSet()
+
+func (r *Renderer) themeSet() *themeSet {
+	if r.themes == nil {
+		return fallbackThemes
+	}
+	return r.themes
+}
+
+func (r *Renderer) currentTheme() Theme {
+	t, _ := r.Theme("")
+	return t
+}
+
+// outputFromConfig 转换输出配置，格式名为空时沿用上一级的格式
+func outputFromConfig(c config.OutputConfig) (OutputOptions, error) {
+	opt := OutputOptions{
+		Quality:  c.Quality,
+		MaxBytes: c.MaxBytes,
+		Scale:    c.Scale,
+	}
+	if c.Format != "" {
+		f, err := ParseOutputFormat(c.Format)
+		if err != nil {
+			return opt, err
+		}
+		opt.Format = f
+	}
+	return opt, nil
+}
@@ -0,0 +1,58 @@
+package render
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/fogleman/gg"
+	xdraw "golang.org/x/image/draw"
+)
+
+const (
+	blurFactor = 32  // 模糊程度：先缩小到 1/blurFactor 再放大
+	warnDim    = 0.4 // 模糊图上叠加的暗色遮罩不透明度
+)
+
+// blurImage 缩小后再放大得到模糊图 (尺寸不变)，足以遮住细节且开销很小
+func blurImage(src image.Image) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx()/blurFactor, b.Dy()/blurFactor
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	small := image.NewRGBA(image.Rect(0, 0, w, h))
+	xdraw.ApproxBiLinear.Scale(small, small.Bounds(), src, b, draw.Src, nil)
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	xdraw.BiLinear.Scale(dst, dst.Bounds(), small, small.Bounds(), draw.Src, nil)
+	return dst
+}
+
+// drawWarningOverlay 在已绘制的模糊图上叠加暗色遮罩和警告文字，调用方负责裁剪圆角
+func (r *Renderer) drawWarningOverlay(dc *gg.Context, label string, x, y, w, h, k float64) {
+	dc.SetRGBA(0, 0, 0, warnDim)
+	dc.DrawRectangle(x, y, w, h)
+	dc.Fill()
+
+	// 小图 (九宫格、聊天记录) 用小一号的字
+	size := 26.0 * k
+	if h < 160*k {
+		size = 20.0 * k
+	}
+	face := r.getFace(size)
+	dc.SetFontFace(face)
+	dc.SetRGB(1, 1, 1)
+	label = truncateToWidth(dc, label, w-16*k)
+	if h < 100*k {
+		dc.DrawStringAnchored(label, x+w/2, y+h/2, 0.5, 0.5)
+		return
+	}
+	dc.DrawStringAnchored(label, x+w/2, y+h/2-size*0.7, 0.5, 0.5)
+
+	hintFace := r.getFace(size * 0.75)
+	dc.SetFontFace(hintFace)
+	dc.SetRGBA(1, 1, 1, 0.8)
+	dc.DrawStringAnchored(truncateToWidth(dc, "清晰原图见后续图片", w-16*k), x+w/2, y+h/2+size*0.7, 0.5, 0.5)
+}
@@ -0,0 +1,116 @@
+package source
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	zero "github.com/wdvxdr1123/ZeroBot"
+	"github.com/wdvxdr1123/ZeroBot/message"
+)
+
+// ──────────────────────────────────────────
+// 投稿黑名单：/封禁 /解封 /封禁列表
+// ──────────────────────────────────────────
+
+// checkBan 发送者被封禁时回复提示并返回 false
+func (b *QQBot) checkBan(ctx *zero.Ctx) bool {
+	ban, err := b.store.ActiveBan(model.QQBanTarget(ctx.Event.UserID))
+	if err != nil {
+		log.Printf("[QQBot] 查询封禁失败: %v", err)
+		return true
+	}
+	if ban == nil {
+		return true
+	}
+	ctx.Send(message.Text(ban.Notice()))
+	return false
+}
+
+// handleBan /封禁 <QQ|@某人> [时长] [理由]，同时拒绝其待审核的稿件
+func (b *QQBot) handleBan(ctx *zero.Ctx) {
+	uin, rest := memberArgs(ctx)
+	if uin == 0 {
+		ctx.Send(message.Text("用法: /封禁 <QQ|@某人> [时长] [理由]\n时长如 30m、12h、7d、2周，不填为永久"))
+		return
+	}
+	if b.roleOfUser(ctx, uin).IsStaff() {
+		ctx.Send(message.Text("❌ 不能封禁审核团队成员，请先 /移除审核"))
+		return
+	}
+
+	var d time.Duration
+	if first, reason, _ := strings.Cut(rest, " "); first != "" {
+		if parsed, err := model.ParseBanDuration(first); err == nil {
+			d, rest = parsed, strings.TrimSpace(reason)
+		}
+	}
+	ban := &model.Ban{
+		Target:   model.QQBanTarget(uin),
+		Reason:   rest,
+		Operator: model.QQVoter(ctx.Event.UserID),
+	}
+	if d > 0 {
+		ban.ExpireTime = time.Now().Add(d).Unix()
+	}
+	if err := b.store.SaveBan(ban); err != nil {
+		ctx.Send(message.Text("❌ 封禁失败: " + err.Error()))
+		return
+	}
+	log.Printf("[QQBot] %d 封禁了 %d (%s): %s", ctx.Event.UserID, uin, ban.Until(), ban.Reason)
+
+	msg := fmt.Sprintf("🚫 已封禁 %d (%s)", uin, ban.Until())
+	if ban.Reason != "" {
+		msg += "\n理由: " + ban.Reason
+	}
+	if n, err := b.store.RejectPendingByUIN(uin, "投稿者已被封禁"); err != nil {
+		log.Printf("[QQBot] 拒绝 %d 的待审核稿件失败: %v", uin, err)
+	} else if n > 0 {
+		msg += fmt.Sprintf("\n已拒绝其 %d 篇待审核稿件", n)
+	}
+	ctx.Send(message.Text(msg))
+}
+
+// handleUnban /解封 <QQ|@某人>
+func (b *QQBot) handleUnban(ctx *zero.Ctx) {
+	uin, _ := memberArgs(ctx)
+	if uin == 0 {
+		ctx.Send(message.Text("用法: /解封 <QQ|@某人>"))
+		return
+	}
+	ok, err := b.store.RemoveBan(model.QQBanTarget(uin))
+	if err != nil {
+		ctx.Send(message.Text("❌ 解封失败: " + err.Error()))
+		return
+	}
+	if !ok {
+		ctx.Send(message.Text(fmt.Sprintf("%d 没有被封禁", uin)))
+		return
+	}
+	log.Printf("[QQBot] %d 解封了 %d", ctx.Event.UserID, uin)
+	ctx.Send(message.Text(fmt.Sprintf("✅ 已解封 %d", uin)))
+}
+
+// handleListBans /封禁列表
+func (b *QQBot) handleListBans(ctx *zero.Ctx) {
+	bans, err := b.store.ListBans()
+	if err != nil {
+		ctx.Send(message.Text("❌ 查询失败: " + err.Error()))
+		return
+	}
+	if len(bans) == 0 {
+		ctx.Send(message.Text("📭 当前没有封禁"))
+		return
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "🚫 封禁列表 (%d)\n", len(bans))
+	for _, ban := range bans {
+		fmt.Fprintf(&sb, "\n%s - %s", ban.Target, ban.Until())
+		if ban.Reason != "" {
+			sb.WriteString("，" + ban.Reason)
+		}
+	}
+	ctx.Send(message.Text(sb.String()))
+}
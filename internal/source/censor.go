@@ -0,0 +1,70 @@
+package source
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/censor"
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	zero "github.com/wdvxdr1123/ZeroBot"
+	"github.com/wdvxdr1123/ZeroBot/message"
+)
+
+// ──────────────────────────────────────────
+// 敏感词管理：/加敏感词 /删敏感词
+// ──────────────────────────────────────────
+
+// handleAddCensorWord /加敏感词 <[处理方式:]词|/正则/> [分类]
+func (b *QQBot) handleAddCensorWord(ctx *zero.Ctx) {
+	arg, category, _ := strings.Cut(getArgs(ctx), " ")
+	if arg == "" {
+		ctx.Send(message.Text("用法: /加敏感词 <[处理方式:]词|/正则/> [分类]\n处理方式: 屏蔽、复核、打码，不填为 " + b.censor.DefaultAction().Text()))
+		return
+	}
+	rule, err := censor.ParseRule(arg, b.censor.DefaultAction())
+	if err != nil {
+		ctx.Send(message.Text("❌ " + err.Error()))
+		return
+	}
+	w := &model.CensorWord{
+		Pattern:  rule.Pattern,
+		Action:   string(rule.Action),
+		Regex:    rule.Regex,
+		Category: category,
+		AddedBy:  model.QQVoter(ctx.Event.UserID),
+	}
+	if err := b.censor.AddWord(w); err != nil {
+		ctx.Send(message.Text("❌ 添加失败: " + err.Error()))
+		return
+	}
+	log.Printf("[QQBot] %d 添加敏感词 %s (%s)", ctx.Event.UserID, rule, w.Category)
+	ctx.Send(message.Text(fmt.Sprintf("✅ 已添加敏感词 %s [%s]，处理方式: %s\n当前共 %d 条规则",
+		rule.Pattern, w.Category, rule.Action.Text(), b.censor.Len())))
+}
+
+// handleRemoveCensorWord /删敏感词 <词|/正则/>
+func (b *QQBot) handleRemoveCensorWord(ctx *zero.Ctx) {
+	pattern := getArgs(ctx)
+	if pattern == "" {
+		ctx.Send(message.Text("用法: /删敏感词 <词|/正则/>"))
+		return
+	}
+	ok, err := b.censor.RemoveWord(pattern)
+	if err != nil {
+		ctx.Send(message.Text("❌ 删除失败: " + err.Error()))
+		return
+	}
+	if !ok {
+		for _, r := range b.censor.StaticRules() {
+			if r.Pattern == pattern || r.Regex && "/"+r.Pattern+"/" == pattern {
+				ctx.Send(message.Text(fmt.Sprintf("%s 来自%s，请修改配置后生效", pattern, r.Category)))
+				return
+			}
+		}
+		ctx.Send(message.Text(fmt.Sprintf("没有找到敏感词 %s", pattern)))
+		return
+	}
+	log.Printf("[QQBot] %d 删除敏感词 %s", ctx.Event.UserID, pattern)
+	ctx.Send(message.Text(fmt.Sprintf("✅ 已删除敏感词 %s", pattern)))
+}
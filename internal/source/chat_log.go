@@ -0,0 +1,203 @@
+package source
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	"github.com/tidwall/gjson"
+	zero "github.com/wdvxdr1123/ZeroBot"
+	"github.com/wdvxdr1123/ZeroBot/message"
+)
+
+const (
+	chatLogTimeout   = 3 * time.Minute // 两条消息之间的最长等待
+	chatLogMaxMsgs   = 50              // 单条聊天记录投稿的最大消息数
+	chatSpeakerMaxLn = 16              // "别名:" 前缀的最大长度
+)
+
+// chatSelfAliases 以这些别名开头的消息视为投稿者本人，显示在右侧
+var chatSelfAliases = map[string]bool{"我": true, "me": true, "Me": true, "ME": true}
+
+// handleChatLog 聊天记录投稿：附带合并转发时直接投稿，否则逐条收集后续消息
+func (b *QQBot) handleChatLog(ctx *zero.Ctx, anon bool) {
+	if !b.canSubmit(ctx) {
+		return
+	}
+	post := &model.Post{Anon: anon}
+	applyWarningTag(post, extractSegments(ctx))
+	if id := findForward(ctx.Event.Message); id != "" {
+		post.Chat, post.Images = chatFromForward(ctx, id)
+		b.submitChat(ctx, post)
+		return
+	}
+
+	uid := ctx.Event.UserID
+	if !b.sessions.acquire(uid) {
+		ctx.Send(message.Text("⚠️ 你还有进行中的投稿，请先完成或发送「取消」"))
+		return
+	}
+	ctx.Send(message.Text("📝 请逐条发送聊天内容，格式「别名: 内容」，以「我:」开头的显示在右侧，省略别名则沿用上一条\n" +
+		"也可以直接发送合并转发\n发送「结束」提交，「取消」放弃"))
+
+	s, cancel := listen(ctx)
+	go func() {
+		defer b.sessions.release(uid)
+		defer cancel()
+		var (
+			chat    []model.ChatMessage
+			images  []string
+			speaker string
+		)
+		for {
+			select {
+			case c := <-s.recv:
+				switch strings.TrimSpace(c.Event.Message.ExtractPlainText()) {
+				case "结束":
+					post.Chat, post.Images = chat, images
+					b.submitChat(ctx, post)
+					return
+				case "取消":
+					ctx.Send(message.Text("已取消聊天记录投稿"))
+					return
+				}
+				if len(chat) >= chatLogMaxMsgs {
+					ctx.Send(message.Text(fmt.Sprintf("❌ 最多 %d 条消息，请发送「结束」提交", chatLogMaxMsgs)))
+					continue
+				}
+
+				if id := findForward(c.Event.Message); id != "" {
+					fwd, fwdImages := chatFromForward(c, id)
+					for _, m := range fwd {
+						for i := range m.Images {
+							m.Images[i] += len(images)
+						}
+						chat = append(chat, m)
+					}
+					images = append(images, fwdImages...)
+					continue
+				}
+
+				segs := dropReply(trimSegments(toSegments(c, c.Event.Message)))
+				if name, rest, ok := splitSpeaker(segs); ok {
+					speaker, segs = name, rest
+				}
+				if speaker == "" {
+					speaker = "对方"
+				}
+				m := model.ChatMessage{Speaker: speaker, Self: chatSelfAliases[speaker], Segments: segs}
+				for _, img := range imagesOf(c.Event.Message) {
+					m.Images = append(m.Images, len(images))
+					images = append(images, img)
+				}
+				if len(m.Segments) > 0 || len(m.Images) > 0 {
+					chat = append(chat, m)
+				}
+			case <-time.After(chatLogTimeout):
+				ctx.Send(message.Text("⌛ 聊天记录投稿超时，已取消"))
+				return
+			}
+		}
+	}()
+}
+
+// submitChat 将聊天记录作为一条稿件提交
+func (b *QQBot) submitChat(ctx *zero.Ctx, post *model.Post) {
+	if len(post.Chat) == 0 {
+		ctx.Send(message.Text("❌ 没有收到任何聊天内容"))
+		return
+	}
+	post.Text = model.ChatText(post.Chat)
+	b.submitPost(ctx, post)
+}
+
+// findForward 返回消息中合并转发的 ID
+func findForward(msg message.Message) string {
+	for _, seg := range msg {
+		if seg.Type == "forward" && seg.Data["id"] != "" {
+			return seg.Data["id"]
+		}
+	}
+	return ""
+}
+
+// chatFromForward 拉取合并转发内容，逐条转为聊天记录；发送者为投稿者本人时显示在右侧
+func chatFromForward(ctx *zero.Ctx, id string) ([]model.ChatMessage, []string) {
+	var (
+		chat   []model.ChatMessage
+		images []string
+	)
+	ctx.GetForwardMessage(id).Get("messages").ForEach(func(_, node gjson.Result) bool {
+		// NapCat 使用 message，go-cqhttp 使用 content
+		content := node.Get("message")
+		if !content.Exists() {
+			content = node.Get("content")
+		}
+		msg := message.ParseMessage([]byte(content.Raw))
+
+		uin := node.Get("sender.user_id").Int()
+		name := node.Get("sender.card").String()
+		if name == "" {
+			name = node.Get("sender.nickname").String()
+		}
+		if name == "" {
+			name = strconv.FormatInt(uin, 10)
+		}
+
+		m := model.ChatMessage{
+			Speaker:  name,
+			UIN:      uin,
+			Self:     uin == ctx.Event.UserID,
+			Segments: dropReply(trimSegments(toSegments(ctx, msg))),
+		}
+		for _, img := range imagesOf(msg) {
+			m.Images = append(m.Images, len(images))
+			images = append(images, img)
+		}
+		if len(m.Segments) > 0 || len(m.Images) > 0 {
+			chat = append(chat, m)
+		}
+		return len(chat) < chatLogMaxMsgs
+	})
+	return chat, images
+}
+
+// splitSpeaker 解析消息开头的 "别名:" 前缀
+func splitSpeaker(segs []model.Segment) (string, []model.Segment, bool) {
+	if len(segs) == 0 || segs[0].Type != model.SegText {
+		return "", segs, false
+	}
+	text := segs[0].Text
+	i := strings.IndexAny(text, ":：")
+	if i <= 0 {
+		return "", segs, false
+	}
+	name := strings.TrimSpace(text[:i])
+	if name == "" || strings.ContainsRune(name, '\n') || len([]rune(name)) > chatSpeakerMaxLn {
+		return "", segs, false
+	}
+
+	_, size := utf8.DecodeRuneInString(text[i:])
+	rest := strings.TrimLeft(text[i+size:], " ")
+	out := append([]model.Segment(nil), segs...)
+	if rest == "" {
+		out = out[1:]
+	} else {
+		out[0].Text = rest
+	}
+	return name, out, true
+}
+
+// dropReply 去掉回复引用段 (聊天记录中不显示)
+func dropReply(segs []model.Segment) []model.Segment {
+	out := segs[:0]
+	for _, seg := range segs {
+		if seg.Type != model.SegReply {
+			out = append(out, seg)
+		}
+	}
+	return out
+}
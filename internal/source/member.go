@@ -0,0 +1,97 @@
+package source
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	zero "github.com/wdvxdr1123/ZeroBot"
+	"github.com/wdvxdr1123/ZeroBot/message"
+)
+
+// memberCacheTTL 群成员校验结果的缓存时间，避免每次投稿都调用 OneBot 接口
+const memberCacheTTL = 10 * time.Minute
+
+// memberCache 缓存用户是否为表白墙群成员
+type memberCache struct {
+	mu      sync.Mutex
+	entries map[int64]memberEntry
+}
+
+type memberEntry struct {
+	ok     bool
+	expire time.Time
+}
+
+func (c *memberCache) get(uid int64, now time.Time) (ok, found bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, found := c.entries[uid]
+	if !found || now.After(e.expire) {
+		return false, false
+	}
+	return e.ok, true
+}
+
+func (c *memberCache) put(uid int64, ok bool, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[int64]memberEntry)
+	}
+	c.entries[uid] = memberEntry{ok: ok, expire: now.Add(memberCacheTTL)}
+}
+
+// canSubmit 被封禁或超出频率限制的用户不能投稿；私聊投稿需是表白墙群 (bot.wall_groups) 的成员，
+// 未配置表白墙群时无法校验，不接受私聊投稿
+func (b *QQBot) canSubmit(ctx *zero.Ctx) bool {
+	if !b.checkBan(ctx) || !b.checkQuota(ctx) {
+		return false
+	}
+	if ctx.Event.GroupID != 0 {
+		return true
+	}
+	if len(b.botCfg.WallGroups) == 0 {
+		ctx.Send(message.Text("❌ 暂未开启私聊投稿，请在表白墙群内投稿"))
+		return false
+	}
+	if b.isWallMember(ctx, ctx.Event.UserID) {
+		return true
+	}
+	ctx.Send(message.Text("❌ 仅表白墙群成员可以私聊投稿，请先加入群聊"))
+	return false
+}
+
+// isWallMember 通过 OneBot 群成员接口检查用户是否在任一表白墙群中
+func (b *QQBot) isWallMember(ctx *zero.Ctx, uid int64) bool {
+	now := time.Now()
+	if ok, found := b.members.get(uid, now); found {
+		return ok
+	}
+	ok := false
+	for _, gid := range b.botCfg.WallGroups {
+		if ctx.GetGroupMemberInfo(gid, uid, false).Get("user_id").Int() == uid {
+			ok = true
+			break
+		}
+	}
+	b.members.put(uid, ok, now)
+	return ok
+}
+
+// notifySubmitter 通知投稿者：一律私聊，群内投稿的稿件 (尤其是匿名稿件) 也不在群里暴露投稿者
+func notifySubmitter(ctx *zero.Ctx, post *model.Post, text string) {
+	if post.UIN <= 0 {
+		return
+	}
+	ctx.SendPrivateMessage(post.UIN, message.Text(text))
+}
+
+// privateHint 群内匿名投稿时提示改用私聊 (需已开启私聊投稿)
+func (b *QQBot) privateHint(ctx *zero.Ctx, post *model.Post) string {
+	if !post.Anon || ctx.Event.GroupID == 0 || len(b.botCfg.WallGroups) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n💡 在群里投稿会被群友看到，下次可以私聊我发送 %s匿名投稿", zero.BotConfig.CommandPrefix)
+}
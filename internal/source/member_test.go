@@ -0,0 +1,25 @@
+package source
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemberCache(t *testing.T) {
+	var c memberCache
+	now := time.Unix(1700000000, 0)
+	if _, found := c.get(10001, now); found {
+		t.Fatal("empty cache should miss")
+	}
+	c.put(10001, true, now)
+	c.put(10002, false, now)
+	if ok, found := c.get(10001, now.Add(time.Minute)); !found || !ok {
+		t.Error("member should be cached")
+	}
+	if ok, found := c.get(10002, now.Add(time.Minute)); !found || ok {
+		t.Error("non-member should be cached as well")
+	}
+	if _, found := c.get(10001, now.Add(memberCacheTTL+time.Second)); found {
+		t.Error("entry should expire after the TTL")
+	}
+}
@@ -0,0 +1,232 @@
+package source
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	zero "github.com/wdvxdr1123/ZeroBot"
+	"github.com/wdvxdr1123/ZeroBot/message"
+)
+
+// ──────────────────────────────────────────
+// 投稿者自助命令：/我的投稿 /稿件状态 /修改
+// ──────────────────────────────────────────
+
+const myPostsPageSize = 5 // /我的投稿 每页条数
+
+// ownPost 按编号读取稿件并校验归属：只能操作自己的稿件，拥有权限 perm 的审核团队成员不受限。失败时已回复用户
+func (b *QQBot) ownPost(ctx *zero.Ctx, arg, action string, perm model.Permission) *model.Post {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		ctx.Send(message.Text("❌ 编号格式不正确"))
+		return nil
+	}
+	post, err := b.store.GetPost(id)
+	if err != nil || post == nil {
+		ctx.Send(message.Text(fmt.Sprintf("❌ 稿件 #%d 不存在", id)))
+		return nil
+	}
+	if post.UIN != ctx.Event.UserID && !b.roleOf(ctx).Can(perm) {
+		ctx.Send(message.Text("❌ 你只能" + action + "自己的稿件"))
+		return nil
+	}
+	return post
+}
+
+// handleMyPosts 分页列出自己的投稿
+func (b *QQBot) handleMyPosts(ctx *zero.Ctx) {
+	page := 1
+	if args := getArgs(ctx); args != "" {
+		n, err := strconv.Atoi(args)
+		if err != nil || n < 1 {
+			ctx.Send(message.Text("用法: /我的投稿 [页码]"))
+			return
+		}
+		page = n
+	}
+
+	uin := ctx.Event.UserID
+	total, err := b.store.CountByUIN(uin)
+	if err != nil {
+		ctx.Send(message.Text("❌ 查询失败: " + err.Error()))
+		return
+	}
+	if total == 0 {
+		ctx.Send(message.Text("📭 你还没有投过稿"))
+		return
+	}
+	pages := (total + myPostsPageSize - 1) / myPostsPageSize
+	if page > pages {
+		ctx.Send(message.Text(fmt.Sprintf("❌ 只有 %d 页", pages)))
+		return
+	}
+	posts, err := b.store.ListByUIN(uin, myPostsPageSize, (page-1)*myPostsPageSize)
+	if err != nil {
+		ctx.Send(message.Text("❌ 查询失败: " + err.Error()))
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📋 我的投稿 (共 %d 件，第 %d/%d 页)\n", total, page, pages)
+	for _, p := range posts {
+		fmt.Fprintf(&sb, "\n#%d [%s] %s\n", p.ID, p.Status.Text(), time.Unix(p.CreateTime, 0).Format("01-02 15:04"))
+		sb.WriteString(brief(p))
+	}
+	if page < pages {
+		fmt.Fprintf(&sb, "\n\n下一页: /我的投稿 %d", page+1)
+	}
+	ctx.Send(message.Text(sb.String()))
+}
+
+// brief 稿件内容的一行摘要
+func brief(p *model.Post) string {
+	text := strings.Join(strings.Fields(p.Text), " ")
+	if runes := []rune(text); len(runes) > 30 {
+		text = string(runes[:30]) + "..."
+	}
+	if len(p.Images) > 0 {
+		text += fmt.Sprintf(" [%d张图片]", len(p.Images))
+	}
+	return strings.TrimSpace(text)
+}
+
+// handlePostStatus 查看自己稿件的详细状态
+func (b *QQBot) handlePostStatus(ctx *zero.Ctx) {
+	args := getArgs(ctx)
+	if args == "" {
+		ctx.Send(message.Text("用法: /稿件状态 <编号>"))
+		return
+	}
+	post := b.ownPost(ctx, args, "查看", model.PermView)
+	if post == nil {
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📄 稿件 #%d\n状态: %s\n", post.ID, post.Status.Text())
+	fmt.Fprintf(&sb, "投稿时间: %s\n", time.Unix(post.CreateTime, 0).Format("2006-01-02 15:04"))
+	if post.Anon {
+		fmt.Fprintf(&sb, "匿名显示为: %s\n", post.ShowName())
+	}
+	if label := post.WarningLabel(); label != "" {
+		sb.WriteString("⚠️ " + label + "\n")
+	}
+
+	switch post.Status {
+	case model.StatusPending, model.StatusApproved:
+		queue := "审核"
+		if post.Status == model.StatusApproved {
+			queue = "发布"
+		}
+		if pos, err := b.store.QueuePosition(post); err == nil {
+			fmt.Fprintf(&sb, "%s队列: 第 %d 位\n", queue, pos)
+		}
+	case model.StatusRejected, model.StatusFailed:
+		if post.Reason != "" {
+			fmt.Fprintf(&sb, "理由: %s\n", post.Reason)
+		}
+	case model.StatusPublished:
+		fmt.Fprintf(&sb, "发布时间: %s\n", time.Unix(post.UpdateTime, 0).Format("2006-01-02 15:04"))
+	}
+	if c := brief(post); c != "" {
+		sb.WriteString("内容: " + c)
+	}
+	ctx.Send(message.Text(strings.TrimRight(sb.String(), "\n")))
+}
+
+// refreshRenders 稿件内容变化后，已保存过截图的稿件重新渲染一个版本，避免 /看稿 显示旧内容
+func (b *QQBot) refreshRenders(post *model.Post) {
+	if old, err := b.artifacts.Latest(post.ID); err != nil || len(old) == 0 || !b.renderer.Available() {
+		return
+	}
+	images, err := b.renderer.RenderForPublish(resolvePostImages(post))
+	if err == nil {
+		_, err = b.artifacts.Save(post.ID, images, false)
+	}
+	if err != nil {
+		log.Printf("[QQBot] 稿件 #%d 重新渲染失败: %v", post.ID, err)
+	}
+}
+
+// handleEditPost 修改自己待审核的稿件：替换正文 (附带图片时一并替换)，之前的投票作废并通知管理群。
+// 被拒的稿件不能修改后重新进入审核，需重新投稿
+func (b *QQBot) handleEditPost(ctx *zero.Ctx) {
+	args := getArgs(ctx)
+	idStr, _, _ := strings.Cut(args, " ")
+	if idStr == "" {
+		ctx.Send(message.Text("用法: /修改 <编号> <新内容>"))
+		return
+	}
+	if !b.checkBan(ctx) {
+		return
+	}
+	post := b.ownPost(ctx, idStr, "修改", model.PermReview)
+	if post == nil {
+		return
+	}
+	if post.Status != model.StatusPending {
+		ctx.Send(message.Text(fmt.Sprintf("❌ 稿件 #%d %s，只能修改待审核的稿件", post.ID, post.Status.Text())))
+		return
+	}
+	if post.IsChat() {
+		ctx.Send(message.Text("❌ 聊天记录稿件无法修改，请撤稿后重新投稿"))
+		return
+	}
+
+	// 去掉开头的编号，剩下的是新内容
+	segs := extractSegments(ctx)
+	if len(segs) > 0 && segs[0].Type == model.SegText {
+		segs[0].Text = strings.TrimPrefix(segs[0].Text, idStr)
+		segs = trimSegments(segs)
+	}
+	images := extractImages(ctx)
+	if len(segs) == 0 && len(images) == 0 {
+		ctx.Send(message.Text("用法: /修改 <编号> <新内容>"))
+		return
+	}
+
+	// 只附带图片时保留原正文
+	edited := *post
+	if segs = applyWarningTag(&edited, segs); len(segs) > 0 {
+		edited.Text = strings.TrimSpace(model.PlainText(segs))
+		edited.Segments = nil
+		if hasRichSegment(segs) {
+			edited.Segments = segs
+		}
+	}
+	if len(images) > 0 {
+		edited.Images = images
+	}
+	if msg := b.checkPost(&edited); msg != "" {
+		ctx.Send(message.Text(msg))
+		return
+	}
+
+	// 条件更新：校验期间稿件被过稿/拒稿时不覆盖
+	ok, err := b.store.SavePostIf(&edited, model.StatusPending)
+	if err != nil {
+		ctx.Send(message.Text("❌ 保存失败: " + err.Error()))
+		return
+	}
+	if !ok {
+		ctx.Send(message.Text(fmt.Sprintf("❌ 稿件 #%d 已被处理，只能修改待审核的稿件", edited.ID)))
+		return
+	}
+	// 内容已变，之前的投票作废
+	if err := b.store.ClearVotes(edited.ID); err != nil {
+		log.Printf("[QQBot] 清空稿件 #%d 投票失败: %v", edited.ID, err)
+	}
+	ctx.Send(message.Text(fmt.Sprintf("✅ 稿件 #%d 已修改，重新等待审核", edited.ID)))
+	b.refreshRenders(&edited)
+
+	editor := "投稿者"
+	if edited.UIN != ctx.Event.UserID {
+		editor = fmt.Sprintf("审核员 %s(%d)", ctx.Event.Sender.NickName, ctx.Event.UserID)
+		notifySubmitter(ctx, &edited, fmt.Sprintf("✏️ 您的投稿 #%d 已被审核员修改", edited.ID))
+	}
+	b.notifyManage(ctx, &edited, fmt.Sprintf("✏️ 稿件 #%d 已被%s修改，请重新审核", edited.ID, editor))
+}
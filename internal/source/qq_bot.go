@@ -3,19 +3,28 @@ package source
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"image"
 	"io"
 	"log"
 	"net/http"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	qzone "github.com/guohuiyuan/qzone-go"
+	"github.com/guohuiyuan/qzonewall-go/internal/anon"
+	"github.com/guohuiyuan/qzonewall-go/internal/artifact"
+	"github.com/guohuiyuan/qzonewall-go/internal/censor"
 	"github.com/guohuiyuan/qzonewall-go/internal/config"
 	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	"github.com/guohuiyuan/qzonewall-go/internal/quota"
 	"github.com/guohuiyuan/qzonewall-go/internal/render"
+	"github.com/guohuiyuan/qzonewall-go/internal/render/faces"
 	"github.com/guohuiyuan/qzonewall-go/internal/store"
+	"github.com/guohuiyuan/qzonewall-go/internal/validate"
 
 	zero "github.com/wdvxdr1123/ZeroBot"
 	"github.com/wdvxdr1123/ZeroBot/driver"
@@ -24,14 +33,21 @@ import (
 
 // QQBot 基于 NapCat + ZeroBot 的 QQ 数据源
 type QQBot struct {
-	botCfg      config.BotConfig
-	wallCfg     config.WallConfig
-	qzoneCfg    config.QzoneConfig
-	store       *store.Store
-	renderer    *render.Renderer
-	qzClient    *qzone.Client
-	censorWords []string
-	engine      *zero.Engine
+	botCfg    config.BotConfig
+	wallCfg   config.WallConfig
+	qzoneCfg  config.QzoneConfig
+	store     *store.Store
+	renderer  *render.Renderer
+	qzClient  *qzone.Client
+	namer     *anon.Namer
+	artifacts *artifact.Store
+	limiter   *quota.Limiter
+	validator *validate.Validator
+	censor    *censor.Engine
+	engine    *zero.Engine
+	sessions  sessionLocks // 进行中的对话，每个用户同时只能有一个
+	members   memberCache  // 私聊投稿的群成员校验结果
+	admins    memberCache  // 管理群群主/管理员的校验结果 (bot.manage_admins)
 }
 
 // NewQQBot 创建 QQ 机器人
@@ -42,16 +58,24 @@ func NewQQBot(
 	st *store.Store,
 	renderer *render.Renderer,
 	qzClient *qzone.Client,
-	censorWords []string,
+	namer *anon.Namer,
+	artifacts *artifact.Store,
+	limiter *quota.Limiter,
+	validator *validate.Validator,
+	censorEngine *censor.Engine,
 ) *QQBot {
 	return &QQBot{
-		botCfg:      botCfg,
-		wallCfg:     wallCfg,
-		qzoneCfg:    qzoneCfg,
-		store:       st,
-		renderer:    renderer,
-		qzClient:    qzClient,
-		censorWords: censorWords,
+		botCfg:    botCfg,
+		wallCfg:   wallCfg,
+		qzoneCfg:  qzoneCfg,
+		store:     st,
+		renderer:  renderer,
+		qzClient:  qzClient,
+		namer:     namer,
+		artifacts: artifacts,
+		limiter:   limiter,
+		validator: validator,
+		censor:    censorEngine,
 	}
 }
 
@@ -97,6 +121,9 @@ func (b *QQBot) Stop() {
 // ──────────────────────────────────────────
 
 func (b *QQBot) registerCommands() {
+	// 回复消息时 reply 段在最前，命令规则要求首段为文字，挪到末尾
+	b.engine.UsePreHandler(moveReplyToEnd)
+
 	// ── 用户命令 ──
 	b.engine.OnCommand("投稿").Handle(func(ctx *zero.Ctx) {
 		b.handleContribute(ctx, false)
@@ -104,32 +131,85 @@ func (b *QQBot) registerCommands() {
 	b.engine.OnCommand("匿名投稿").Handle(func(ctx *zero.Ctx) {
 		b.handleContribute(ctx, true)
 	})
+	b.engine.OnCommand("聊天记录").Handle(func(ctx *zero.Ctx) {
+		b.handleChatLog(ctx, false)
+	})
+	b.engine.OnCommand("匿名聊天记录").Handle(func(ctx *zero.Ctx) {
+		b.handleChatLog(ctx, true)
+	})
 	b.engine.OnCommand("撤稿").Handle(func(ctx *zero.Ctx) {
 		b.handleRecall(ctx)
 	})
+	b.engine.OnCommand("我的投稿").Handle(func(ctx *zero.Ctx) {
+		b.handleMyPosts(ctx)
+	})
+	b.engine.OnCommand("稿件状态").Handle(func(ctx *zero.Ctx) {
+		b.handlePostStatus(ctx)
+	})
+	b.engine.OnCommand("修改").Handle(func(ctx *zero.Ctx) {
+		b.handleEditPost(ctx)
+	})
 
-	// ── 管理员命令 ──
-	b.engine.OnCommand("看稿", zero.SuperUserPermission).SetBlock(true).Handle(func(ctx *zero.Ctx) {
+	// ── 管理命令 (按审核团队角色授权，见 team.go) ──
+	b.engine.OnCommand("看稿", b.allow(model.PermView)).SetBlock(true).Handle(func(ctx *zero.Ctx) {
 		b.handleViewPost(ctx)
 	})
-	b.engine.OnCommand("过稿", zero.SuperUserPermission).SetBlock(true).Handle(func(ctx *zero.Ctx) {
+	b.engine.OnCommand("过稿", b.allow(model.PermReview)).SetBlock(true).Handle(func(ctx *zero.Ctx) {
 		b.handleApprove(ctx)
 	})
-	b.engine.OnCommand("拒稿", zero.SuperUserPermission).SetBlock(true).Handle(func(ctx *zero.Ctx) {
+	b.engine.OnCommand("拒稿", b.allow(model.PermReview)).SetBlock(true).Handle(func(ctx *zero.Ctx) {
 		b.handleReject(ctx)
 	})
-	b.engine.OnCommand("待审核", zero.SuperUserPermission).SetBlock(true).Handle(func(ctx *zero.Ctx) {
+	b.engine.OnCommand("重新渲染", b.allow(model.PermReview)).SetBlock(true).Handle(func(ctx *zero.Ctx) {
+		b.handleRerender(ctx)
+	})
+	b.engine.OnCommand("内容警告", b.allow(model.PermReview)).SetBlock(true).Handle(func(ctx *zero.Ctx) {
+		b.handleWarning(ctx)
+	})
+	b.engine.OnCommand("待审核", b.allow(model.PermView)).SetBlock(true).Handle(func(ctx *zero.Ctx) {
 		b.handleListPending(ctx)
 	})
-	b.engine.OnCommand("发说说", zero.SuperUserPermission).SetBlock(true).Handle(func(ctx *zero.Ctx) {
+	b.engine.OnCommand("精选", b.allow(model.PermPublish)).SetBlock(true).Handle(func(ctx *zero.Ctx) {
+		b.handleDigest(ctx)
+	})
+	b.engine.OnCommand("发说说", b.allow(model.PermPublish)).SetBlock(true).Handle(func(ctx *zero.Ctx) {
 		b.handleDirectPublish(ctx)
 	})
-	b.engine.OnCommand("扫码", zero.SuperUserPermission).SetBlock(true).Handle(func(ctx *zero.Ctx) {
+	b.engine.OnCommand("扫码", b.allow(model.PermAccount)).SetBlock(true).Handle(func(ctx *zero.Ctx) {
 		b.handleScanQR(ctx)
 	})
-	b.engine.OnCommand("刷新cookie", zero.SuperUserPermission).SetBlock(true).Handle(func(ctx *zero.Ctx) {
+	b.engine.OnCommand("刷新cookie", b.allow(model.PermAccount)).SetBlock(true).Handle(func(ctx *zero.Ctx) {
 		b.handleRefreshCookie(ctx)
 	})
+	// 命令按前缀匹配，/封禁列表 需在 /封禁 之前注册
+	b.engine.OnCommand("封禁列表", b.allow(model.PermView)).SetBlock(true).Handle(func(ctx *zero.Ctx) {
+		b.handleListBans(ctx)
+	})
+	b.engine.OnCommand("封禁", b.allow(model.PermReview)).SetBlock(true).Handle(func(ctx *zero.Ctx) {
+		b.handleBan(ctx)
+	})
+	b.engine.OnCommand("解封", b.allow(model.PermReview)).SetBlock(true).Handle(func(ctx *zero.Ctx) {
+		b.handleUnban(ctx)
+	})
+	b.engine.OnCommand("加敏感词", b.allow(model.PermCensor)).SetBlock(true).Handle(func(ctx *zero.Ctx) {
+		b.handleAddCensorWord(ctx)
+	})
+	b.engine.OnCommand("删敏感词", b.allow(model.PermCensor)).SetBlock(true).Handle(func(ctx *zero.Ctx) {
+		b.handleRemoveCensorWord(ctx)
+	})
+	b.engine.OnCommand("添加审核", b.allow(model.PermTeam)).SetBlock(true).Handle(func(ctx *zero.Ctx) {
+		b.handleAddModerator(ctx)
+	})
+	b.engine.OnCommand("移除审核", b.allow(model.PermTeam)).SetBlock(true).Handle(func(ctx *zero.Ctx) {
+		b.handleRemoveModerator(ctx)
+	})
+	b.engine.OnCommand("审核团队", b.allow(model.PermView)).SetBlock(true).Handle(func(ctx *zero.Ctx) {
+		b.handleListTeam(ctx)
+	})
+	// 在管理群回复新投稿通知进行审核
+	b.engine.OnMessage(zero.OnlyGroup, b.isReviewReply, b.allow(model.PermView)).SetBlock(true).Handle(func(ctx *zero.Ctx) {
+		b.handleReviewReply(ctx)
+	})
 	b.engine.OnCommandGroup([]string{"帮助", "help"}).SetBlock(true).Handle(func(ctx *zero.Ctx) {
 		b.handleHelp(ctx)
 	})
@@ -141,51 +221,96 @@ func (b *QQBot) registerCommands() {
 
 // handleContribute 投稿 / 匿名投稿
 func (b *QQBot) handleContribute(ctx *zero.Ctx, anon bool) {
-	rawText := getArgs(ctx)
-	text := strings.TrimSpace(rawText)
-	images := extractImages(ctx)
-
-	if text == "" && len(images) == 0 {
-		ctx.Send(message.Text("❌ 投稿内容不能为空，请发送文字或图片"))
+	if !b.canSubmit(ctx) {
 		return
 	}
-	if b.wallCfg.MaxTextLen > 0 && len([]rune(text)) > b.wallCfg.MaxTextLen {
-		ctx.Send(message.Text(fmt.Sprintf("❌ 文字超出限制 (%d/%d)", len([]rune(text)), b.wallCfg.MaxTextLen)))
+	post := &model.Post{Anon: anon}
+	segments := applyWarningTag(post, extractSegments(ctx))
+
+	// 附带合并转发时按聊天记录投稿
+	if id := findForward(ctx.Event.Message); id != "" {
+		post.Chat, post.Images = chatFromForward(ctx, id)
+		b.submitChat(ctx, post)
 		return
 	}
-	if b.wallCfg.MaxImages > 0 && len(images) > b.wallCfg.MaxImages {
-		ctx.Send(message.Text(fmt.Sprintf("❌ 图片超出限制 (%d/%d)", len(images), b.wallCfg.MaxImages)))
+
+	post.Text = strings.TrimSpace(model.PlainText(segments))
+	post.Images = extractImages(ctx)
+	if hasRichSegment(segments) {
+		post.Segments = segments
+	}
+	// 只发了命令时进入引导式投稿，逐步收集内容
+	if post.Text == "" && len(post.Images) == 0 && len(post.Segments) == 0 {
+		b.startSession(ctx, post, !anon)
 		return
 	}
+	b.submitPost(ctx, post)
+}
 
-	if len(b.censorWords) > 0 {
-		if hit, word := store.CheckCensor(text, b.censorWords); hit {
-			ctx.Send(message.Text(fmt.Sprintf("❌ 投稿包含违禁词: %s", word)))
-			return
+// checkPost 规范化并校验投稿内容，不通过时返回提示
+func (b *QQBot) checkPost(post *model.Post) string {
+	err := b.validator.Check(post)
+	var verr *validate.Error
+	if !errors.As(err, &verr) {
+		return ""
+	}
+	switch verr.Code {
+	case validate.CodeEmpty:
+		return "❌ 投稿内容不能为空，请发送文字或图片"
+	case validate.CodeCensored:
+		if len(verr.Images) > 0 {
+			return fmt.Sprintf("❌ 第 %s 张图片不允许发布，请更换后重试", verr.ImageList())
 		}
+		return "❌ 投稿包含不允许发布的内容，请修改后重试"
+	case validate.CodeDuplicate:
+		return fmt.Sprintf("❌ 与稿件 #%d 内容相同，请勿重复投稿", verr.PostID)
+	}
+	return "❌ " + verr.Error()
+}
+
+// stampPost 填写投稿者信息和投稿时间
+func (b *QQBot) stampPost(ctx *zero.Ctx, post *model.Post) {
+	post.UIN = ctx.Event.UserID
+	post.Name = ctx.Event.Sender.NickName
+	post.GroupID = ctx.Event.GroupID
+	post.Status = model.StatusPending
+	post.CreateTime = time.Now().Unix()
+	b.namer.Apply(post, "")
+}
+
+// submitPost 校验并保存投稿，回复投稿者并通知管理群
+func (b *QQBot) submitPost(ctx *zero.Ctx, post *model.Post) {
+	if msg := b.checkPost(post); msg != "" {
+		ctx.Send(message.Text(msg))
+		return
 	}
 
-	post := &model.Post{
-		UIN:        ctx.Event.UserID,
-		Name:       ctx.Event.Sender.NickName,
-		GroupID:    ctx.Event.GroupID,
-		Text:       text,
-		Images:     images,
-		Anon:       anon,
-		Status:     model.StatusPending,
-		CreateTime: time.Now().Unix(),
+	b.stampPost(ctx, post)
+	now := time.Now()
+	if !b.takeQuota(ctx, now) {
+		return
 	}
 	if err := b.store.SavePost(post); err != nil {
+		b.releaseQuota(ctx, now)
 		ctx.Send(message.Text("❌ 保存失败: " + err.Error()))
 		return
 	}
 
-	ctx.Send(message.Text(fmt.Sprintf("✅ 投稿成功！编号 #%d，等待审核...", post.ID)))
+	reply := fmt.Sprintf("✅ 投稿成功！编号 #%d，等待审核...", post.ID)
+	if post.Anon {
+		reply += fmt.Sprintf("\n本次匿名显示为「%s」", post.ShowName())
+	}
+	if post.Warning && len(post.Images) > 0 {
+		reply += "\n已标记内容警告，配图将模糊显示，清晰原图附在截图之后"
+	}
+	reply += b.privateHint(ctx, post)
+	ctx.Send(message.Text(reply))
 
-	if b.botCfg.ManageGroup > 0 {
-		notifyMsg := fmt.Sprintf("📬 收到新投稿 #%d\n%s", post.ID, post.Summary())
-		ctx.SendGroupMessage(b.botCfg.ManageGroup, message.Text(notifyMsg))
+	header := fmt.Sprintf("📬 收到新投稿 #%d", post.ID)
+	if post.GroupID == 0 {
+		header += " (私聊)"
 	}
+	b.notifyManage(ctx, post, header)
 }
 
 // handleRecall 撤稿
@@ -195,18 +320,8 @@ func (b *QQBot) handleRecall(ctx *zero.Ctx) {
 		ctx.Send(message.Text("用法: /撤稿 <编号>"))
 		return
 	}
-	id, err := strconv.ParseInt(args, 10, 64)
-	if err != nil {
-		ctx.Send(message.Text("❌ 编号格式不正确"))
-		return
-	}
-	post, err := b.store.GetPost(id)
-	if err != nil || post == nil {
-		ctx.Send(message.Text(fmt.Sprintf("❌ 稿件 #%d 不存在", id)))
-		return
-	}
-	if post.UIN != ctx.Event.UserID && !zero.SuperUserPermission(ctx) {
-		ctx.Send(message.Text("❌ 你只能撤回自己的稿件"))
+	post := b.ownPost(ctx, args, "撤回", model.PermReview)
+	if post == nil {
 		return
 	}
 	if post.Status == model.StatusPublished {
@@ -214,11 +329,11 @@ func (b *QQBot) handleRecall(ctx *zero.Ctx) {
 		return
 	}
 
-	if err := b.store.DeletePost(id); err != nil {
+	if err := b.store.DeletePost(post.ID); err != nil {
 		ctx.Send(message.Text("❌ 撤回失败: " + err.Error()))
 		return
 	}
-	ctx.Send(message.Text(fmt.Sprintf("✅ 稿件 #%d 已撤回", id)))
+	ctx.Send(message.Text(fmt.Sprintf("✅ 稿件 #%d 已撤回", post.ID)))
 }
 
 // handleViewPost 看稿
@@ -233,12 +348,23 @@ func (b *QQBot) handleViewPost(ctx *zero.Ctx) {
 		ctx.Send(message.Text("❌ 编号格式不正确"))
 		return
 	}
+	b.viewPost(ctx, id)
+}
+
+// viewPost 发送稿件截图
+func (b *QQBot) viewPost(ctx *zero.Ctx, id int64) {
 	post, err := b.store.GetPost(id)
 	if err != nil || post == nil {
 		ctx.Send(message.Text(fmt.Sprintf("❌ 稿件 #%d 不存在", id)))
 		return
 	}
 
+	// 已保存过截图的稿件直接发送最新版本，和发布出去的一致
+	if segs := b.savedRenders(post.ID); len(segs) > 0 {
+		ctx.Send(segs)
+		return
+	}
+
 	if b.renderer.Available() {
 		// 解析图片地址后再渲染
 		renderPost := resolvePostImages(post)
@@ -269,7 +395,11 @@ func (b *QQBot) handleApprove(ctx *zero.Ctx) {
 		ctx.Send(message.Text("❌ " + err.Error() + "\n用法: /过稿 1-4 或 /过稿 1,2,5"))
 		return
 	}
+	b.approvePosts(ctx, ids)
+}
 
+// approvePosts 通过待审核稿件，合并为一条说说立即发布
+func (b *QQBot) approvePosts(ctx *zero.Ctx, ids []int64) {
 	posts, err := b.store.GetPostsByIDs(ids)
 	if err != nil {
 		ctx.Send(message.Text("❌ 数据库查询失败: " + err.Error()))
@@ -287,111 +417,182 @@ func (b *QQBot) handleApprove(ctx *zero.Ctx) {
 		ctx.Send(message.Text("⚠️ 没有找到[待审核]的稿件，可能已处理"))
 		return
 	}
+	if !b.renderer.Available() {
+		ctx.Send(message.Text("❌ 渲染器不可用，取消发布"))
+		return
+	}
+	// 票数已满的稿件以条件更新直接改为已发布，并发的另一次过稿不会再拿到它们
+	if validPosts = b.voteApprove(ctx, validPosts, model.StatusPublished); len(validPosts) == 0 {
+		return
+	}
 
 	ctx.Send(message.Text(fmt.Sprintf("⏳ 正在处理 %d 条稿件，合并发布中...", len(validPosts))))
 
-	var summaryBuilder strings.Builder
-	summaryBuilder.WriteString(fmt.Sprintf("【表白墙更新】 %s\n", time.Now().Format("01/02")))
-	summaryBuilder.WriteString("----------------\n")
-
-	// 收集图片数据
-	var imagesData [][]byte
-
+	// 渲染截图 (内容警告稿件附带清晰原图)，按稿件记录以便发布成功后保存
+	rendered := map[int64][][]byte{}
 	for _, post := range validPosts {
-		// A. 渲染图片
-		var imgData []byte
-		var renderErr error
-
-		if b.renderer.Available() {
-			// 解析图片地址后再渲染
-			renderPost := resolvePostImages(post)
-			imgData, renderErr = b.renderer.RenderPost(renderPost)
-		}
-
-		if renderErr != nil || imgData == nil {
-			log.Printf("渲染失败 #%d: %v", post.ID, renderErr)
-			ctx.Send(message.Text(fmt.Sprintf("❌ 稿件 #%d 渲染失败，跳过", post.ID)))
-			continue
-		}
-
-		imagesData = append(imagesData, imgData)
-
-		// B. 拼接摘要
-		content := []rune(post.Text)
-		if len(content) > 20 {
-			summaryBuilder.WriteString(fmt.Sprintf("#%d: %s...\n", post.ID, string(content[:20])))
-		} else {
-			if post.Text == "" {
-				summaryBuilder.WriteString(fmt.Sprintf("#%d: [图片]\n", post.ID))
-			} else {
-				summaryBuilder.WriteString(fmt.Sprintf("#%d: %s\n", post.ID, post.Text))
+		// 解析图片地址后再渲染
+		imgData, err := b.renderer.RenderForPublish(resolvePostImages(post))
+		if err != nil || len(imgData) == 0 {
+			log.Printf("渲染失败 #%d: %v", post.ID, err)
+			ctx.Send(message.Text(fmt.Sprintf("❌ 稿件 #%d 渲染失败，已退回待审核", post.ID)))
+			post.Status = model.StatusPending
+			if err := b.store.SavePost(post); err != nil {
+				log.Printf("回滚稿件状态失败 #%d: %v", post.ID, err)
 			}
+			continue
 		}
-
-		// C. 标记为已发布
-		post.Status = model.StatusPublished
-		if err := b.store.SavePost(post); err != nil {
-			log.Printf("保存稿件状态失败 #%d: %v", post.ID, err)
-		}
+		rendered[post.ID] = imgData
 	}
 
-	if len(imagesData) == 0 {
+	// 图片超出说说上限时拆成多条发布
+	batches := render.SplitPublish(validPosts, rendered)
+	if len(batches) == 0 {
 		ctx.Send(message.Text("❌ 没有成功渲染的图片，取消发布"))
 		return
 	}
 
-	summaryBuilder.WriteString("----------------\n")
-	summaryBuilder.WriteString("详情见图 👇")
-	finalText := summaryBuilder.String()
-
+	date := time.Now().Format("01/02")
 	go func() {
-		// 修正：直接使用 ImageBytes 字段，让 qzone 库处理上传逻辑
-		opts := &qzone.PublishOption{
-			ImageBytes: imagesData,
-		}
-
-		// 调用发布接口
-		_, publishErr := b.qzClient.Publish(context.Background(), finalText, opts)
-
-		if publishErr != nil {
-			log.Printf("发布说说失败: %v", publishErr)
-			ctx.Send(message.Text("❌ 发布到空间失败: " + publishErr.Error()))
-
-			// 失败回滚
-			for _, p := range validPosts {
-				p.Status = model.StatusPending
-				if err := b.store.SavePost(p); err != nil {
-					log.Printf("回滚稿件状态失败 #%d: %v", p.ID, err)
+		for i, batch := range batches {
+			finalText := batch.Text(date, i+1, len(batches))
+			// 直接使用 ImageBytes 字段，让 qzone 库处理上传逻辑
+			opts := &qzone.PublishOption{ImageBytes: batch.Images}
+			if _, err := b.qzClient.Publish(context.Background(), finalText, opts); err != nil {
+				log.Printf("发布说说失败: %v", err)
+				ctx.Send(message.Text("❌ 发布到空间失败: " + err.Error()))
+
+				// 失败回滚本条说说中的稿件
+				for _, p := range batch.Posts {
+					p.Status = model.StatusPending
+					if err := b.store.SavePost(p); err != nil {
+						log.Printf("回滚稿件状态失败 #%d: %v", p.ID, err)
+					}
 				}
+				continue
 			}
-			return
-		}
 
-		// 发布成功：群内反馈
-		var msgSegments message.Message
-		msgSegments = append(msgSegments, message.Text("✅ 批量过稿成功！已发布到空间：\n"+finalText))
+			// 发布成功：保存实际发布的截图
+			for _, p := range batch.Posts {
+				if _, err := b.artifacts.Save(p.ID, rendered[p.ID], true); err != nil {
+					log.Printf("[QQBot] 保存稿件 #%d 发布截图失败: %v", p.ID, err)
+				}
+			}
 
-		for _, img := range imagesData {
-			b64 := base64.StdEncoding.EncodeToString(img)
-			msgSegments = append(msgSegments, message.Image("base64://"+b64))
-		}
-		ctx.Send(msgSegments)
+			// 发布成功：群内反馈
+			msgSegments := message.Message{message.Text("✅ 批量过稿成功！已发布到空间：\n" + finalText)}
+			for _, img := range batch.Images {
+				msgSegments = append(msgSegments, message.Image("base64://"+base64.StdEncoding.EncodeToString(img)))
+			}
+			ctx.Send(msgSegments)
 
-		// 通知投稿者
-		for _, p := range validPosts {
-			if p.UIN > 0 {
-				notifyMsg := fmt.Sprintf("🎉 您的投稿 #%d 已发布！", p.ID)
-				time.Sleep(500 * time.Millisecond)
-				if p.GroupID > 0 {
-					ctx.SendGroupMessage(p.GroupID, message.Text(notifyMsg))
-				} else {
-					ctx.SendPrivateMessage(p.UIN, message.Text(notifyMsg))
+			// 通知投稿者
+			for _, p := range batch.Posts {
+				if p.UIN > 0 {
+					time.Sleep(500 * time.Millisecond)
+					notifySubmitter(ctx, p, fmt.Sprintf("🎉 您的投稿 #%d 已发布！", p.ID))
 				}
 			}
 		}
 	}()
 }
 
+// savedRenders 读取稿件最新版本的截图，没有保存过时返回 nil
+func (b *QQBot) savedRenders(id int64) message.Message {
+	renders, err := b.artifacts.Latest(id)
+	if err != nil {
+		log.Printf("[QQBot] 读取稿件 #%d 截图记录失败: %v", id, err)
+		return nil
+	}
+	var segs message.Message
+	for _, r := range renders {
+		data, err := b.artifacts.Read(r.File)
+		if err != nil {
+			log.Printf("[QQBot] 读取截图 %s 失败: %v", r.File, err)
+			return nil
+		}
+		segs = append(segs, message.Image("base64://"+base64.StdEncoding.EncodeToString(data)))
+	}
+	return segs
+}
+
+// handleRerender 重新渲染稿件并保存为新版本 (不会重新发布)
+func (b *QQBot) handleRerender(ctx *zero.Ctx) {
+	args := getArgs(ctx)
+	if args == "" {
+		ctx.Send(message.Text("用法: /重新渲染 <编号>"))
+		return
+	}
+	id, err := strconv.ParseInt(args, 10, 64)
+	if err != nil {
+		ctx.Send(message.Text("❌ 编号格式不正确"))
+		return
+	}
+	post, err := b.store.GetPost(id)
+	if err != nil || post == nil {
+		ctx.Send(message.Text(fmt.Sprintf("❌ 稿件 #%d 不存在", id)))
+		return
+	}
+	if !b.renderer.Available() {
+		ctx.Send(message.Text("❌ 渲染器不可用"))
+		return
+	}
+
+	images, err := b.renderer.RenderForPublish(resolvePostImages(post))
+	if err != nil {
+		ctx.Send(message.Text("❌ 渲染失败: " + err.Error()))
+		return
+	}
+	version, err := b.artifacts.Save(post.ID, images, false)
+	if err != nil {
+		ctx.Send(message.Text("❌ 保存截图失败: " + err.Error()))
+		return
+	}
+	ctx.Send(message.Message{
+		message.Text(fmt.Sprintf("✅ 稿件 #%d 已重新渲染 (版本 %d)", id, version)),
+		message.Image("base64://" + base64.StdEncoding.EncodeToString(images[0])),
+	})
+}
+
+// handleWarning 管理员标记或取消内容警告
+func (b *QQBot) handleWarning(ctx *zero.Ctx) {
+	args := strings.Fields(getArgs(ctx))
+	if len(args) < 1 {
+		ctx.Send(message.Text("用法: /内容警告 <编号> [理由|取消]"))
+		return
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		ctx.Send(message.Text("❌ 编号格式不正确"))
+		return
+	}
+	post, err := b.store.GetPost(id)
+	if err != nil || post == nil {
+		ctx.Send(message.Text(fmt.Sprintf("❌ 稿件 #%d 不存在", id)))
+		return
+	}
+	if post.Status == model.StatusPublished {
+		ctx.Send(message.Text(fmt.Sprintf("稿件 #%d 已发布，无法修改", id)))
+		return
+	}
+
+	reason := strings.Join(args[1:], " ")
+	if reason == "取消" {
+		post.Warning, post.WarnReason = false, ""
+	} else {
+		post.Warning, post.WarnReason = true, reason
+	}
+	if err := b.store.SavePost(post); err != nil {
+		ctx.Send(message.Text("❌ 更新稿件失败: " + err.Error()))
+		return
+	}
+	if post.Warning {
+		ctx.Send(message.Text(fmt.Sprintf("⚠️ 稿件 #%d 已标记%s", id, post.WarningLabel())))
+	} else {
+		ctx.Send(message.Text(fmt.Sprintf("✅ 稿件 #%d 已取消内容警告", id)))
+	}
+}
+
 // handleReject 拒稿
 func (b *QQBot) handleReject(ctx *zero.Ctx) {
 	argsStr := getArgs(ctx)
@@ -405,27 +606,33 @@ func (b *QQBot) handleReject(ctx *zero.Ctx) {
 		ctx.Send(message.Text("❌ 编号格式不正确"))
 		return
 	}
+	b.rejectPost(ctx, id, strings.Join(args[1:], " "))
+}
+
+// rejectPost 拒绝稿件并通知投稿者
+func (b *QQBot) rejectPost(ctx *zero.Ctx, id int64, reason string) {
 	post, err := b.store.GetPost(id)
 	if err != nil || post == nil {
 		ctx.Send(message.Text(fmt.Sprintf("❌ 稿件 #%d 不存在", id)))
 		return
 	}
-	if post.Status == model.StatusPublished {
-		ctx.Send(message.Text(fmt.Sprintf("稿件 #%d 已发布，无法拒绝", id)))
+	if post.Status != model.StatusPending && post.Status != model.StatusApproved {
+		ctx.Send(message.Text(fmt.Sprintf("稿件 #%d %s，无法拒绝", id, post.Status.Text())))
 		return
 	}
 
-	reason := ""
-	if len(args) > 1 {
-		reason = strings.Join(args[1:], " ")
-	}
-
+	b.voteReject(ctx, post, reason)
 	post.Status = model.StatusRejected
 	post.Reason = reason
-	if err := b.store.SavePost(post); err != nil {
+	ok, err := b.store.SavePostIf(post, model.StatusPending, model.StatusApproved)
+	if err != nil {
 		ctx.Send(message.Text("❌ 更新稿件状态失败: " + err.Error()))
 		return
 	}
+	if !ok {
+		ctx.Send(message.Text(fmt.Sprintf("⚠️ 稿件 #%d 已被其他审核员处理", id)))
+		return
+	}
 
 	msg := fmt.Sprintf("❌ 稿件 #%d 已拒绝", id)
 	if reason != "" {
@@ -433,17 +640,11 @@ func (b *QQBot) handleReject(ctx *zero.Ctx) {
 	}
 	ctx.Send(message.Text(msg))
 
-	if post.UIN > 0 {
-		notifyMsg := fmt.Sprintf("😔 您的投稿 #%d 未通过审核", post.ID)
-		if reason != "" {
-			notifyMsg += "\n理由: " + reason
-		}
-		if post.GroupID > 0 {
-			ctx.SendGroupMessage(post.GroupID, message.Text(notifyMsg))
-		} else {
-			ctx.SendPrivateMessage(post.UIN, message.Text(notifyMsg))
-		}
+	notifyMsg := fmt.Sprintf("😔 您的投稿 #%d 未通过审核", post.ID)
+	if reason != "" {
+		notifyMsg += "\n理由: " + reason
 	}
+	notifySubmitter(ctx, post, notifyMsg)
 }
 
 // handleListPending 待审核列表
@@ -461,11 +662,98 @@ func (b *QQBot) handleListPending(ctx *zero.Ctx) {
 	sb.WriteString(fmt.Sprintf("📋 待审核稿件 (%d 件):\n\n", len(posts)))
 	for _, p := range posts {
 		sb.WriteString(p.Summary())
+		if progress := b.voteProgress(p); progress != "" {
+			sb.WriteString(progress + "\n")
+		}
 		sb.WriteString("---\n")
 	}
 	ctx.Send(message.Text(sb.String()))
 }
 
+// handleDigest 精选合集: /精选 [日|周] [发布]
+func (b *QQBot) handleDigest(ctx *zero.Ctx) {
+	kind, span, period := "每日精选", 24*time.Hour, "日"
+	publish := false
+	for _, arg := range strings.Fields(getArgs(ctx)) {
+		switch arg {
+		case "日", "day":
+			kind, span, period = "每日精选", 24*time.Hour, "日"
+		case "周", "week":
+			kind, span, period = "每周精选", 7*24*time.Hour, "周"
+		case "发布":
+			publish = true
+		default:
+			ctx.Send(message.Text("用法: /精选 [日|周] [发布]"))
+			return
+		}
+	}
+	if !b.renderer.Available() {
+		ctx.Send(message.Text("❌ 渲染器不可用"))
+		return
+	}
+
+	now := time.Now()
+	start := now.Add(-span)
+	posts, err := b.store.ListPublishedBetween(start.Unix(), now.Unix())
+	if err != nil {
+		ctx.Send(message.Text("❌ 查询失败: " + err.Error()))
+		return
+	}
+	if len(posts) == 0 {
+		ctx.Send(message.Text("📭 该时间段内没有已发布的稿件"))
+		return
+	}
+	issue, err := b.store.NextDigestIssue(kind)
+	if err != nil {
+		ctx.Send(message.Text("❌ 查询期号失败: " + err.Error()))
+		return
+	}
+
+	date := now.Format("2006/01/02")
+	if span > 24*time.Hour {
+		date = start.Format("01/02") + " - " + now.Format("01/02")
+	}
+	title := render.DigestTitle{WallName: b.wallCfg.Name, Kind: kind, Issue: issue, Date: date}
+
+	renderPosts := make([]*model.Post, len(posts))
+	for i, p := range posts {
+		renderPosts[i] = resolvePostImages(p)
+	}
+	imgData, included, err := b.renderer.RenderDigest(renderPosts, title)
+	if err != nil {
+		ctx.Send(message.Text("❌ 渲染失败: " + err.Error()))
+		return
+	}
+	// 超出长图高度的稿件不收录，计数和记录都以实际收录的为准
+	posts = posts[:len(included)]
+
+	if !publish {
+		ctx.Send(message.Message{
+			message.Text(fmt.Sprintf("📰 %s 第 %d 期预览 (%d 条)，发送 /精选 %s 发布 发布到空间\n",
+				kind, issue, len(posts), period)),
+			message.Image("base64://" + base64.StdEncoding.EncodeToString(imgData)),
+		})
+		return
+	}
+
+	text := fmt.Sprintf("【%s%s 第%d期】 %s\n共 %d 条投稿，详情见图 👇", b.wallCfg.Name, kind, issue, date, len(posts))
+	go func() {
+		_, err := b.qzClient.Publish(context.Background(), text, &qzone.PublishOption{ImageBytes: [][]byte{imgData}})
+		if err != nil {
+			ctx.Send(message.Text("❌ 发布精选失败: " + err.Error()))
+			return
+		}
+		ids := make([]int64, len(posts))
+		for i, p := range posts {
+			ids[i] = p.ID
+		}
+		if err := b.store.SaveDigest(kind, issue, ids); err != nil {
+			log.Printf("[QQBot] 记录精选失败: %v", err)
+		}
+		ctx.Send(message.Text(fmt.Sprintf("✅ %s 第 %d 期已发布", kind, issue)))
+	}()
+}
+
 // handleDirectPublish 管理员直接发说说
 func (b *QQBot) handleDirectPublish(ctx *zero.Ctx) {
 	text := getArgs(ctx)
@@ -570,17 +858,40 @@ func (b *QQBot) handleHelp(ctx *zero.Ctx) {
 
 【投稿命令】
 /投稿 <内容>       - 投稿（可附带图片）
+/投稿              - 不带内容时进入引导投稿，可分多条发送、预览后确认
+/投稿 [CW 理由] <内容> - 内容警告投稿，配图模糊显示
 /匿名投稿 <内容>   - 匿名投稿
+/聊天记录          - 逐条发送或合并转发聊天记录投稿
+/匿名聊天记录      - 匿名投稿聊天记录
 /撤稿 <编号>       - 撤回自己的稿件
+/我的投稿 [页码]   - 查看自己的投稿
+/稿件状态 <编号>   - 查看审核状态、拒稿理由和排队位置
+/修改 <编号> <新内容> - 修改待审核的稿件
+以上命令均可私聊机器人使用，私聊投稿的审核结果也只通过私聊通知
+正文支持 **粗体** *强调*，行首 > 引用、- 列表，单独一行 --- 分隔线，\* 输出星号
 
-【管理命令】（仅管理员）
+【管理命令】（审核团队）
 /待审核             - 查看待审核稿件
 /看稿 <编号>        - 查看稿件详情（截图）
+/审核团队           - 查看审核团队成员
+/封禁列表           - 查看被禁止投稿的用户
+以上观察员即可使用，以下需审核员：
+/重新渲染 <编号>    - 重新生成截图并保存为新版本
 /过稿 <编号>        - 通过并发布
 /过稿 1-4           - 批量通过 #1~#4
 /拒稿 <编号> [理由]  - 拒绝稿件
+/内容警告 <编号> [理由|取消] - 标记/取消内容警告
+/精选 [日|周] [发布] - 预览/发布精选合集长图
 /发说说 <内容>      - 直接发布到空间
-/扫码               - 扫码登录QQ空间`
+/封禁 <QQ|@某人> [时长] [理由] - 禁止投稿 (如 7d、12h，不填为永久)，并拒绝其待审核稿件
+/解封 <QQ|@某人>    - 解除封禁
+/加敏感词 <[处理方式:]词|/正则/> [分类] - 添加敏感词 (处理方式: 屏蔽/复核/打码)，立即生效
+/删敏感词 <词|/正则/> - 删除通过命令或后台添加的敏感词
+在管理群回复新投稿通知：过 / 拒 [理由] / 看 / 删
+以下仅所有者：
+/扫码               - 扫码登录QQ空间
+/添加审核 @某人 [审核员|观察员|所有者] - 添加成员或修改角色
+/移除审核 @某人     - 移出审核团队`
 	ctx.Send(message.Text(help))
 }
 
@@ -596,8 +907,13 @@ func getArgs(ctx *zero.Ctx) string {
 }
 
 func extractImages(ctx *zero.Ctx) []string {
+	return imagesOf(ctx.Event.Message)
+}
+
+// imagesOf 提取消息中的图片 (URL 或 file ID)
+func imagesOf(msg message.Message) []string {
 	var images []string
-	for _, seg := range ctx.Event.Message {
+	for _, seg := range msg {
 		if seg.Type == "image" {
 			u := seg.Data["url"]
 			f := seg.Data["file"]
@@ -616,6 +932,165 @@ func extractImages(ctx *zero.Ctx) []string {
 	return images
 }
 
+// moveReplyToEnd 将首个 reply 段挪到消息末尾，并去掉 QQ 客户端回复时自动附带的 @
+func moveReplyToEnd(ctx *zero.Ctx) bool {
+	msg := ctx.Event.Message
+	if len(msg) == 0 || msg[0].Type != "reply" {
+		return true
+	}
+	reply := msg[0]
+	rest := msg[1:]
+	for len(rest) > 0 && rest[0].Type == "at" {
+		rest = rest[1:]
+	}
+	if len(rest) > 0 && rest[0].Type == "text" {
+		rest[0].Data["text"] = strings.TrimLeft(rest[0].Data["text"], " ")
+	}
+	out := make(message.Message, 0, len(rest)+1)
+	out = append(out, rest...)
+	ctx.Event.Message = append(out, reply)
+	return true
+}
+
+// extractSegments 提取命令参数中的文字、表情、@ 和回复引用 (图片由 extractImages 处理)
+func extractSegments(ctx *zero.Ctx) []model.Segment {
+	msg := ctx.Event.Message
+	if len(msg) > 0 && msg[0].Type == "text" {
+		// 去掉命令本身 (复制一份，不修改原消息)
+		text := strings.TrimPrefix(msg[0].Data["text"], zero.BotConfig.CommandPrefix)
+		if cmd, ok := ctx.State["command"].(string); ok {
+			text = strings.TrimPrefix(text, cmd)
+		}
+		msg = append(message.Message{message.Text(strings.TrimLeft(text, " "))}, msg[1:]...)
+	}
+	return trimSegments(toSegments(ctx, msg))
+}
+
+// toSegments 将 OneBot 消息转为投稿消息段，相邻文字合并
+func toSegments(ctx *zero.Ctx, msg message.Message) []model.Segment {
+	var segs []model.Segment
+	addText := func(text string) {
+		if text == "" {
+			return
+		}
+		if n := len(segs); n > 0 && segs[n-1].Type == model.SegText {
+			segs[n-1].Text += text
+			return
+		}
+		segs = append(segs, model.Segment{Type: model.SegText, Text: text})
+	}
+
+	for _, seg := range msg {
+		switch seg.Type {
+		case "text":
+			addText(seg.Data["text"])
+		case "face":
+			id := seg.Data["id"]
+			segs = append(segs, model.Segment{Type: model.SegFace, ID: id, Name: faces.Name(id)})
+		case "at":
+			qq := seg.Data["qq"]
+			name := seg.Data["name"]
+			if qq == "all" {
+				name = "全体成员"
+			} else if name == "" {
+				if uid, err := strconv.ParseInt(qq, 10, 64); err == nil {
+					name = ctx.CardOrNickName(uid)
+				}
+			}
+			if name == "" {
+				name = qq
+			}
+			segs = append(segs, model.Segment{Type: model.SegAt, ID: qq, Text: strings.TrimPrefix(name, "@")})
+		case "reply":
+			segs = append(segs, replySegment(ctx, seg.Data["id"]))
+		case "forward":
+			addText("[聊天记录]")
+		}
+	}
+	return segs
+}
+
+// applyWarningTag 识别开头的内容警告标记 ([CW 理由])，标记到稿件上并从消息段中去掉
+func applyWarningTag(post *model.Post, segs []model.Segment) []model.Segment {
+	for i := range segs {
+		if segs[i].Type == model.SegReply {
+			continue
+		}
+		if segs[i].Type != model.SegText {
+			break
+		}
+		rest, ok, reason := model.ParseWarningTag(segs[i].Text)
+		if !ok {
+			break
+		}
+		post.Warning, post.WarnReason = true, reason
+		segs[i].Text = rest
+		return trimSegments(segs)
+	}
+	return segs
+}
+
+// trimSegments 去掉首尾文字段的空白
+func trimSegments(segs []model.Segment) []model.Segment {
+	for i := range segs {
+		if segs[i].Type == model.SegText {
+			segs[i].Text = strings.TrimLeft(segs[i].Text, " \t\r\n")
+			break
+		}
+		if segs[i].Type != model.SegReply {
+			break
+		}
+	}
+	for i := len(segs) - 1; i >= 0; i-- {
+		if segs[i].Type == model.SegText {
+			segs[i].Text = strings.TrimRight(segs[i].Text, " \t\r\n")
+			break
+		}
+		if segs[i].Type != model.SegReply {
+			break
+		}
+	}
+	out := segs[:0]
+	for _, seg := range segs {
+		if seg.Type != model.SegText || seg.Text != "" {
+			out = append(out, seg)
+		}
+	}
+	return out
+}
+
+// replySegment 获取被回复消息的发送者与文字
+func replySegment(ctx *zero.Ctx, id string) model.Segment {
+	seg := model.Segment{Type: model.SegReply, ID: id}
+	msg := ctx.GetMessage(id, true)
+	if msg.Sender != nil {
+		seg.Name = msg.Sender.Name()
+	}
+	seg.Text = strings.TrimSpace(msg.Elements.ExtractPlainText())
+	if seg.Text == "" {
+		for _, e := range msg.Elements {
+			if e.Type == "image" {
+				seg.Text = "[图片]"
+				break
+			}
+		}
+	}
+	if runes := []rune(seg.Text); len(runes) > 100 {
+		seg.Text = string(runes[:100]) + "…"
+	}
+	return seg
+}
+
+// hasRichSegment 是否包含纯文字以外的消息段
+func hasRichSegment(segs []model.Segment) bool {
+	for _, seg := range segs {
+		if seg.Type != model.SegText {
+			return true
+		}
+	}
+	return false
+}
+
 func parseIDs(s string) ([]int64, error) {
 	s = strings.TrimSpace(s)
 	if s == "" {
@@ -675,6 +1150,24 @@ func resolveImageURL(img string) string {
 	return img
 }
 
+// ImageSource 先解析 file ID 再加载图片，供投稿校验计算图片指纹
+type ImageSource struct {
+	*render.HTTPImageSource
+}
+
+// NewImageSource 创建图片源
+func NewImageSource() *ImageSource {
+	return &ImageSource{HTTPImageSource: render.NewHTTPImageSource()}
+}
+
+// Load 加载并解码图片；网页上传的图片 (/uploads/... 或预览时的临时文件) 直接读取本地文件
+func (s *ImageSource) Load(img string) (image.Image, error) {
+	if !strings.HasPrefix(img, "/") && !filepath.IsAbs(img) {
+		img = resolveImageURL(img)
+	}
+	return s.HTTPImageSource.Load(img)
+}
+
 // resolvePostImages 克隆 Post 并解析所有图片 URL (仅用于渲染，不保存回DB)
 func resolvePostImages(p *model.Post) *model.Post {
 	clone := *p
@@ -0,0 +1,60 @@
+package source
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/quota"
+	zero "github.com/wdvxdr1123/ZeroBot"
+	"github.com/wdvxdr1123/ZeroBot/message"
+)
+
+// quotaRules 发送者的投稿限流规则 (wall.rate_limit)，审核团队成员不受限
+func (b *QQBot) quotaRules(ctx *zero.Ctx) []quota.Rule {
+	if b.roleOf(ctx).IsStaff() {
+		return nil
+	}
+	group := ""
+	if ctx.Event.GroupID != 0 {
+		group = fmt.Sprintf("group:%d", ctx.Event.GroupID)
+	}
+	return quota.Rules(b.wallCfg.RateLimit, fmt.Sprintf("qq:%d", ctx.Event.UserID), group, "")
+}
+
+// checkQuota 开始投稿时提前检查频率限制，超出时回复何时可以再投并返回 false
+func (b *QQBot) checkQuota(ctx *zero.Ctx) bool {
+	now := time.Now()
+	ex, err := b.limiter.Check(b.quotaRules(ctx), now)
+	if err != nil {
+		log.Printf("[QQBot] 检查投稿频率失败: %v", err)
+		return true
+	}
+	if ex == nil {
+		return true
+	}
+	ctx.Send(message.Text("⏳ " + ex.Message(now)))
+	return false
+}
+
+// takeQuota 保存投稿前检查并计入频率限制 (检查与计数不可分割，并发投稿不会同时通过)；
+// 超出时回复何时可以再投并返回 false
+func (b *QQBot) takeQuota(ctx *zero.Ctx, now time.Time) bool {
+	ex, err := b.limiter.Take(b.quotaRules(ctx), now)
+	if err != nil {
+		log.Printf("[QQBot] 记录投稿频率失败: %v", err)
+		return true
+	}
+	if ex == nil {
+		return true
+	}
+	ctx.Send(message.Text("⏳ " + ex.Message(now)))
+	return false
+}
+
+// releaseQuota 投稿保存失败时撤销 takeQuota 的计数
+func (b *QQBot) releaseQuota(ctx *zero.Ctx, now time.Time) {
+	if err := b.limiter.Release(b.quotaRules(ctx), now); err != nil {
+		log.Printf("[QQBot] 撤销投稿频率记录失败: %v", err)
+	}
+}
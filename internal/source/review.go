@@ -0,0 +1,162 @@
+package source
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	zero "github.com/wdvxdr1123/ZeroBot"
+	"github.com/wdvxdr1123/ZeroBot/message"
+)
+
+// ──────────────────────────────────────────
+// 管理群通知与回复审核
+//
+//   新投稿通知附带截图预览，并记录通知的消息 ID；
+//   管理员在管理群回复该通知即可审核：过 / 拒 [理由] / 看 / 删
+// ──────────────────────────────────────────
+
+// reviewAction 回复通知时的审核操作
+type reviewAction string
+
+const (
+	reviewApprove reviewAction = "过"
+	reviewReject  reviewAction = "拒"
+	reviewView    reviewAction = "看"
+	reviewDelete  reviewAction = "删"
+)
+
+// parseReviewReply 解析回复内容。过、看、删 需单独发送；拒 后面可跟理由 (空格或冒号分隔)
+func parseReviewReply(text string) (reviewAction, string, bool) {
+	text = strings.TrimSpace(text)
+	switch reviewAction(text) {
+	case reviewApprove, reviewView, reviewDelete, reviewReject:
+		return reviewAction(text), "", true
+	}
+	first, size := utf8.DecodeRuneInString(text)
+	if reviewAction(first) != reviewReject {
+		return "", "", false
+	}
+	rest := text[size:]
+	trimmed := strings.TrimLeft(rest, " :：")
+	if trimmed == rest {
+		// "拒绝"、"拒了" 之类的普通聊天不算
+		return "", "", false
+	}
+	return reviewReject, strings.TrimSpace(trimmed), true
+}
+
+// notifyManage 向管理群发送稿件通知 (截图预览 + 摘要)，并记录消息 ID 供回复审核
+func (b *QQBot) notifyManage(ctx *zero.Ctx, post *model.Post, header string) {
+	if b.botCfg.ManageGroup <= 0 {
+		return
+	}
+	if flags := post.FlagText(); flags != "" {
+		header += "\n⚑ " + flags
+	}
+	msg := message.Message{message.Text(header + "\n" + post.Summary())}
+	if b.renderer.Available() {
+		if data, err := b.renderer.RenderPost(resolvePostImages(post)); err == nil {
+			msg = message.Message{
+				message.Text(header + "\n"),
+				message.Image("base64://" + base64.StdEncoding.EncodeToString(data)),
+			}
+		} else {
+			log.Printf("[QQBot] 通知预览渲染失败 #%d: %v", post.ID, err)
+		}
+	}
+	footer := "\n回复本消息：过 / 拒 [理由] / 看 / 删"
+	if q := b.wallCfg.ApproveQuorum; q > 1 {
+		footer += fmt.Sprintf("\n🗳 需 %d 名审核员同意，任一人拒绝即否决", q)
+	}
+	msg = append(msg, message.Text(footer))
+
+	id := ctx.SendGroupMessage(b.botCfg.ManageGroup, msg)
+	if id == 0 {
+		return
+	}
+	if err := b.store.SaveNotice(id, post.ID); err != nil {
+		log.Printf("[QQBot] 记录通知消息失败 #%d: %v", post.ID, err)
+	}
+}
+
+// replyTarget 返回消息中回复的消息 ID
+func replyTarget(msg message.Message) int64 {
+	for _, seg := range msg {
+		if seg.Type == "reply" {
+			id, _ := strconv.ParseInt(seg.Data["id"], 10, 64)
+			return id
+		}
+	}
+	return 0
+}
+
+// perm 执行该操作所需的权限
+func (a reviewAction) perm() model.Permission {
+	if a == reviewView {
+		return model.PermView
+	}
+	return model.PermReview
+}
+
+// isReviewReply 管理群中回复新投稿通知的审核消息，解析结果存入 ctx.State
+func (b *QQBot) isReviewReply(ctx *zero.Ctx) bool {
+	if b.botCfg.ManageGroup <= 0 || ctx.Event.GroupID != b.botCfg.ManageGroup {
+		return false
+	}
+	target := replyTarget(ctx.Event.Message)
+	if target == 0 {
+		return false
+	}
+	action, reason, ok := parseReviewReply(ctx.Event.Message.ExtractPlainText())
+	if !ok {
+		return false
+	}
+	postID, err := b.store.NoticePost(target)
+	if err != nil || postID == 0 {
+		return false
+	}
+	ctx.State["review_post"] = postID
+	ctx.State["review_action"] = action
+	ctx.State["review_reason"] = reason
+	return true
+}
+
+// handleReviewReply 执行回复通知的审核操作
+func (b *QQBot) handleReviewReply(ctx *zero.Ctx) {
+	id, _ := ctx.State["review_post"].(int64)
+	action, _ := ctx.State["review_action"].(reviewAction)
+	reason, _ := ctx.State["review_reason"].(string)
+	if !b.roleOf(ctx).Can(action.perm()) {
+		ctx.Send(message.Text("❌ 观察员只能查看稿件"))
+		return
+	}
+
+	switch action {
+	case reviewApprove:
+		b.approvePosts(ctx, []int64{id})
+	case reviewReject:
+		b.rejectPost(ctx, id, reason)
+	case reviewView:
+		b.viewPost(ctx, id)
+	case reviewDelete:
+		post, err := b.store.GetPost(id)
+		if err != nil || post == nil {
+			ctx.Send(message.Text(fmt.Sprintf("❌ 稿件 #%d 不存在", id)))
+			return
+		}
+		if post.Status == model.StatusPublished {
+			ctx.Send(message.Text(fmt.Sprintf("稿件 #%d 已发布，无法删除", id)))
+			return
+		}
+		if err := b.store.DeletePost(id); err != nil {
+			ctx.Send(message.Text("❌ 删除失败: " + err.Error()))
+			return
+		}
+		ctx.Send(message.Text(fmt.Sprintf("🗑 稿件 #%d 已删除", id)))
+	}
+}
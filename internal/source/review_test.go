@@ -0,0 +1,31 @@
+package source
+
+import "testing"
+
+func TestParseReviewReply(t *testing.T) {
+	tests := []struct {
+		text   string
+		action reviewAction
+		reason string
+		ok     bool
+	}{
+		{"过", reviewApprove, "", true},
+		{" 看 ", reviewView, "", true},
+		{"删", reviewDelete, "", true},
+		{"拒", reviewReject, "", true},
+		{"拒 广告", reviewReject, "广告", true},
+		{"拒：内容不实", reviewReject, "内容不实", true},
+		{"拒:", reviewReject, "", true},
+		{"拒绝", "", "", false},
+		{"过分了", "", "", false},
+		{"看看", "", "", false},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		action, reason, ok := parseReviewReply(tt.text)
+		if action != tt.action || reason != tt.reason || ok != tt.ok {
+			t.Errorf("parseReviewReply(%q) = %q, %q, %v; want %q, %q, %v",
+				tt.text, action, reason, ok, tt.action, tt.reason, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package source
+
+import (
+	"encoding/base64"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	zero "github.com/wdvxdr1123/ZeroBot"
+	"github.com/wdvxdr1123/ZeroBot/message"
+)
+
+// ──────────────────────────────────────────
+// 引导式投稿
+//
+//   /投稿 未附带内容时进入对话：
+//   收集正文和图片 → 询问是否匿名 (/匿名投稿 跳过) → 发送预览 → 确认提交
+// ──────────────────────────────────────────
+
+const (
+	sessionStepTimeout = 3 * time.Minute  // 每一步等待回复的最长时间
+	sessionQuietWindow = 30 * time.Second // 已收到内容后，超过此时间没有新消息自动进入下一步
+)
+
+// sessionLocks 记录进行中的对话 (引导投稿、聊天记录收集)，每个用户同时只能有一个
+type sessionLocks struct {
+	mu    sync.Mutex
+	users map[int64]bool
+}
+
+// acquire 为用户加锁，已有进行中的对话时返回 false
+func (l *sessionLocks) acquire(uid int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.users[uid] {
+		return false
+	}
+	if l.users == nil {
+		l.users = make(map[int64]bool)
+	}
+	l.users[uid] = true
+	return true
+}
+
+func (l *sessionLocks) release(uid int64) {
+	l.mu.Lock()
+	delete(l.users, uid)
+	l.mu.Unlock()
+}
+
+// session 一次对话，recv 接收该用户在同一会话 (群或私聊) 中的后续消息
+type session struct {
+	ctx  *zero.Ctx
+	recv <-chan *zero.Ctx
+}
+
+// listen 监听发起者后续的消息，返回的 cancel 用于结束监听
+func listen(ctx *zero.Ctx) (*session, func()) {
+	uid, gid := ctx.Event.UserID, ctx.Event.GroupID
+	recv, cancel := zero.NewFutureEvent("message", 999, true, func(c *zero.Ctx) bool {
+		return c.Event.UserID == uid && c.Event.GroupID == gid
+	}).Repeat()
+	return &session{ctx: ctx, recv: recv}, cancel
+}
+
+// next 等待下一条消息 (跳过其他命令)，超时返回 nil
+func (s *session) next(timeout time.Duration) *zero.Ctx {
+	deadline := time.After(timeout)
+	for {
+		select {
+		case c, ok := <-s.recv:
+			if !ok {
+				return nil
+			}
+			if isCommand(c) {
+				continue
+			}
+			return c
+		case <-deadline:
+			return nil
+		}
+	}
+}
+
+// isCommand 消息是否为命令 (由对应的命令处理，不计入对话内容)
+func isCommand(c *zero.Ctx) bool {
+	prefix := zero.BotConfig.CommandPrefix
+	return prefix != "" && strings.HasPrefix(strings.TrimSpace(c.Event.Message.ExtractPlainText()), prefix)
+}
+
+// replyText 消息的纯文字内容，用于识别「确认」「取消」等回复
+func replyText(c *zero.Ctx) string {
+	return strings.TrimSpace(c.Event.Message.ExtractPlainText())
+}
+
+// startSession 引导式投稿。post 已带有命令中的内容警告标记；askAnon 为 false 时不询问是否匿名
+func (b *QQBot) startSession(ctx *zero.Ctx, post *model.Post, askAnon bool) {
+	uid := ctx.Event.UserID
+	if !b.sessions.acquire(uid) {
+		ctx.Send(message.Text("⚠️ 你还有进行中的投稿，请先完成或发送「取消」"))
+		return
+	}
+	ctx.Send(message.Text("📝 请发送投稿内容，文字和图片可以分多条发送\n发送「完成」进入下一步，「取消」放弃"))
+
+	s, cancel := listen(ctx)
+	go func() {
+		defer b.sessions.release(uid)
+		defer cancel()
+
+		if !b.collectContent(s, post) {
+			return
+		}
+		if askAnon && !s.askAnon(post) {
+			return
+		}
+		b.previewAndConfirm(s, post)
+	}()
+}
+
+// collectContent 收集正文和图片，直到用户发送「完成」或静默超过 sessionQuietWindow
+func (b *QQBot) collectContent(s *session, post *model.Post) bool {
+	var (
+		segs   []model.Segment
+		images []string
+	)
+	for {
+		wait := sessionStepTimeout
+		if len(segs) > 0 || len(images) > 0 {
+			wait = sessionQuietWindow
+		}
+		c := s.next(wait)
+		if c == nil {
+			if len(segs) == 0 && len(images) == 0 {
+				s.ctx.Send(message.Text("⌛ 投稿超时，已取消"))
+				return false
+			}
+			break
+		}
+
+		switch replyText(c) {
+		case "取消":
+			s.ctx.Send(message.Text("已取消投稿"))
+			return false
+		case "完成":
+			if len(segs) == 0 && len(images) == 0 {
+				s.ctx.Send(message.Text("还没有收到内容，请发送文字或图片"))
+				continue
+			}
+		default:
+			// 合并转发按聊天记录投稿，直接进入下一步
+			if id := findForward(c.Event.Message); id != "" {
+				post.Chat, post.Images = chatFromForward(c, id)
+				if len(post.Chat) == 0 {
+					s.ctx.Send(message.Text("❌ 没有读取到聊天记录内容，已取消投稿"))
+					return false
+				}
+				post.Text = model.ChatText(post.Chat)
+				return true
+			}
+			msgSegs := trimSegments(toSegments(c, c.Event.Message))
+			if len(msgSegs) > 0 {
+				if len(segs) > 0 {
+					segs = append(segs, model.Segment{Type: model.SegText, Text: "\n"})
+				}
+				segs = append(segs, msgSegs...)
+			}
+			images = append(images, imagesOf(c.Event.Message)...)
+			continue
+		}
+		break
+	}
+
+	segs = applyWarningTag(post, mergeText(segs))
+	post.Text = strings.TrimSpace(model.PlainText(segs))
+	post.Images = images
+	if hasRichSegment(segs) {
+		post.Segments = segs
+	}
+	return true
+}
+
+// mergeText 合并相邻的文字段 (多条消息之间插入的换行与正文合为一段)
+func mergeText(segs []model.Segment) []model.Segment {
+	var out []model.Segment
+	for _, seg := range segs {
+		if n := len(out); n > 0 && seg.Type == model.SegText && out[n-1].Type == model.SegText {
+			out[n-1].Text += seg.Text
+			continue
+		}
+		out = append(out, seg)
+	}
+	return out
+}
+
+// askAnon 询问是否匿名
+func (s *session) askAnon(post *model.Post) bool {
+	s.ctx.Send(message.Text("是否匿名投稿？回复「是」或「否」"))
+	for {
+		c := s.next(sessionStepTimeout)
+		if c == nil {
+			s.ctx.Send(message.Text("⌛ 投稿超时，已取消"))
+			return false
+		}
+		switch replyText(c) {
+		case "是", "匿名":
+			post.Anon = true
+			return true
+		case "否", "不匿名", "实名":
+			post.Anon = false
+			return true
+		case "取消":
+			s.ctx.Send(message.Text("已取消投稿"))
+			return false
+		}
+		s.ctx.Send(message.Text("请回复「是」或「否」，发送「取消」放弃"))
+	}
+}
+
+// previewAndConfirm 校验后发送渲染预览，等待「确认」后提交
+func (b *QQBot) previewAndConfirm(s *session, post *model.Post) {
+	if msg := b.checkPost(post); msg != "" {
+		s.ctx.Send(message.Text(msg))
+		return
+	}
+	b.stampPost(s.ctx, post)
+
+	prompt := "👀 预览如上，回复「确认」提交，「取消」放弃"
+	if b.renderer.Available() {
+		if data, err := b.renderer.RenderPost(resolvePostImages(post)); err == nil {
+			s.ctx.Send(message.Image("base64://" + base64.StdEncoding.EncodeToString(data)))
+		} else {
+			prompt = "⚠️ 预览生成失败，回复「确认」仍可提交，「取消」放弃"
+		}
+	} else {
+		prompt = "回复「确认」提交，「取消」放弃\n" + post.Summary()
+	}
+	s.ctx.Send(message.Text(prompt))
+
+	for {
+		c := s.next(sessionStepTimeout)
+		if c == nil {
+			s.ctx.Send(message.Text("⌛ 投稿超时，已取消"))
+			return
+		}
+		switch replyText(c) {
+		case "确认", "确定":
+			b.submitPost(s.ctx, post)
+			return
+		case "取消":
+			s.ctx.Send(message.Text("已取消投稿"))
+			return
+		}
+		s.ctx.Send(message.Text("请回复「确认」或「取消」"))
+	}
+}
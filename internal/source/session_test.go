@@ -0,0 +1,38 @@
+package source
+
+import (
+	"testing"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+)
+
+func TestSessionLocks(t *testing.T) {
+	var l sessionLocks
+	if !l.acquire(10001) {
+		t.Fatal("first acquire should succeed")
+	}
+	if l.acquire(10001) {
+		t.Error("second acquire by the same user should fail")
+	}
+	if !l.acquire(10002) {
+		t.Error("other users should not be blocked")
+	}
+	l.release(10001)
+	if !l.acquire(10001) {
+		t.Error("acquire after release should succeed")
+	}
+}
+
+func TestMergeText(t *testing.T) {
+	segs := mergeText([]model.Segment{
+		{Type: model.SegText, Text: "第一条"},
+		{Type: model.SegText, Text: "\n"},
+		{Type: model.SegText, Text: "第二条"},
+		{Type: model.SegFace, ID: "14"},
+		{Type: model.SegText, Text: "\n"},
+		{Type: model.SegText, Text: "第三条"},
+	})
+	if len(segs) != 3 || segs[0].Text != "第一条\n第二条" || segs[2].Text != "\n第三条" {
+		t.Errorf("unexpected segments: %+v", segs)
+	}
+}
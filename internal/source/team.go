@@ -0,0 +1,179 @@
+package source
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	zero "github.com/wdvxdr1123/ZeroBot"
+	"github.com/wdvxdr1123/ZeroBot/message"
+)
+
+// ──────────────────────────────────────────
+// 审核团队
+//
+//   角色来源 (按优先级)：
+//   bot.zero.super_users → 所有者；数据库 moderators 表；
+//   开启 bot.manage_admins 时管理群的群主/管理员 → 审核员
+// ──────────────────────────────────────────
+
+// roleOf 消息发送者的角色
+func (b *QQBot) roleOf(ctx *zero.Ctx) model.Role {
+	return b.roleOfUser(ctx, ctx.Event.UserID)
+}
+
+// roleOfUser 查询指定 QQ 的角色
+func (b *QQBot) roleOfUser(ctx *zero.Ctx, uid int64) model.Role {
+	if slices.Contains(b.botCfg.Zero.SuperUsers, uid) {
+		return model.RoleOwner
+	}
+	m, err := b.store.GetModerator(uid)
+	if err != nil {
+		log.Printf("[QQBot] 查询审核员 %d 失败: %v", uid, err)
+	}
+	if m != nil {
+		return m.Role
+	}
+	if b.botCfg.ManageAdmins && b.isManageAdmin(ctx, uid) {
+		return model.RoleModerator
+	}
+	return model.RoleUser
+}
+
+// isManageAdmin 用户是否为管理群的群主或管理员
+func (b *QQBot) isManageAdmin(ctx *zero.Ctx, uid int64) bool {
+	if b.botCfg.ManageGroup <= 0 {
+		return false
+	}
+	now := time.Now()
+	if ok, found := b.admins.get(uid, now); found {
+		return ok
+	}
+	role := ctx.GetGroupMemberInfo(b.botCfg.ManageGroup, uid, false).Get("role").String()
+	ok := role == "owner" || role == "admin"
+	b.admins.put(uid, ok, now)
+	return ok
+}
+
+// allow 命令权限规则：发送者的角色需拥有权限 p
+func (b *QQBot) allow(p model.Permission) zero.Rule {
+	return func(ctx *zero.Ctx) bool {
+		return b.roleOf(ctx).Can(p)
+	}
+}
+
+// parseMemberArgs 解析 /添加审核 /移除审核 的参数：目标 QQ (@ 或 QQ 号) 与其余文字
+func parseMemberArgs(msg message.Message, prefix, cmd string) (int64, string) {
+	var (
+		uin  int64
+		rest []string
+	)
+	for i, seg := range msg {
+		switch seg.Type {
+		case "at":
+			if uin == 0 {
+				uin, _ = strconv.ParseInt(seg.Data["qq"], 10, 64)
+			}
+		case "text":
+			text := seg.Data["text"]
+			if i == 0 {
+				text = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(text), prefix), cmd)
+			}
+			rest = append(rest, strings.Fields(text)...)
+		}
+	}
+	if uin == 0 && len(rest) > 0 {
+		if n, err := strconv.ParseInt(rest[0], 10, 64); err == nil && n > 0 {
+			uin, rest = n, rest[1:]
+		}
+	}
+	return uin, strings.Join(rest, " ")
+}
+
+// memberArgs 解析当前命令的目标 QQ 与其余文字
+func memberArgs(ctx *zero.Ctx) (int64, string) {
+	cmd, _ := ctx.State["command"].(string)
+	return parseMemberArgs(ctx.Event.Message, zero.BotConfig.CommandPrefix, cmd)
+}
+
+// handleAddModerator /添加审核 @某人 [审核员|观察员|所有者]
+func (b *QQBot) handleAddModerator(ctx *zero.Ctx) {
+	uin, rest := memberArgs(ctx)
+	if uin == 0 {
+		ctx.Send(message.Text("用法: /添加审核 @某人 [审核员|观察员|所有者]"))
+		return
+	}
+	role := model.RoleModerator
+	if rest != "" {
+		r, ok := model.ParseRole(rest)
+		if !ok || !r.IsStaff() {
+			ctx.Send(message.Text("❌ 角色只能是 审核员、观察员 或 所有者"))
+			return
+		}
+		role = r
+	}
+	if slices.Contains(b.botCfg.Zero.SuperUsers, uin) {
+		ctx.Send(message.Text(fmt.Sprintf("%d 是配置文件中的超级用户，角色固定为所有者", uin)))
+		return
+	}
+
+	if err := b.store.SetModerator(&model.Moderator{UIN: uin, Role: role, AddedBy: ctx.Event.UserID}); err != nil {
+		ctx.Send(message.Text("❌ 保存失败: " + err.Error()))
+		return
+	}
+	log.Printf("[QQBot] %d 将 %d 设为%s", ctx.Event.UserID, uin, role.Text())
+	ctx.Send(message.Text(fmt.Sprintf("✅ 已将 %d 设为%s", uin, role.Text())))
+}
+
+// handleRemoveModerator /移除审核 @某人
+func (b *QQBot) handleRemoveModerator(ctx *zero.Ctx) {
+	uin, _ := memberArgs(ctx)
+	if uin == 0 {
+		ctx.Send(message.Text("用法: /移除审核 @某人"))
+		return
+	}
+	if slices.Contains(b.botCfg.Zero.SuperUsers, uin) {
+		ctx.Send(message.Text(fmt.Sprintf("%d 是配置文件中的超级用户，请修改 bot.zero.super_users", uin)))
+		return
+	}
+	ok, err := b.store.RemoveModerator(uin)
+	if err != nil {
+		ctx.Send(message.Text("❌ 移除失败: " + err.Error()))
+		return
+	}
+	if !ok {
+		ctx.Send(message.Text(fmt.Sprintf("%d 不在审核团队中", uin)))
+		return
+	}
+	log.Printf("[QQBot] %d 移除了审核员 %d", ctx.Event.UserID, uin)
+	msg := fmt.Sprintf("✅ 已将 %d 移出审核团队", uin)
+	if b.botCfg.ManageAdmins && b.isManageAdmin(ctx, uin) {
+		msg += "\n⚠️ 对方是管理群管理员，仍会自动视为审核员"
+	}
+	ctx.Send(message.Text(msg))
+}
+
+// handleListTeam /审核团队
+func (b *QQBot) handleListTeam(ctx *zero.Ctx) {
+	var sb strings.Builder
+	sb.WriteString("👥 审核团队\n")
+	for _, uin := range b.botCfg.Zero.SuperUsers {
+		fmt.Fprintf(&sb, "\n%d - %s (配置文件)", uin, model.RoleOwner.Text())
+	}
+	members, err := b.store.ListModerators()
+	if err != nil {
+		ctx.Send(message.Text("❌ 查询失败: " + err.Error()))
+		return
+	}
+	for _, m := range members {
+		fmt.Fprintf(&sb, "\n%d - %s", m.UIN, m.Role.Text())
+	}
+	if b.botCfg.ManageAdmins && b.botCfg.ManageGroup > 0 {
+		sb.WriteString("\n\n管理群的群主和管理员自动视为审核员")
+	}
+	ctx.Send(message.Text(sb.String()))
+}
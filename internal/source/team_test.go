@@ -0,0 +1,46 @@
+package source
+
+import (
+	"testing"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	"github.com/wdvxdr1123/ZeroBot/message"
+)
+
+func TestParseMemberArgs(t *testing.T) {
+	cases := []struct {
+		cmd  string
+		msg  message.Message
+		uin  int64
+		rest string
+	}{
+		{"添加审核", message.Message{message.Text("/添加审核 "), message.At(10001), message.Text(" 观察员")}, 10001, "观察员"},
+		{"添加审核", message.Message{message.Text("/添加审核 10002 审核员")}, 10002, "审核员"},
+		{"移除审核", message.Message{message.Text("/移除审核"), message.At(10003)}, 10003, ""},
+		{"移除审核", message.Message{message.Text("/移除审核 某人")}, 0, "某人"},
+	}
+	for _, c := range cases {
+		uin, rest := parseMemberArgs(c.msg, "/", c.cmd)
+		if uin != c.uin || rest != c.rest {
+			t.Errorf("%v: got (%d, %q), want (%d, %q)", c.msg, uin, rest, c.uin, c.rest)
+		}
+	}
+}
+
+func TestRolePermissions(t *testing.T) {
+	if !model.RoleOwner.Can(model.PermTeam) || model.RoleModerator.Can(model.PermTeam) {
+		t.Error("only owners may manage the team")
+	}
+	if !model.RoleModerator.Can(model.PermReview) || model.RoleViewer.Can(model.PermReview) {
+		t.Error("moderators review, viewers do not")
+	}
+	if !model.RoleViewer.Can(model.PermView) || model.RoleUser.IsStaff() {
+		t.Error("viewers are staff, users are not")
+	}
+	if r, ok := model.ParseRole("审核员"); !ok || r != model.RoleModerator {
+		t.Errorf("ParseRole(审核员) = %q, %v", r, ok)
+	}
+	if _, ok := model.ParseRole("admin"); ok {
+		t.Error("legacy admin role should not parse")
+	}
+}
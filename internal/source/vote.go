@@ -0,0 +1,82 @@
+package source
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	zero "github.com/wdvxdr1123/ZeroBot"
+	"github.com/wdvxdr1123/ZeroBot/message"
+)
+
+// ──────────────────────────────────────────
+// 多人审核：稿件需 wall.approve_quorum 名审核员同意才通过，
+// 任一人拒绝即否决，所有者同意时直接通过
+// ──────────────────────────────────────────
+
+// voteApprove 为待审核稿件记录发送者的同意票，返回可以通过的稿件；票数不足的回复投票进度。
+// 可以通过的稿件以条件更新从待审核改为 to，已被其他审核员处理的稿件不会返回，
+// 防止并发的同意票把同一稿件发布两次
+func (b *QQBot) voteApprove(ctx *zero.Ctx, posts []*model.Post, to model.PostStatus) []*model.Post {
+	owner := b.roleOf(ctx) == model.RoleOwner
+	voter := model.QQVoter(ctx.Event.UserID)
+
+	var (
+		passed  []*model.Post
+		waiting strings.Builder
+		handled []string
+	)
+	for _, p := range posts {
+		tally, err := b.store.CastVote(&model.Vote{PostID: p.ID, Voter: voter, Approve: true}, b.wallCfg.ApproveQuorum)
+		if err != nil {
+			log.Printf("[QQBot] 记录稿件 #%d 投票失败: %v", p.ID, err)
+			fmt.Fprintf(&waiting, "\n#%d 投票记录失败: %v", p.ID, err)
+			continue
+		}
+		if !owner && !tally.Passed() {
+			fmt.Fprintf(&waiting, "\n#%d %s，还需 %d 人", p.ID, tally.Text(), tally.Remaining())
+			continue
+		}
+		p.Status = to
+		ok, err := b.store.SavePostIf(p, model.StatusPending)
+		if err != nil {
+			log.Printf("[QQBot] 更新稿件 #%d 状态失败: %v", p.ID, err)
+			p.Status = model.StatusPending
+			fmt.Fprintf(&waiting, "\n#%d 更新状态失败: %v", p.ID, err)
+			continue
+		}
+		if !ok {
+			handled = append(handled, fmt.Sprintf("#%d", p.ID))
+			continue
+		}
+		passed = append(passed, p)
+	}
+	if waiting.Len() > 0 {
+		ctx.Send(message.Text("🗳 已记录同意，以下稿件票数未满：" + waiting.String()))
+	}
+	if len(handled) > 0 {
+		ctx.Send(message.Text("⚠️ 以下稿件已被其他审核员处理：" + strings.Join(handled, "，")))
+	}
+	return passed
+}
+
+// voteReject 记录发送者的否决票 (否决后稿件直接拒绝，投票仅作记录)
+func (b *QQBot) voteReject(ctx *zero.Ctx, post *model.Post, reason string) {
+	v := &model.Vote{PostID: post.ID, Voter: model.QQVoter(ctx.Event.UserID), Reason: reason}
+	if _, err := b.store.CastVote(v, b.wallCfg.ApproveQuorum); err != nil {
+		log.Printf("[QQBot] 记录稿件 #%d 否决失败: %v", post.ID, err)
+	}
+}
+
+// voteProgress 待审核稿件的投票进度，未启用多人审核时返回空
+func (b *QQBot) voteProgress(post *model.Post) string {
+	if b.wallCfg.ApproveQuorum <= 1 || post.Status != model.StatusPending {
+		return ""
+	}
+	tally, err := b.store.VoteTally(post.ID, b.wallCfg.ApproveQuorum)
+	if err != nil {
+		return ""
+	}
+	return "🗳 " + tally.Text()
+}
@@ -1,11 +1,9 @@
 package store
 
 import (
-	"bufio"
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
@@ -39,6 +37,59 @@ func New(dbPath string) (*Store, error) {
 }
 
 func (s *Store) migrate() error {
+	if err := s.createTables(); err != nil {
+		return err
+	}
+	// 旧库补充后加的列
+	columns := []struct{ table, name, def string }{
+		{"posts", "segments", "TEXT NOT NULL DEFAULT '[]'"},
+		{"posts", "chat", "TEXT NOT NULL DEFAULT '[]'"},
+		{"posts", "anon_key", "TEXT NOT NULL DEFAULT ''"},
+		{"posts", "warning", "INTEGER NOT NULL DEFAULT 0"},
+		{"posts", "warn_reason", "TEXT NOT NULL DEFAULT ''"},
+		{"posts", "flags", "TEXT NOT NULL DEFAULT '[]'"},
+		{"posts", "image_hashes", "TEXT NOT NULL DEFAULT '[]'"},
+	}
+	for _, c := range columns {
+		if err := s.ensureColumn(c.table, c.name, c.def); err != nil {
+			return err
+		}
+	}
+	// 旧版网页管理员角色 "admin" 改为所有者
+	_, err := s.db.Exec("UPDATE accounts SET role=? WHERE role='admin'", string(model.RoleOwner))
+	return err
+}
+
+// ensureColumn 列不存在时执行 ALTER TABLE ADD COLUMN
+func (s *Store) ensureColumn(table, column, def string) error {
+	rows, err := s.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, typ        string
+			dflt             sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	_, err = s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, def))
+	return err
+}
+
+func (s *Store) createTables() error {
 	_, err := s.db.Exec(`
 		CREATE TABLE IF NOT EXISTS posts (
 			id          INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -52,6 +103,13 @@ func (s *Store) migrate() error {
 			reason      TEXT    NOT NULL DEFAULT '',
 			tid         TEXT    NOT NULL DEFAULT '',
 			avatar_url  TEXT    NOT NULL DEFAULT '',
+			segments    TEXT    NOT NULL DEFAULT '[]',
+			chat        TEXT    NOT NULL DEFAULT '[]',
+			anon_key    TEXT    NOT NULL DEFAULT '',
+			warning     INTEGER NOT NULL DEFAULT 0,
+			warn_reason TEXT    NOT NULL DEFAULT '',
+			flags       TEXT    NOT NULL DEFAULT '[]',
+			image_hashes TEXT   NOT NULL DEFAULT '[]',
 			create_time INTEGER NOT NULL DEFAULT 0,
 			update_time INTEGER NOT NULL DEFAULT 0
 		);
@@ -66,11 +124,93 @@ func (s *Store) migrate() error {
 			create_time   INTEGER NOT NULL DEFAULT 0
 		);
 
+		CREATE TABLE IF NOT EXISTS moderators (
+			uin         INTEGER PRIMARY KEY,
+			role        TEXT    NOT NULL,
+			added_by    INTEGER NOT NULL DEFAULT 0,
+			create_time INTEGER NOT NULL DEFAULT 0
+		);
+
+		CREATE TABLE IF NOT EXISTS votes (
+			post_id     INTEGER NOT NULL,
+			voter       TEXT    NOT NULL,
+			approve     INTEGER NOT NULL DEFAULT 0,
+			reason      TEXT    NOT NULL DEFAULT '',
+			create_time INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (post_id, voter)
+		);
+
+		CREATE TABLE IF NOT EXISTS bans (
+			target      TEXT PRIMARY KEY,
+			reason      TEXT    NOT NULL DEFAULT '',
+			expire_time INTEGER NOT NULL DEFAULT 0,
+			operator    TEXT    NOT NULL DEFAULT '',
+			create_time INTEGER NOT NULL DEFAULT 0
+		);
+
+		CREATE TABLE IF NOT EXISTS censor_words (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			pattern     TEXT    NOT NULL,
+			action      TEXT    NOT NULL DEFAULT 'block',
+			regex       INTEGER NOT NULL DEFAULT 0,
+			category    TEXT    NOT NULL DEFAULT '',
+			added_by    TEXT    NOT NULL DEFAULT '',
+			create_time INTEGER NOT NULL DEFAULT 0,
+			UNIQUE(pattern, regex)
+		);
+
+		CREATE TABLE IF NOT EXISTS image_blocklist (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			hash        TEXT    NOT NULL UNIQUE,
+			action      TEXT    NOT NULL DEFAULT 'block',
+			note        TEXT    NOT NULL DEFAULT '',
+			post_id     INTEGER NOT NULL DEFAULT 0,
+			added_by    TEXT    NOT NULL DEFAULT '',
+			create_time INTEGER NOT NULL DEFAULT 0
+		);
+
+		CREATE TABLE IF NOT EXISTS rate_events (
+			key  TEXT    NOT NULL,
+			time INTEGER NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_rate_events ON rate_events(key, time);
+
 		CREATE TABLE IF NOT EXISTS sessions (
 			token      TEXT PRIMARY KEY,
 			account_id INTEGER NOT NULL,
 			expire_time INTEGER NOT NULL
 		);
+
+		CREATE TABLE IF NOT EXISTS digests (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			kind        TEXT    NOT NULL,
+			issue       INTEGER NOT NULL,
+			post_ids    TEXT    NOT NULL DEFAULT '[]',
+			create_time INTEGER NOT NULL DEFAULT 0
+		);
+		CREATE INDEX IF NOT EXISTS idx_digests_kind ON digests(kind);
+
+		CREATE TABLE IF NOT EXISTS renders (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			post_id     INTEGER NOT NULL,
+			version     INTEGER NOT NULL,
+			idx         INTEGER NOT NULL DEFAULT 0,
+			file        TEXT    NOT NULL,
+			published   INTEGER NOT NULL DEFAULT 0,
+			create_time INTEGER NOT NULL DEFAULT 0
+		);
+		CREATE INDEX IF NOT EXISTS idx_renders_post ON renders(post_id, version);
+
+		CREATE TABLE IF NOT EXISTS notices (
+			message_id  INTEGER PRIMARY KEY,
+			post_id     INTEGER NOT NULL,
+			create_time INTEGER NOT NULL DEFAULT 0
+		);
+
+		CREATE TABLE IF NOT EXISTS settings (
+			key   TEXT PRIMARY KEY,
+			value TEXT NOT NULL DEFAULT ''
+		);
 	`)
 	return err
 }
@@ -81,37 +221,70 @@ func (s *Store) migrate() error {
 
 // SavePost 保存投稿, 若 ID==0 则插入并回填 ID, 否则更新
 func (s *Store) SavePost(p *model.Post) error {
+	if p.ID != 0 {
+		_, err := s.updatePost(p)
+		return err
+	}
+
 	imagesJSON, _ := json.Marshal(p.Images)
+	segmentsJSON, _ := json.Marshal(p.Segments)
+	chatJSON, _ := json.Marshal(p.Chat)
+	flagsJSON, _ := json.Marshal(p.Flags)
+	hashesJSON, _ := json.Marshal(p.ImageHashes)
 	now := time.Now().Unix()
+	if p.CreateTime == 0 {
+		p.CreateTime = now
+	}
+	res, err := s.db.Exec(
+		`INSERT INTO posts (uin,name,group_id,text,images,anon,status,reason,tid,avatar_url,segments,chat,anon_key,warning,warn_reason,flags,image_hashes,create_time,update_time)
+		 VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		p.UIN, p.Name, p.GroupID, p.Text, string(imagesJSON),
+		b2i(p.Anon), string(p.Status), p.Reason, p.TID, p.AvatarURL,
+		string(segmentsJSON), string(chatJSON), p.AnonKey, b2i(p.Warning), p.WarnReason, string(flagsJSON), string(hashesJSON), p.CreateTime, now,
+	)
+	if err != nil {
+		return err
+	}
+	p.ID, _ = res.LastInsertId()
+	return nil
+}
 
-	if p.ID == 0 {
-		if p.CreateTime == 0 {
-			p.CreateTime = now
-		}
-		res, err := s.db.Exec(
-			`INSERT INTO posts (uin,name,group_id,text,images,anon,status,reason,tid,avatar_url,create_time,update_time)
-			 VALUES (?,?,?,?,?,?,?,?,?,?,?,?)`,
-			p.UIN, p.Name, p.GroupID, p.Text, string(imagesJSON),
-			b2i(p.Anon), string(p.Status), p.Reason, p.TID, p.AvatarURL,
-			p.CreateTime, now,
-		)
-		if err != nil {
-			return err
-		}
-		p.ID, _ = res.LastInsertId()
-	} else {
-		_, err := s.db.Exec(
-			`UPDATE posts SET uin=?,name=?,group_id=?,text=?,images=?,anon=?,status=?,reason=?,tid=?,avatar_url=?,update_time=?
-			 WHERE id=?`,
-			p.UIN, p.Name, p.GroupID, p.Text, string(imagesJSON),
-			b2i(p.Anon), string(p.Status), p.Reason, p.TID, p.AvatarURL,
-			now, p.ID,
-		)
-		if err != nil {
-			return err
+// SavePostIf 仅当稿件在库中仍处于 from 中的某个状态时保存，返回是否保存成功。
+// 审核、修改等先读后写的操作用它代替 SavePost，避免并发时覆盖他人已做的处理
+func (s *Store) SavePostIf(p *model.Post, from ...model.PostStatus) (bool, error) {
+	if len(from) == 0 {
+		return false, nil
+	}
+	return s.updatePost(p, from...)
+}
+
+// updatePost 更新已有投稿，from 非空时附加状态条件，返回是否有行被更新
+func (s *Store) updatePost(p *model.Post, from ...model.PostStatus) (bool, error) {
+	imagesJSON, _ := json.Marshal(p.Images)
+	segmentsJSON, _ := json.Marshal(p.Segments)
+	chatJSON, _ := json.Marshal(p.Chat)
+	flagsJSON, _ := json.Marshal(p.Flags)
+	hashesJSON, _ := json.Marshal(p.ImageHashes)
+
+	q := `UPDATE posts SET uin=?,name=?,group_id=?,text=?,images=?,anon=?,status=?,reason=?,tid=?,avatar_url=?,segments=?,chat=?,anon_key=?,warning=?,warn_reason=?,flags=?,image_hashes=?,update_time=?
+			 WHERE id=?`
+	args := []interface{}{
+		p.UIN, p.Name, p.GroupID, p.Text, string(imagesJSON),
+		b2i(p.Anon), string(p.Status), p.Reason, p.TID, p.AvatarURL,
+		string(segmentsJSON), string(chatJSON), p.AnonKey, b2i(p.Warning), p.WarnReason, string(flagsJSON), string(hashesJSON), time.Now().Unix(), p.ID,
+	}
+	if len(from) > 0 {
+		q += " AND status IN (?" + strings.Repeat(",?", len(from)-1) + ")"
+		for _, st := range from {
+			args = append(args, string(st))
 		}
 	}
-	return nil
+	res, err := s.db.Exec(q, args...)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
 }
 
 // GetPost 获取单条投稿
@@ -188,6 +361,62 @@ func (s *Store) ListAll(limit, offset int) ([]*model.Post, error) {
 	return scanPosts(rows)
 }
 
+// ListByUIN 分页列出某个投稿者的投稿（最新在前）
+func (s *Store) ListByUIN(uin int64, limit, offset int) ([]*model.Post, error) {
+	rows, err := s.db.Query(
+		postCols("WHERE uin=? ORDER BY id DESC LIMIT ? OFFSET ?"), uin, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	return scanPosts(rows)
+}
+
+// CountByUIN 统计某个投稿者的投稿数量
+func (s *Store) CountByUIN(uin int64) (int, error) {
+	var n int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM posts WHERE uin=?", uin).Scan(&n)
+	return n, err
+}
+
+// FindDuplicate 查找 since 之后正文相同、未被拒绝的纯文字投稿，返回其编号，没有时返回 0
+func (s *Store) FindDuplicate(text string, since, excludeID int64) (int64, error) {
+	var id int64
+	err := s.db.QueryRow(
+		`SELECT id FROM posts WHERE text=? AND create_time>=? AND id<>? AND status<>? AND images IN ('','null','[]')
+		 ORDER BY id DESC LIMIT 1`,
+		text, since, excludeID, string(model.StatusRejected),
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+// QueuePosition 稿件在同状态队列中的位置 (从 1 开始)。审核和发布都按编号先后处理
+func (s *Store) QueuePosition(p *model.Post) (int, error) {
+	var n int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM posts WHERE status=? AND id<=?", string(p.Status), p.ID).Scan(&n)
+	return n, err
+}
+
+// ListPublishedBetween 列出发布时间 (update_time) 在 [start, end] 内的已发布投稿
+func (s *Store) ListPublishedBetween(start, end int64) ([]*model.Post, error) {
+	rows, err := s.db.Query(
+		postCols("WHERE status='published' AND update_time BETWEEN ? AND ? ORDER BY id ASC"), start, end,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	return scanPosts(rows)
+}
+
 // CountByStatus 统计各状态数量
 func (s *Store) CountByStatus(status model.PostStatus) (int, error) {
 	var n int
@@ -206,7 +435,7 @@ func (s *Store) CountAll() (int, error) {
 // Account CRUD
 // ──────────────────────────────────────────
 
-func (s *Store) CreateAccount(username, passwordHash, salt, role string) error {
+func (s *Store) CreateAccount(username, passwordHash, salt string, role model.Role) error {
 	_, err := s.db.Exec(
 		"INSERT INTO accounts (username,password_hash,salt,role,create_time) VALUES (?,?,?,?,?)",
 		username, passwordHash, salt, role, time.Now().Unix(),
@@ -244,6 +473,98 @@ func (s *Store) AccountCount() (int, error) {
 	return n, err
 }
 
+// ListAccounts 列出所有网页账号
+func (s *Store) ListAccounts() ([]*model.Account, error) {
+	rows, err := s.db.Query("SELECT id,username,password_hash,salt,role,create_time FROM accounts ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var out []*model.Account
+	for rows.Next() {
+		var a model.Account
+		if err := rows.Scan(&a.ID, &a.Username, &a.PasswordHash, &a.Salt, &a.Role, &a.CreateTime); err != nil {
+			return nil, err
+		}
+		out = append(out, &a)
+	}
+	return out, rows.Err()
+}
+
+// SetAccountRole 修改网页账号的角色
+func (s *Store) SetAccountRole(id int64, role model.Role) error {
+	_, err := s.db.Exec("UPDATE accounts SET role=? WHERE id=?", string(role), id)
+	return err
+}
+
+// CountAccountsByRole 统计某角色的网页账号数
+func (s *Store) CountAccountsByRole(role model.Role) (int, error) {
+	var n int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM accounts WHERE role=?", string(role)).Scan(&n)
+	return n, err
+}
+
+// ──────────────────────────────────────────
+// Moderators QQ 审核团队
+// ──────────────────────────────────────────
+
+// SetModerator 添加审核团队成员或修改其角色
+func (s *Store) SetModerator(m *model.Moderator) error {
+	if m.CreateTime == 0 {
+		m.CreateTime = time.Now().Unix()
+	}
+	_, err := s.db.Exec(
+		`INSERT INTO moderators (uin,role,added_by,create_time) VALUES (?,?,?,?)
+		 ON CONFLICT(uin) DO UPDATE SET role=excluded.role, added_by=excluded.added_by`,
+		m.UIN, string(m.Role), m.AddedBy, m.CreateTime,
+	)
+	return err
+}
+
+// RemoveModerator 移除审核团队成员，返回是否存在该成员
+func (s *Store) RemoveModerator(uin int64) (bool, error) {
+	res, err := s.db.Exec("DELETE FROM moderators WHERE uin=?", uin)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// GetModerator 查询审核团队成员，不存在时返回 nil
+func (s *Store) GetModerator(uin int64) (*model.Moderator, error) {
+	var m model.Moderator
+	err := s.db.QueryRow(
+		"SELECT uin,role,added_by,create_time FROM moderators WHERE uin=?", uin,
+	).Scan(&m.UIN, &m.Role, &m.AddedBy, &m.CreateTime)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &m, err
+}
+
+// ListModerators 列出审核团队成员
+func (s *Store) ListModerators() ([]*model.Moderator, error) {
+	rows, err := s.db.Query("SELECT uin,role,added_by,create_time FROM moderators ORDER BY create_time")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var out []*model.Moderator
+	for rows.Next() {
+		var m model.Moderator
+		if err := rows.Scan(&m.UIN, &m.Role, &m.AddedBy, &m.CreateTime); err != nil {
+			return nil, err
+		}
+		out = append(out, &m)
+	}
+	return out, rows.Err()
+}
+
 // ──────────────────────────────────────────
 // Session CRUD
 // ──────────────────────────────────────────
@@ -283,50 +604,434 @@ func (s *Store) CleanExpiredSessions() {
 	_, _ = s.db.Exec("DELETE FROM sessions WHERE expire_time < ?", time.Now().Unix())
 }
 
-// Close 关闭数据库连接
-func (s *Store) Close() error {
-	return s.db.Close()
+// ──────────────────────────────────────────
+// Digest 精选合集
+// ──────────────────────────────────────────
+
+// NextDigestIssue 返回指定类型合集的下一期期号
+func (s *Store) NextDigestIssue(kind string) (int, error) {
+	var n int
+	err := s.db.QueryRow("SELECT COALESCE(MAX(issue),0) FROM digests WHERE kind=?", kind).Scan(&n)
+	return n + 1, err
+}
+
+// SaveDigest 记录一期已发布的合集
+func (s *Store) SaveDigest(kind string, issue int, postIDs []int64) error {
+	idsJSON, _ := json.Marshal(postIDs)
+	_, err := s.db.Exec(
+		"INSERT INTO digests (kind,issue,post_ids,create_time) VALUES (?,?,?,?)",
+		kind, issue, string(idsJSON), time.Now().Unix(),
+	)
+	return err
 }
 
 // ──────────────────────────────────────────
-// 敏感词辅助
+// Render 渲染产物
 // ──────────────────────────────────────────
 
-// LoadCensorWords 加载敏感词列表（内置 + 文件）
-func LoadCensorWords(words []string, filePath string) []string {
-	result := make([]string, 0, len(words))
-	for _, w := range words {
-		w = strings.TrimSpace(w)
-		if w != "" {
-			result = append(result, strings.ToLower(w))
+// SaveRenders 将一组渲染图记录为稿件的新版本，返回版本号
+func (s *Store) SaveRenders(postID int64, files []string, published bool) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var version int
+	if err := tx.QueryRow("SELECT COALESCE(MAX(version),0)+1 FROM renders WHERE post_id=?", postID).Scan(&version); err != nil {
+		return 0, err
+	}
+	now := time.Now().Unix()
+	for i, f := range files {
+		if _, err := tx.Exec(
+			"INSERT INTO renders (post_id,version,idx,file,published,create_time) VALUES (?,?,?,?,?,?)",
+			postID, version, i, f, b2i(published), now,
+		); err != nil {
+			return 0, err
 		}
 	}
-	if filePath != "" {
-		if f, err := os.Open(filePath); err == nil {
-			defer func() {
-				_ = f.Close()
-			}()
-			sc := bufio.NewScanner(f)
-			for sc.Scan() {
-				w := strings.TrimSpace(strings.ToLower(sc.Text()))
-				if w != "" && !strings.HasPrefix(w, "#") {
-					result = append(result, w)
-				}
-			}
+	return version, tx.Commit()
+}
+
+// ListRenders 列出稿件的全部渲染图，新版本在前，版本内按顺序排列
+func (s *Store) ListRenders(postID int64) ([]*model.Render, error) {
+	rows, err := s.db.Query(
+		"SELECT id,post_id,version,idx,file,published,create_time FROM renders WHERE post_id=? ORDER BY version DESC, idx ASC",
+		postID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var out []*model.Render
+	for rows.Next() {
+		var r model.Render
+		var published int
+		if err := rows.Scan(&r.ID, &r.PostID, &r.Version, &r.Index, &r.File, &published, &r.CreateTime); err != nil {
+			return nil, err
+		}
+		r.Published = published != 0
+		out = append(out, &r)
+	}
+	return out, rows.Err()
+}
+
+// ──────────────────────────────────────────
+// Notices 管理群通知
+// ──────────────────────────────────────────
+
+// SaveNotice 记录管理群中某条通知消息对应的稿件
+func (s *Store) SaveNotice(messageID, postID int64) error {
+	_, err := s.db.Exec(
+		"INSERT OR REPLACE INTO notices (message_id,post_id,create_time) VALUES (?,?,?)",
+		messageID, postID, time.Now().Unix(),
+	)
+	return err
+}
+
+// NoticePost 返回通知消息对应的稿件编号，没有记录时返回 0
+func (s *Store) NoticePost(messageID int64) (int64, error) {
+	var postID int64
+	err := s.db.QueryRow("SELECT post_id FROM notices WHERE message_id=?", messageID).Scan(&postID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return postID, err
+}
+
+// ──────────────────────────────────────────
+// Votes 多人审核投票
+// ──────────────────────────────────────────
+
+// CastVote 记录一票 (同一审核员重复投票时覆盖)，返回该稿件最新的投票统计
+func (s *Store) CastVote(v *model.Vote, quorum int) (*model.VoteTally, error) {
+	if v.CreateTime == 0 {
+		v.CreateTime = time.Now().Unix()
+	}
+	_, err := s.db.Exec(
+		"INSERT OR REPLACE INTO votes (post_id,voter,approve,reason,create_time) VALUES (?,?,?,?,?)",
+		v.PostID, v.Voter, b2i(v.Approve), v.Reason, v.CreateTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return s.VoteTally(v.PostID, quorum)
+}
+
+// ListVotes 按投票时间列出稿件的投票
+func (s *Store) ListVotes(postID int64) ([]*model.Vote, error) {
+	rows, err := s.db.Query(
+		"SELECT post_id,voter,approve,reason,create_time FROM votes WHERE post_id=? ORDER BY create_time, rowid",
+		postID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var out []*model.Vote
+	for rows.Next() {
+		var (
+			v       model.Vote
+			approve int
+		)
+		if err := rows.Scan(&v.PostID, &v.Voter, &approve, &v.Reason, &v.CreateTime); err != nil {
+			return nil, err
+		}
+		v.Approve = approve != 0
+		out = append(out, &v)
+	}
+	return out, rows.Err()
+}
+
+// VoteTally 统计稿件的投票
+func (s *Store) VoteTally(postID int64, quorum int) (*model.VoteTally, error) {
+	votes, err := s.ListVotes(postID)
+	if err != nil {
+		return nil, err
+	}
+	return model.TallyVotes(votes, quorum), nil
+}
+
+// ClearVotes 清空稿件的投票 (稿件内容修改后重新审核)
+func (s *Store) ClearVotes(postID int64) error {
+	_, err := s.db.Exec("DELETE FROM votes WHERE post_id=?", postID)
+	return err
+}
+
+// ──────────────────────────────────────────
+// CensorWords 敏感词规则
+// ──────────────────────────────────────────
+
+// SaveCensorWord 添加敏感词规则，同一个词已存在时更新处理方式和分类
+func (s *Store) SaveCensorWord(w *model.CensorWord) error {
+	if w.CreateTime == 0 {
+		w.CreateTime = time.Now().Unix()
+	}
+	_, err := s.db.Exec(
+		`INSERT INTO censor_words (pattern,action,regex,category,added_by,create_time) VALUES (?,?,?,?,?,?)
+		 ON CONFLICT(pattern,regex) DO UPDATE SET action=excluded.action,category=excluded.category,added_by=excluded.added_by`,
+		w.Pattern, w.Action, b2i(w.Regex), w.Category, w.AddedBy, w.CreateTime,
+	)
+	return err
+}
+
+// RemoveCensorWord 按词删除规则，返回是否存在
+func (s *Store) RemoveCensorWord(pattern string, regex bool) (bool, error) {
+	res, err := s.db.Exec("DELETE FROM censor_words WHERE pattern=? AND regex=?", pattern, b2i(regex))
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// ListCensorWords 列出全部规则，按分类和添加顺序排列
+func (s *Store) ListCensorWords() ([]*model.CensorWord, error) {
+	rows, err := s.db.Query("SELECT id,pattern,action,regex,category,added_by,create_time FROM censor_words ORDER BY category, id")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var list []*model.CensorWord
+	for rows.Next() {
+		var w model.CensorWord
+		var regex int
+		if err := rows.Scan(&w.ID, &w.Pattern, &w.Action, &regex, &w.Category, &w.AddedBy, &w.CreateTime); err != nil {
+			return nil, err
+		}
+		w.Regex = regex != 0
+		list = append(list, &w)
+	}
+	return list, rows.Err()
+}
+
+// ──────────────────────────────────────────
+// ImageBlocklist 图片黑名单
+// ──────────────────────────────────────────
+
+// SaveImageBlock 添加图片黑名单，同一指纹已存在时更新处理方式和说明
+func (s *Store) SaveImageBlock(b *model.ImageBlock) error {
+	if b.CreateTime == 0 {
+		b.CreateTime = time.Now().Unix()
+	}
+	_, err := s.db.Exec(
+		`INSERT INTO image_blocklist (hash,action,note,post_id,added_by,create_time) VALUES (?,?,?,?,?,?)
+		 ON CONFLICT(hash) DO UPDATE SET action=excluded.action,note=excluded.note,added_by=excluded.added_by`,
+		b.Hash, b.Action, b.Note, b.PostID, b.AddedBy, b.CreateTime,
+	)
+	return err
+}
+
+// RemoveImageBlock 按 ID 删除，返回是否存在
+func (s *Store) RemoveImageBlock(id int64) (bool, error) {
+	res, err := s.db.Exec("DELETE FROM image_blocklist WHERE id=?", id)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// ListImageBlocks 列出全部图片黑名单，按添加顺序排列
+func (s *Store) ListImageBlocks() ([]*model.ImageBlock, error) {
+	rows, err := s.db.Query("SELECT id,hash,action,note,post_id,added_by,create_time FROM image_blocklist ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var list []*model.ImageBlock
+	for rows.Next() {
+		var b model.ImageBlock
+		if err := rows.Scan(&b.ID, &b.Hash, &b.Action, &b.Note, &b.PostID, &b.AddedBy, &b.CreateTime); err != nil {
+			return nil, err
+		}
+		list = append(list, &b)
+	}
+	return list, rows.Err()
+}
+
+// ──────────────────────────────────────────
+// Bans 投稿黑名单
+// ──────────────────────────────────────────
+
+// SaveBan 添加封禁，已封禁时覆盖原记录
+func (s *Store) SaveBan(b *model.Ban) error {
+	if b.CreateTime == 0 {
+		b.CreateTime = time.Now().Unix()
+	}
+	_, err := s.db.Exec(
+		"INSERT OR REPLACE INTO bans (target,reason,expire_time,operator,create_time) VALUES (?,?,?,?,?)",
+		b.Target, b.Reason, b.ExpireTime, b.Operator, b.CreateTime,
+	)
+	return err
+}
+
+// RemoveBan 解除封禁，返回是否存在有效的封禁
+func (s *Store) RemoveBan(target string) (bool, error) {
+	res, err := s.db.Exec(
+		"DELETE FROM bans WHERE target=? AND (expire_time=0 OR expire_time>?)",
+		target, time.Now().Unix(),
+	)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// ActiveBan 返回 targets 中任一标识当前有效的封禁，没有时返回 nil
+func (s *Store) ActiveBan(targets ...string) (*model.Ban, error) {
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	args := make([]interface{}, 0, len(targets)+1)
+	for _, t := range targets {
+		args = append(args, t)
+	}
+	args = append(args, time.Now().Unix())
+	var b model.Ban
+	err := s.db.QueryRow(
+		"SELECT target,reason,expire_time,operator,create_time FROM bans WHERE target IN (?"+
+			strings.Repeat(",?", len(targets)-1)+") AND (expire_time=0 OR expire_time>?) LIMIT 1",
+		args...,
+	).Scan(&b.Target, &b.Reason, &b.ExpireTime, &b.Operator, &b.CreateTime)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &b, err
+}
+
+// ListBans 列出有效的封禁，最近的在前
+func (s *Store) ListBans() ([]*model.Ban, error) {
+	rows, err := s.db.Query(
+		"SELECT target,reason,expire_time,operator,create_time FROM bans WHERE expire_time=0 OR expire_time>? ORDER BY create_time DESC",
+		time.Now().Unix(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var out []*model.Ban
+	for rows.Next() {
+		var b model.Ban
+		if err := rows.Scan(&b.Target, &b.Reason, &b.ExpireTime, &b.Operator, &b.CreateTime); err != nil {
+			return nil, err
+		}
+		out = append(out, &b)
+	}
+	return out, rows.Err()
+}
+
+// RejectPendingByUIN 拒绝某 QQ 用户所有待审核的稿件，返回处理数量
+func (s *Store) RejectPendingByUIN(uin int64, reason string) (int, error) {
+	res, err := s.db.Exec(
+		"UPDATE posts SET status=?, reason=?, update_time=? WHERE uin=? AND status=?",
+		string(model.StatusRejected), reason, time.Now().Unix(), uin, string(model.StatusPending),
+	)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// ──────────────────────────────────────────
+// Rate events 投稿限流记录
+// ──────────────────────────────────────────
+
+// RecordEvents 为每个 key 记录一次发生在 at 的事件
+func (s *Store) RecordEvents(keys []string, at int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if _, err := tx.Exec("INSERT INTO rate_events (key,time) VALUES (?,?)", key, at); err != nil {
+			_ = tx.Rollback()
+			return err
 		}
 	}
-	return result
+	return tx.Commit()
 }
 
-// CheckCensor 检测文本是否包含敏感词, 返回命中的词
-func CheckCensor(text string, words []string) (bool, string) {
-	lower := strings.ToLower(text)
-	for _, w := range words {
-		if strings.Contains(lower, w) {
-			return true, w
+// DeleteEvents 为每个 key 删除一条发生在 at 的事件
+func (s *Store) DeleteEvents(keys []string, at int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if _, err := tx.Exec("DELETE FROM rate_events WHERE rowid IN (SELECT rowid FROM rate_events WHERE key=? AND time=? LIMIT 1)", key, at); err != nil {
+			_ = tx.Rollback()
+			return err
 		}
 	}
-	return false, ""
+	return tx.Commit()
+}
+
+// EventTimes 按时间升序返回 key 在 since 之后的事件时间
+func (s *Store) EventTimes(key string, since int64) ([]int64, error) {
+	rows, err := s.db.Query("SELECT time FROM rate_events WHERE key=? AND time>? ORDER BY time", key, since)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var out []int64
+	for rows.Next() {
+		var t int64
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// PruneEvents 删除 before 之前的事件
+func (s *Store) PruneEvents(before int64) error {
+	_, err := s.db.Exec("DELETE FROM rate_events WHERE time<?", before)
+	return err
+}
+
+// ──────────────────────────────────────────
+// Settings 运行时设置
+// ──────────────────────────────────────────
+
+// GetSetting 读取设置，不存在时返回空字符串
+func (s *Store) GetSetting(key string) (string, error) {
+	var v string
+	err := s.db.QueryRow("SELECT value FROM settings WHERE key=?", key).Scan(&v)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return v, err
+}
+
+// SetSetting 写入设置
+func (s *Store) SetSetting(key, value string) error {
+	_, err := s.db.Exec(
+		"INSERT INTO settings (key,value) VALUES (?,?) ON CONFLICT(key) DO UPDATE SET value=excluded.value",
+		key, value,
+	)
+	return err
+}
+
+// Close 关闭数据库连接
+func (s *Store) Close() error {
+	return s.db.Close()
 }
 
 // ──────────────────────────────────────────
@@ -334,15 +1039,15 @@ func CheckCensor(text string, words []string) (bool, string) {
 // ──────────────────────────────────────────
 
 func postCols(where string) string {
-	return "SELECT id,uin,name,group_id,text,images,anon,status,reason,tid,avatar_url,create_time,update_time FROM posts " + where
+	return "SELECT id,uin,name,group_id,text,images,anon,status,reason,tid,avatar_url,segments,chat,anon_key,warning,warn_reason,flags,image_hashes,create_time,update_time FROM posts " + where
 }
 
 func scanPost(row *sql.Row) (*model.Post, error) {
 	var p model.Post
-	var imgs string
-	var anon int
+	var imgs, segs, chat, flags, hashes string
+	var anon, warning int
 	err := row.Scan(&p.ID, &p.UIN, &p.Name, &p.GroupID, &p.Text, &imgs, &anon,
-		&p.Status, &p.Reason, &p.TID, &p.AvatarURL, &p.CreateTime, &p.UpdateTime)
+		&p.Status, &p.Reason, &p.TID, &p.AvatarURL, &segs, &chat, &p.AnonKey, &warning, &p.WarnReason, &flags, &hashes, &p.CreateTime, &p.UpdateTime)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -350,7 +1055,12 @@ func scanPost(row *sql.Row) (*model.Post, error) {
 		return nil, err
 	}
 	p.Anon = anon != 0
+	p.Warning = warning != 0
 	_ = json.Unmarshal([]byte(imgs), &p.Images)
+	_ = json.Unmarshal([]byte(segs), &p.Segments)
+	_ = json.Unmarshal([]byte(chat), &p.Chat)
+	_ = json.Unmarshal([]byte(flags), &p.Flags)
+	_ = json.Unmarshal([]byte(hashes), &p.ImageHashes)
 	return &p, nil
 }
 
@@ -358,14 +1068,19 @@ func scanPosts(rows *sql.Rows) ([]*model.Post, error) {
 	var posts []*model.Post
 	for rows.Next() {
 		var p model.Post
-		var imgs string
-		var anon int
+		var imgs, segs, chat, flags, hashes string
+		var anon, warning int
 		if err := rows.Scan(&p.ID, &p.UIN, &p.Name, &p.GroupID, &p.Text, &imgs, &anon,
-			&p.Status, &p.Reason, &p.TID, &p.AvatarURL, &p.CreateTime, &p.UpdateTime); err != nil {
+			&p.Status, &p.Reason, &p.TID, &p.AvatarURL, &segs, &chat, &p.AnonKey, &warning, &p.WarnReason, &flags, &hashes, &p.CreateTime, &p.UpdateTime); err != nil {
 			return nil, err
 		}
 		p.Anon = anon != 0
+		p.Warning = warning != 0
 		_ = json.Unmarshal([]byte(imgs), &p.Images)
+		_ = json.Unmarshal([]byte(segs), &p.Segments)
+		_ = json.Unmarshal([]byte(chat), &p.Chat)
+		_ = json.Unmarshal([]byte(flags), &p.Flags)
+		_ = json.Unmarshal([]byte(hashes), &p.ImageHashes)
 		posts = append(posts, &p)
 	}
 	return posts, rows.Err()
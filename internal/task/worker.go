@@ -9,6 +9,7 @@ import (
 	"time"
 
 	qzone "github.com/guohuiyuan/qzone-go"
+	"github.com/guohuiyuan/qzonewall-go/internal/artifact"
 	"github.com/guohuiyuan/qzonewall-go/internal/config"
 	"github.com/guohuiyuan/qzonewall-go/internal/model"
 	"github.com/guohuiyuan/qzonewall-go/internal/render"
@@ -24,6 +25,7 @@ type Worker struct {
 	client      *qzone.Client
 	store       *store.Store
 	renderer    *render.Renderer
+	artifacts   *artifact.Store
 	ctx         context.Context
 	cancel      context.CancelFunc
 	wg          sync.WaitGroup
@@ -38,16 +40,18 @@ func NewWorker(
 	client *qzone.Client,
 	st *store.Store,
 	renderer *render.Renderer,
+	artifacts *artifact.Store,
 ) *Worker {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Worker{
-		cfg:      cfg,
-		wallCfg:  wallCfg,
-		client:   client,
-		store:    st,
-		renderer: renderer,
-		ctx:      ctx,
-		cancel:   cancel,
+		cfg:       cfg,
+		wallCfg:   wallCfg,
+		client:    client,
+		store:     st,
+		renderer:  renderer,
+		artifacts: artifacts,
+		ctx:       ctx,
+		cancel:    cancel,
 	}
 }
 
@@ -130,8 +134,8 @@ func (w *Worker) pollAndPublish(workerID int) {
 
 // publish 发布到 QQ 空间。
 func (w *Worker) publish(post *model.Post) error {
-	// 构建说说文本。
-	text := post.Text
+	// 构建说说文本，打码规则命中的片段替换为 ＊
+	text := post.Masked().Text
 	if w.wallCfg.ShowAuthor && !post.Anon {
 		text = fmt.Sprintf("【来自 %s 的投稿】\n\n%s", post.ShowName(), text)
 	}
@@ -141,14 +145,14 @@ func (w *Worker) publish(post *model.Post) error {
 		return fmt.Errorf("publish: renderer not available")
 	}
 
-	// 渲染前解析 file ID 为 URL
+	// 渲染前解析 file ID 为 URL；内容警告稿件在截图后附带清晰原图
 	renderPost := w.resolvePostImages(post)
-	screenshot, err := w.renderer.RenderPost(renderPost)
+	images, err := w.renderer.RenderForPublish(renderPost)
 	if err != nil {
 		return fmt.Errorf("publish: render screenshot: %w", err)
 	}
 
-	opt := &qzone.PublishOption{ImageBytes: [][]byte{screenshot}}
+	opt := &qzone.PublishOption{ImageBytes: images}
 
 	resp, err := w.client.Publish(w.ctx, text, opt)
 	if err != nil {
@@ -172,6 +176,9 @@ func (w *Worker) publish(post *model.Post) error {
 	if err := w.store.SavePost(post); err != nil {
 		log.Printf("[Worker] 回填 TID 失败: %v", err)
 	}
+	if _, err := w.artifacts.Save(post.ID, images, true); err != nil {
+		log.Printf("[Worker] 保存稿件 #%d 发布截图失败: %v", post.ID, err)
+	}
 
 	// 记录发布时间。
 	w.mu.Lock()
@@ -0,0 +1,383 @@
+// Package validate 投稿校验：QQ 和网页投稿共用的规范化与检查流程
+package validate
+
+import (
+	"fmt"
+	"image"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/censor"
+	"github.com/guohuiyuan/qzonewall-go/internal/config"
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	"github.com/guohuiyuan/qzonewall-go/internal/store"
+)
+
+// duplicateWindow 重复投稿检查的时间范围
+const duplicateWindow = 24 * time.Hour
+
+// Code 校验失败的原因
+type Code string
+
+const (
+	CodeEmpty         Code = "empty"           // 没有文字也没有图片
+	CodeTextTooLong   Code = "text_too_long"   // 文字超出 wall.max_text_len
+	CodeTooManyImages Code = "too_many_images" // 图片超出 wall.max_images
+	CodeCensored      Code = "censored"        // 命中屏蔽规则
+	CodeDuplicate     Code = "duplicate"       // 与近期稿件重复
+)
+
+// Error 结构化的校验错误，提示文字由 QQ 和网页各自组织
+type Error struct {
+	Code   Code         `json:"code"`
+	Limit  int          `json:"limit,omitempty"`   // 超限时的上限
+	Actual int          `json:"actual,omitempty"`  // 超限时的实际数量
+	Word   string       `json:"word,omitempty"`    // 第一个命中的敏感词
+	Hits   []censor.Hit `json:"hits,omitempty"`    // 全部命中
+	Images []int        `json:"images,omitempty"`  // 命中图片黑名单的是第几张图片 (从 1 开始)
+	PostID int64        `json:"post_id,omitempty"` // 重复的稿件编号
+}
+
+func (e *Error) Error() string {
+	switch e.Code {
+	case CodeEmpty:
+		return "投稿内容不能为空"
+	case CodeTextTooLong:
+		return fmt.Sprintf("文字超出限制 (%d/%d)", e.Actual, e.Limit)
+	case CodeTooManyImages:
+		return fmt.Sprintf("图片超出限制 (%d/%d)", e.Actual, e.Limit)
+	case CodeCensored:
+		return "投稿包含不允许发布的内容"
+	case CodeDuplicate:
+		return fmt.Sprintf("与稿件 #%d 内容重复", e.PostID)
+	}
+	return string(e.Code)
+}
+
+// ImageList 命中图片黑名单的图片序号，如 "1、3"
+func (e *Error) ImageList() string {
+	nums := make([]string, len(e.Images))
+	for i, n := range e.Images {
+		nums[i] = strconv.Itoa(n)
+	}
+	return strings.Join(nums, "、")
+}
+
+// Censor 敏感词检测，*censor.Engine 与 *censor.Matcher 均可
+type Censor interface {
+	Find(text string) []censor.Hit
+}
+
+// ImageSource 加载投稿图片，用于计算图片指纹
+type ImageSource interface {
+	Load(url string) (image.Image, error)
+}
+
+// ImageCensor 图片黑名单，*censor.Engine 实现
+type ImageCensor interface {
+	FindImage(h censor.ImageHash) *censor.ImageHit
+}
+
+// Validator 投稿校验器。db 为空时不检查重复，nil 时只做规范化和非空检查
+type Validator struct {
+	maxTextLen int
+	maxImages  int
+	censor     Censor
+	db         *store.Store
+	images     ImageSource
+	blocklist  ImageCensor
+}
+
+// New 创建校验器
+func New(cfg config.WallConfig, words Censor, db *store.Store) *Validator {
+	return &Validator{
+		maxTextLen: cfg.MaxTextLen,
+		maxImages:  cfg.MaxImages,
+		censor:     words,
+		db:         db,
+	}
+}
+
+// SetImages 设置图片来源和图片黑名单，设置后校验时为每张图片计算指纹 (写入 post.ImageHashes)
+func (v *Validator) SetImages(src ImageSource, blocklist ImageCensor) {
+	v.images = src
+	v.blocklist = blocklist
+}
+
+// Check 规范化稿件正文 (原地修改)，再依次检查是否为空、长度、图片数、敏感词、图片黑名单和重复投稿。
+// 不通过时返回 *Error；命中复核、打码规则时不拒收，而是写入 post.Flags
+func (v *Validator) Check(post *model.Post) error {
+	if v == nil {
+		v = &Validator{}
+	}
+	normalizePost(post)
+	known := knownHashes(post)
+	post.Flags = nil
+	post.ImageHashes = nil
+
+	text := post.Text
+	if text == "" && len(post.Images) == 0 {
+		return &Error{Code: CodeEmpty}
+	}
+	if n := len([]rune(text)); v.maxTextLen > 0 && n > v.maxTextLen {
+		return &Error{Code: CodeTextTooLong, Limit: v.maxTextLen, Actual: n}
+	}
+	if n := len(post.Images); v.maxImages > 0 && n > v.maxImages {
+		return &Error{Code: CodeTooManyImages, Limit: v.maxImages, Actual: n}
+	}
+	if v.censor != nil {
+		if err := censorPost(post, v.censor.Find(text)); err != nil {
+			return err
+		}
+	}
+	if v.images != nil && len(post.Images) > 0 {
+		post.ImageHashes = v.hashImages(post.Images, known)
+		if v.blocklist != nil {
+			if err := censorImages(post, v.blocklist); err != nil {
+				return err
+			}
+		}
+	}
+	// 带图片的稿件正文常常只是 "如图"，只对纯文字稿件查重
+	if v.db != nil && text != "" && len(post.Images) == 0 {
+		since := time.Now().Add(-duplicateWindow).Unix()
+		id, err := v.db.FindDuplicate(text, since, post.ID)
+		if err != nil {
+			log.Printf("[Validate] 查重失败: %v", err)
+		} else if id != 0 {
+			return &Error{Code: CodeDuplicate, PostID: id}
+		}
+	}
+	return nil
+}
+
+// censorPost 处理敏感词命中：有屏蔽规则命中时拒收，其余写入稿件标记
+func censorPost(post *model.Post, hits []censor.Hit) error {
+	var blocked []censor.Hit
+	for _, h := range hits {
+		if h.Action == censor.ActionBlock {
+			blocked = append(blocked, h)
+			continue
+		}
+		post.Flags = append(post.Flags, model.Flag{
+			Action: string(h.Action), Word: h.Word, Text: h.Text, Start: h.Start, End: h.End,
+		})
+	}
+	if len(blocked) == 0 {
+		return nil
+	}
+	words := make([]string, len(blocked))
+	for i, h := range blocked {
+		words[i] = h.Word
+	}
+	log.Printf("[Validate] 投稿命中屏蔽规则: %s", strings.Join(words, ", "))
+	return &Error{Code: CodeCensored, Hits: blocked}
+}
+
+// knownHashes 稿件已有的图片指纹 (修改稿件时只改正文不必重新下载图片)
+func knownHashes(post *model.Post) map[string]string {
+	if len(post.ImageHashes) != len(post.Images) {
+		return nil
+	}
+	known := make(map[string]string, len(post.Images))
+	for i, img := range post.Images {
+		if post.ImageHashes[i] != "" {
+			known[img] = post.ImageHashes[i]
+		}
+	}
+	return known
+}
+
+// hashImages 并发加载图片并计算指纹，加载失败的图片指纹为空
+func (v *Validator) hashImages(images []string, known map[string]string) []string {
+	hashes := make([]string, len(images))
+	var wg sync.WaitGroup
+	for i, img := range images {
+		if h, ok := known[img]; ok {
+			hashes[i] = h
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m, err := v.images.Load(img)
+			if err != nil {
+				log.Printf("[Validate] 加载第 %d 张图片失败: %v", i+1, err)
+				return
+			}
+			hashes[i] = censor.HashImage(m).String()
+		}()
+	}
+	wg.Wait()
+	return hashes
+}
+
+// censorImages 处理图片黑名单命中，与敏感词相同：屏蔽时拒收，复核时写入稿件标记
+func censorImages(post *model.Post, blocklist ImageCensor) error {
+	var blocked []int
+	for i, s := range post.ImageHashes {
+		h, err := censor.ParseImageHash(s)
+		if err != nil {
+			continue
+		}
+		hit := blocklist.FindImage(h)
+		if hit == nil {
+			continue
+		}
+		if hit.Action == censor.ActionBlock {
+			blocked = append(blocked, i+1)
+			log.Printf("[Validate] 第 %d 张图片命中图片黑名单 #%d (距离 %d)", i+1, hit.Block.ID, hit.Distance)
+			continue
+		}
+		post.Flags = append(post.Flags, model.Flag{Action: string(hit.Action), Word: imageWord(i+1, hit), Image: i + 1})
+	}
+	if len(blocked) == 0 {
+		return nil
+	}
+	return &Error{Code: CodeCensored, Images: blocked}
+}
+
+// imageWord 图片标记的说明，如 "第 2 张图片 (广告二维码)"
+func imageWord(n int, hit *censor.ImageHit) string {
+	label := hit.Block.Note
+	if label == "" {
+		label = fmt.Sprintf("图片黑名单 #%d", hit.Block.ID)
+	}
+	return fmt.Sprintf("第 %d 张图片 (%s)", n, label)
+}
+
+// normalizePost 规范化正文、消息段和聊天记录，并重新生成纯文本
+func normalizePost(post *model.Post) {
+	switch {
+	case post.IsChat():
+		for i := range post.Chat {
+			post.Chat[i].Segments = normalizeSegments(post.Chat[i].Segments)
+		}
+		post.Text = model.ChatText(post.Chat)
+	case len(post.Segments) > 0:
+		post.Segments = normalizeSegments(post.Segments)
+		post.Text = strings.TrimFunc(model.PlainText(post.Segments), blank)
+	default:
+		post.Text = Normalize(post.Text)
+	}
+}
+
+// normalizeSegments 规范化文字段，去掉首尾空白和清理后为空的文字段
+func normalizeSegments(segs []model.Segment) []model.Segment {
+	out := segs[:0]
+	for _, seg := range segs {
+		if seg.Type == model.SegText {
+			if seg.Text = clean(seg.Text); seg.Text == "" {
+				continue
+			}
+		}
+		out = append(out, seg)
+	}
+	trimEdge(out, 0, 1, func(s string) string { return strings.TrimLeftFunc(s, blank) })
+	trimEdge(out, len(out)-1, -1, func(s string) string { return strings.TrimRightFunc(s, blank) })
+	kept := out[:0]
+	for _, seg := range out {
+		if seg.Type != model.SegText || seg.Text != "" {
+			kept = append(kept, seg)
+		}
+	}
+	return kept
+}
+
+// trimEdge 从 start 起沿 step 方向找到第一个文字段并裁剪，跳过回复段
+func trimEdge(segs []model.Segment, start, step int, trim func(string) string) {
+	for i := start; i >= 0 && i < len(segs); i += step {
+		if segs[i].Type == model.SegText {
+			segs[i].Text = trim(segs[i].Text)
+			return
+		}
+		if segs[i].Type != model.SegReply {
+			return
+		}
+	}
+}
+
+// Normalize 清理正文：去掉零宽和控制字符，合并连续空白与空行，去掉首尾空白
+func Normalize(text string) string {
+	return strings.TrimFunc(clean(text), blank)
+}
+
+// blank 是否为可去掉的空白，全角空格常用于段首缩进，保留
+func blank(r rune) bool {
+	return r != '\u3000' && unicode.IsSpace(r)
+}
+
+// clean 规范化一段文字，但保留首尾 (消息段之间的) 空白：
+//   - 去掉零宽、方向控制等不可见字符和换行、制表以外的控制字符
+//   - 保留行首缩进 (空格与制表符)，去掉行尾空白
+//   - 行内连续空白合并为一个空格；其中有制表符时只保留制表符，供渲染时对齐制表位
+//   - 连续空行最多保留一行
+func clean(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	var prev rune
+	var pending []rune // 尚未写出的连续空白
+	newlines := 0
+	flush := func() {
+		switch {
+		case newlines > 0:
+			b.WriteString(strings.Repeat("\n", min(newlines, 2)))
+			// 行首缩进原样保留，其余空白字符统一为空格
+			for _, r := range pending {
+				if r != '\t' {
+					r = ' '
+				}
+				b.WriteRune(r)
+			}
+		case len(pending) > 0:
+			if tabs := strings.Count(string(pending), "\t"); tabs > 0 {
+				b.WriteString(strings.Repeat("\t", tabs))
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+		pending, newlines = pending[:0], 0
+	}
+	for _, r := range s {
+		switch {
+		case r == '\n' || r == '\r':
+			pending = pending[:0] // 行尾空白
+			if r == '\r' || prev != '\r' {
+				newlines++
+			}
+			prev = r
+			continue
+		case invisible(r, prev):
+			continue
+		case blank(r):
+			pending = append(pending, r)
+			continue
+		case unicode.IsControl(r):
+			continue
+		}
+		flush()
+		b.WriteRune(r)
+		prev = r
+	}
+	if newlines > 0 {
+		pending = pending[:0] // 末尾空行上的空白
+	}
+	flush()
+	return b.String()
+}
+
+// invisible 是否为应去掉的格式字符 (零宽空格、BOM、方向控制等)。
+// 零宽连接符紧跟在表情后时是组合表情的一部分，保留
+func invisible(r, prev rune) bool {
+	if !unicode.Is(unicode.Cf, r) {
+		return false
+	}
+	if r == '\u200d' && (unicode.In(prev, unicode.So, unicode.Sk) || prev == '\ufe0f') {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/censor"
+	"github.com/guohuiyuan/qzonewall-go/internal/config"
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	"github.com/guohuiyuan/qzonewall-go/internal/store"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"  你好\u200b世界\ufeff  ":      "你好世界",
+		"a   b":                     "a b",
+		"a  \t b":                   "a\tb",
+		"if x {  \n\treturn\n    }": "if x {\n\treturn\n    }",
+		"a\n \t \n\n\n\tb":          "a\n\n\tb",
+		"第一行   \r\n\r\n\r\n\n第二行":   "第一行\n\n第二行",
+		"　　段首缩进":                    "　　段首缩进",
+		"响\x07铃\u202e反转":            "响铃反转",
+		"👨\u200d👩\u200d👧":           "👨\u200d👩\u200d👧",
+	}
+	for in, want := range cases {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
+
+func TestNormalizeSegments(t *testing.T) {
+	post := &model.Post{Segments: []model.Segment{
+		{Type: model.SegText, Text: " \u200b "},
+		{Type: model.SegText, Text: "  早上好  "},
+		{Type: model.SegAt, Text: "小明", ID: "10001"},
+		{Type: model.SegText, Text: "  一起吃饭 \n"},
+	}}
+	if err := (*Validator)(nil).Check(post); err != nil {
+		t.Fatal(err)
+	}
+	if len(post.Segments) != 3 || post.Text != "早上好 @小明 一起吃饭" {
+		t.Errorf("segments %+v, text %q", post.Segments, post.Text)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	db, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	v := New(config.WallConfig{MaxTextLen: 10, MaxImages: 2}, censor.New([]censor.Rule{
+		{Pattern: "广告", Action: censor.ActionBlock},
+		{Pattern: "兼职", Action: censor.ActionReview},
+		{Pattern: "笨蛋", Action: censor.ActionMask},
+	}, censor.Options{}), db)
+
+	code := func(post *model.Post) Code {
+		var verr *Error
+		if errors.As(v.Check(post), &verr) {
+			return verr.Code
+		}
+		return ""
+	}
+	if c := code(&model.Post{Text: " \u200b\n"}); c != CodeEmpty {
+		t.Errorf("blank text: %q", c)
+	}
+	if c := code(&model.Post{Text: "一二三四五六七八九十一"}); c != CodeTextTooLong {
+		t.Errorf("long text: %q", c)
+	}
+	if c := code(&model.Post{Images: []string{"a", "b", "c"}}); c != CodeTooManyImages {
+		t.Errorf("too many images: %q", c)
+	}
+	if c := code(&model.Post{Text: "加微信看广\u200b告"}); c != CodeCensored {
+		t.Errorf("censor should see through zero-width chars: %q", c)
+	}
+
+	flagged := &model.Post{Text: "招兼职，不要笨蛋"}
+	if c := code(flagged); c != "" || len(flagged.Flags) != 2 {
+		t.Fatalf("review and mask rules should flag, not reject: %q %+v", c, flagged.Flags)
+	}
+	if got := flagged.Masked().Text; got != "招兼职，不要＊＊" {
+		t.Errorf("masked text = %q", got)
+	}
+
+	saved := &model.Post{Text: "有人捡到校园卡吗", Status: model.StatusPending}
+	if err := db.SavePost(saved); err != nil {
+		t.Fatal(err)
+	}
+	dup := &model.Post{Text: "有人捡到校园卡吗\u200b \n"}
+	var verr *Error
+	if !errors.As(v.Check(dup), &verr) || verr.Code != CodeDuplicate || verr.PostID != saved.ID {
+		t.Errorf("duplicate: %+v", verr)
+	}
+	if c := code(&model.Post{Text: "有人捡到校园卡吗", Images: []string{"a"}}); c != "" {
+		t.Errorf("posts with images are not deduplicated: %q", c)
+	}
+	if c := code(saved); c != "" {
+		t.Errorf("editing a post should not match itself: %q", c)
+	}
+}
+
+// memImages 内存图片源，键为图片地址
+type memImages map[string]image.Image
+
+func (m memImages) Load(url string) (image.Image, error) {
+	if img, ok := m[url]; ok {
+		return img, nil
+	}
+	return nil, fmt.Errorf("not found: %s", url)
+}
+
+// blocklist 按指纹精确匹配的图片黑名单
+type blocklist map[censor.ImageHash]censor.Action
+
+func (b blocklist) FindImage(h censor.ImageHash) *censor.ImageHit {
+	if a, ok := b[h]; ok {
+		return &censor.ImageHit{Block: &model.ImageBlock{ID: 1, Note: "二维码"}, Action: a}
+	}
+	return nil
+}
+
+func TestCheckImages(t *testing.T) {
+	// 左暗右亮与左亮右暗两张图片
+	ramp := func(rev bool) image.Image {
+		img := image.NewGray(image.Rect(0, 0, 90, 80))
+		for x := 0; x < 90; x++ {
+			v := uint8(x * 2)
+			if rev {
+				v = 255 - v
+			}
+			for y := 0; y < 80; y++ {
+				img.SetGray(x, y, color.Gray{Y: v})
+			}
+		}
+		return img
+	}
+	spam, review := ramp(false), ramp(true)
+	v := New(config.WallConfig{}, nil, nil)
+	v.SetImages(memImages{"spam": spam, "review": review}, blocklist{
+		censor.HashImage(spam):   censor.ActionBlock,
+		censor.HashImage(review): censor.ActionReview,
+	})
+
+	var verr *Error
+	post := &model.Post{Images: []string{"missing", "spam"}}
+	if !errors.As(v.Check(post), &verr) || verr.Code != CodeCensored || verr.ImageList() != "2" {
+		t.Errorf("blocked image: %+v", verr)
+	}
+
+	post = &model.Post{Text: "如图", Images: []string{"missing", "review"}}
+	if err := v.Check(post); err != nil {
+		t.Fatal(err)
+	}
+	if len(post.ImageHashes) != 2 || post.ImageHashes[0] != "" || post.ImageHashes[1] != censor.HashImage(review).String() {
+		t.Errorf("image hashes = %q", post.ImageHashes)
+	}
+	if post.ImageFlag(1) != model.FlagReview || post.FlagText() != "需复核：第 2 张图片 (二维码)" {
+		t.Errorf("flags = %+v", post.Flags)
+	}
+
+	// 已有的指纹不再重新加载
+	v.SetImages(memImages{}, blocklist{})
+	if err := v.Check(post); err != nil || post.ImageHashes[1] == "" {
+		t.Errorf("known hashes should be kept: %v %q", err, post.ImageHashes)
+	}
+}
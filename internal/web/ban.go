@@ -0,0 +1,156 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+)
+
+// ──────────────────────────────────────────
+// 投稿黑名单：QQ 号、account:用户名、ip:地址
+// ──────────────────────────────────────────
+
+// banTargets 网页投稿者可能被封禁的全部标识
+func (s *Server) banTargets(uin int64, account *model.Account, r *http.Request) []string {
+	var targets []string
+	if uin > 0 {
+		targets = append(targets, model.QQBanTarget(uin))
+	}
+	if account != nil {
+		targets = append(targets, "account:"+account.Username)
+	}
+	return append(targets, "ip:"+s.clientIP(r))
+}
+
+// checkBan 投稿者被封禁时写入 403 并返回 false
+func (s *Server) checkBan(w http.ResponseWriter, r *http.Request, uin int64, account *model.Account) bool {
+	ban, err := s.store.ActiveBan(s.banTargets(uin, account, r)...)
+	if err != nil {
+		log.Printf("[Web] 查询封禁失败: %v", err)
+		return true
+	}
+	if ban == nil {
+		return true
+	}
+	jsonResp(w, 403, false, strings.TrimPrefix(ban.Notice(), "🚫 "))
+	return false
+}
+
+// normalizeBanTarget 校验封禁对象：纯数字为 QQ 号，或带 account: / ip: 前缀
+func normalizeBanTarget(target string) (string, bool) {
+	target = strings.TrimSpace(target)
+	if uin, err := strconv.ParseInt(target, 10, 64); err == nil {
+		return model.QQBanTarget(uin), uin > 0
+	}
+	for _, prefix := range []string{"account:", "ip:"} {
+		if rest, ok := strings.CutPrefix(target, prefix); ok && strings.TrimSpace(rest) != "" {
+			return prefix + strings.TrimSpace(rest), true
+		}
+	}
+	return "", false
+}
+
+// parseUIN 封禁对象为 QQ 号时返回号码，否则返回 0
+func parseUIN(target string) int64 {
+	uin, _ := strconv.ParseInt(target, 10, 64)
+	return uin
+}
+
+// handleBansPage 封禁管理页
+func (s *Server) handleBansPage(w http.ResponseWriter, r *http.Request) {
+	account := s.currentAccount(r)
+	if account == nil || !account.IsAdmin() {
+		http.Redirect(w, r, s.url("/login"), http.StatusFound)
+		return
+	}
+	bans, err := s.store.ListBans()
+	if err != nil {
+		log.Printf("[Web] 查询封禁失败: %v", err)
+	}
+	s.renderTemplate(w, "bans.html", map[string]interface{}{
+		"Account": account,
+		"Bans":    bans,
+		"Root":    s.prefix,
+	})
+}
+
+// handleAPIBan 添加封禁 (target, duration, reason, reject_pending)
+func (s *Server) handleAPIBan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		jsonResp(w, 405, false, "仅支持 POST")
+		return
+	}
+	account := s.currentAccount(r)
+	if account == nil || !account.Can(model.PermReview) {
+		jsonResp(w, 403, false, "无权限")
+		return
+	}
+
+	target, ok := normalizeBanTarget(r.FormValue("target"))
+	if !ok {
+		jsonResp(w, 400, false, "封禁对象应为 QQ 号，或 account:用户名、ip:地址")
+		return
+	}
+	if m, _ := s.store.GetModerator(parseUIN(target)); m != nil {
+		jsonResp(w, 400, false, "不能封禁审核团队成员")
+		return
+	}
+	d, err := model.ParseBanDuration(r.FormValue("duration"))
+	if err != nil {
+		jsonResp(w, 400, false, err.Error())
+		return
+	}
+	ban := &model.Ban{
+		Target:   target,
+		Reason:   strings.TrimSpace(r.FormValue("reason")),
+		Operator: model.WebVoter(account.Username),
+	}
+	if d > 0 {
+		ban.ExpireTime = time.Now().Add(d).Unix()
+	}
+	if err := s.store.SaveBan(ban); err != nil {
+		jsonResp(w, 500, false, "保存失败")
+		return
+	}
+	log.Printf("[Web] %s 封禁了 %s (%s): %s", account.Username, target, ban.Until(), ban.Reason)
+
+	msg := fmt.Sprintf("已封禁 %s (%s)", target, ban.Until())
+	if uin := parseUIN(target); uin > 0 && r.FormValue("reject_pending") == "true" {
+		if n, err := s.store.RejectPendingByUIN(uin, "投稿者已被封禁"); err != nil {
+			log.Printf("[Web] 拒绝 %d 的待审核稿件失败: %v", uin, err)
+		} else {
+			msg += fmt.Sprintf("，已拒绝 %d 篇待审核稿件", n)
+		}
+	}
+	jsonResp(w, 200, true, msg)
+}
+
+// handleAPIUnban 解除封禁 (target)
+func (s *Server) handleAPIUnban(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		jsonResp(w, 405, false, "仅支持 POST")
+		return
+	}
+	account := s.currentAccount(r)
+	if account == nil || !account.Can(model.PermReview) {
+		jsonResp(w, 403, false, "无权限")
+		return
+	}
+	target := strings.TrimSpace(r.FormValue("target"))
+	ok, err := s.store.RemoveBan(target)
+	if err != nil {
+		jsonResp(w, 500, false, "解封失败")
+		return
+	}
+	if !ok {
+		jsonResp(w, 404, false, target+" 没有被封禁")
+		return
+	}
+	log.Printf("[Web] %s 解封了 %s", account.Username, target)
+	jsonResp(w, 200, true, "已解封 "+target)
+}
@@ -0,0 +1,218 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/censor"
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	"github.com/guohuiyuan/qzonewall-go/internal/render"
+)
+
+// ──────────────────────────────────────────
+// 敏感词管理
+// ──────────────────────────────────────────
+
+// censorGroup 管理页中的一个分类
+type censorGroup struct {
+	Category string
+	Words    []*model.CensorWord
+}
+
+// groupCensorWords 按分类分组，words 已按分类排序
+func groupCensorWords(words []*model.CensorWord) []censorGroup {
+	var groups []censorGroup
+	for _, w := range words {
+		if n := len(groups); n == 0 || groups[n-1].Category != w.Category {
+			groups = append(groups, censorGroup{Category: w.Category})
+		}
+		groups[len(groups)-1].Words = append(groups[len(groups)-1].Words, w)
+	}
+	return groups
+}
+
+// handleCensorPage 敏感词管理页
+func (s *Server) handleCensorPage(w http.ResponseWriter, r *http.Request) {
+	account := s.currentAccount(r)
+	if account == nil || !account.Can(model.PermCensor) {
+		http.Redirect(w, r, s.url("/login"), http.StatusFound)
+		return
+	}
+	words, err := s.store.ListCensorWords()
+	if err != nil {
+		log.Printf("[Web] 查询敏感词失败: %v", err)
+	}
+	images, err := s.store.ListImageBlocks()
+	if err != nil {
+		log.Printf("[Web] 查询图片黑名单失败: %v", err)
+	}
+	s.renderTemplate(w, "censor.html", map[string]interface{}{
+		"Account": account,
+		"Groups":  groupCensorWords(words),
+		"Count":   len(words),
+		"Static":  s.censor.StaticRules(),
+		"Default": s.censor.DefaultAction(),
+		"Total":   s.censor.Len(),
+		"Images":  images,
+		"Root":    s.prefix,
+	})
+}
+
+// handleAPICensorAdd 添加敏感词 (pattern, action, regex, category)，立即生效
+func (s *Server) handleAPICensorAdd(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		jsonResp(w, 405, false, "仅支持 POST")
+		return
+	}
+	account := s.currentAccount(r)
+	if account == nil || !account.Can(model.PermCensor) {
+		jsonResp(w, 403, false, "无权限")
+		return
+	}
+	action := s.censor.DefaultAction()
+	if v := r.FormValue("action"); v != "" {
+		a, err := censor.ParseAction(v)
+		if err != nil {
+			jsonResp(w, 400, false, err.Error())
+			return
+		}
+		action = a
+	}
+	word := &model.CensorWord{
+		Pattern:  r.FormValue("pattern"),
+		Action:   string(action),
+		Regex:    r.FormValue("regex") == "true",
+		Category: r.FormValue("category"),
+		AddedBy:  model.WebVoter(account.Username),
+	}
+	if err := s.censor.AddWord(word); err != nil {
+		jsonResp(w, 400, false, err.Error())
+		return
+	}
+	log.Printf("[Web] %s 添加敏感词 %s:%s (%s)", account.Username, word.Action, word.Pattern, word.Category)
+	jsonResp(w, 200, true, fmt.Sprintf("已添加 %s，当前共 %d 条规则", word.Pattern, s.censor.Len()))
+}
+
+// handleAPICensorRemove 删除敏感词 (pattern，正则写作 /正则/)
+func (s *Server) handleAPICensorRemove(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		jsonResp(w, 405, false, "仅支持 POST")
+		return
+	}
+	account := s.currentAccount(r)
+	if account == nil || !account.Can(model.PermCensor) {
+		jsonResp(w, 403, false, "无权限")
+		return
+	}
+	pattern := strings.TrimSpace(r.FormValue("pattern"))
+	ok, err := s.censor.RemoveWord(pattern)
+	if err != nil {
+		jsonResp(w, 500, false, "删除失败")
+		return
+	}
+	if !ok {
+		jsonResp(w, 404, false, "没有找到 "+pattern)
+		return
+	}
+	log.Printf("[Web] %s 删除敏感词 %s", account.Username, pattern)
+	jsonResp(w, 200, true, "已删除 "+pattern)
+}
+
+// ──────────────────────────────────────────
+// 图片黑名单
+// ──────────────────────────────────────────
+
+// postImageHash 稿件第 idx 张图片的指纹，投稿时未能计算的现在重新加载
+func (s *Server) postImageHash(post *model.Post, idx int) (string, error) {
+	if idx < len(post.ImageHashes) && post.ImageHashes[idx] != "" {
+		return post.ImageHashes[idx], nil
+	}
+	img, err := render.NewHTTPImageSource().Load(s.resolvePostImagesForRender(post).Images[idx])
+	if err != nil {
+		return "", err
+	}
+	return censor.HashImage(img).String(), nil
+}
+
+// handleAPIImageBlock 将稿件中的一张图片加入黑名单 (id, index 从 0 开始, action, note)
+func (s *Server) handleAPIImageBlock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		jsonResp(w, 405, false, "仅支持 POST")
+		return
+	}
+	account := s.currentAccount(r)
+	if account == nil || !account.Can(model.PermCensor) {
+		jsonResp(w, 403, false, "无权限")
+		return
+	}
+	id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	if err != nil {
+		jsonResp(w, 400, false, "编号格式错误")
+		return
+	}
+	post, err := s.store.GetPost(id)
+	if err != nil || post == nil {
+		jsonResp(w, 404, false, "稿件不存在")
+		return
+	}
+	idx, err := strconv.Atoi(r.FormValue("index"))
+	if err != nil || idx < 0 || idx >= len(post.Images) {
+		jsonResp(w, 400, false, "图片序号错误")
+		return
+	}
+	hash, err := s.postImageHash(post, idx)
+	if err != nil {
+		log.Printf("[Web] 加载稿件 #%d 第 %d 张图片失败: %v", id, idx+1, err)
+		jsonResp(w, 500, false, "图片加载失败，无法计算指纹")
+		return
+	}
+
+	block := &model.ImageBlock{
+		Hash:    hash,
+		Action:  r.FormValue("action"),
+		Note:    r.FormValue("note"),
+		PostID:  id,
+		AddedBy: model.WebVoter(account.Username),
+	}
+	if block.Action == "" {
+		block.Action = string(censor.ActionBlock)
+	}
+	if err := s.censor.AddImage(block); err != nil {
+		jsonResp(w, 400, false, err.Error())
+		return
+	}
+	log.Printf("[Web] %s 将稿件 #%d 第 %d 张图片加入黑名单 (%s)", account.Username, id, idx+1, hash)
+	jsonResp(w, 200, true, fmt.Sprintf("已加入图片黑名单，之后相似的图片将%s", censor.Action(block.Action).Text()))
+}
+
+// handleAPIImageUnblock 从图片黑名单中删除 (id)
+func (s *Server) handleAPIImageUnblock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		jsonResp(w, 405, false, "仅支持 POST")
+		return
+	}
+	account := s.currentAccount(r)
+	if account == nil || !account.Can(model.PermCensor) {
+		jsonResp(w, 403, false, "无权限")
+		return
+	}
+	id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	if err != nil {
+		jsonResp(w, 400, false, "编号格式错误")
+		return
+	}
+	ok, err := s.censor.RemoveImage(id)
+	if err != nil {
+		jsonResp(w, 500, false, "删除失败")
+		return
+	}
+	if !ok {
+		jsonResp(w, 404, false, "图片黑名单中没有该项")
+		return
+	}
+	log.Printf("[Web] %s 删除图片黑名单 #%d", account.Username, id)
+	jsonResp(w, 200, true, "已删除")
+}
@@ -0,0 +1,76 @@
+package web
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ──────────────────────────────────────────
+// 客户端 IP：部署在反向代理之后时按可信代理转发的请求头识别
+// ──────────────────────────────────────────
+
+// parseTrustedProxies 解析 web.trusted_proxies，单个 IP 视为 /32 (IPv6 为 /128)
+func parseTrustedProxies(entries []string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if !strings.Contains(e, "/") {
+			if ip := net.ParseIP(e); ip != nil {
+				bits := 8 * len(ip.To16())
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 32
+				}
+				nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+		} else if _, n, err := net.ParseCIDR(e); err == nil {
+			nets = append(nets, n)
+			continue
+		}
+		log.Printf("[Web] ⚠️ 无效的 trusted_proxies 项 %q，已忽略", e)
+	}
+	return nets
+}
+
+func (s *Server) trusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, n := range s.trustedProxies {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP 请求方的 IP 地址。连接来自可信代理时，从 X-Forwarded-For 右侧起跳过可信代理，
+// 取第一个不可信的地址；没有该头时使用 X-Real-IP。不可信的连接一律使用连接地址，防止伪造请求头
+func (s *Server) clientIP(r *http.Request) string {
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+	if !s.trusted(remote) {
+		return remote
+	}
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			if !s.trusted(hop) {
+				return hop
+			}
+		}
+	}
+	if real := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(real) != nil {
+		return real
+	}
+	return remote
+}
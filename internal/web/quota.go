@@ -0,0 +1,86 @@
+package web
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	"github.com/guohuiyuan/qzonewall-go/internal/quota"
+)
+
+// quotaRules 网页投稿的限流规则：登录账号按人计，另按 IP 计；审核团队成员不受限。
+// 表单里的 QQ 号可以随意填写，不用于限流，以免占用他人额度
+func (s *Server) quotaRules(r *http.Request, account *model.Account) []quota.Rule {
+	if account != nil && account.IsAdmin() {
+		return nil
+	}
+	user := ""
+	if account != nil {
+		user = "account:" + account.Username
+	}
+	return quota.Rules(s.wallCfg.RateLimit, user, "", "ip:"+s.clientIP(r))
+}
+
+// previewRules 投稿预览的限流规则：登录账号按人计，未登录按 IP 计；审核团队成员不受限
+func (s *Server) previewRules(r *http.Request, account *model.Account) []quota.Rule {
+	if account != nil && account.IsAdmin() {
+		return nil
+	}
+	user := ""
+	if account != nil {
+		user = "account:" + account.Username
+	}
+	return quota.PreviewRules(s.wallCfg.RateLimit, user, "ip:"+s.clientIP(r))
+}
+
+// checkPreviewQuota 超出预览频率限制时写入 429 并返回 false，未超出时计入本次预览
+func (s *Server) checkPreviewQuota(w http.ResponseWriter, r *http.Request, account *model.Account) bool {
+	now := time.Now()
+	ex, err := s.limiter.Take(s.previewRules(r, account), now)
+	if err != nil {
+		log.Printf("[Web] 记录预览频率失败: %v", err)
+		return true
+	}
+	if ex != nil {
+		jsonResp(w, 429, false, ex.Message(now))
+		return false
+	}
+	return true
+}
+
+// checkQuota 提前检查投稿频率限制 (不计数)，超出时写入 429 并返回 false
+func (s *Server) checkQuota(w http.ResponseWriter, r *http.Request, account *model.Account) bool {
+	now := time.Now()
+	ex, err := s.limiter.Check(s.quotaRules(r, account), now)
+	if err != nil {
+		log.Printf("[Web] 检查投稿频率失败: %v", err)
+		return true
+	}
+	if ex == nil {
+		return true
+	}
+	jsonResp(w, 429, false, ex.Message(now))
+	return false
+}
+
+// takeQuota 保存投稿前检查并计入频率限制，检查与计数不可分割；超出时写入 429 并返回 false
+func (s *Server) takeQuota(w http.ResponseWriter, r *http.Request, account *model.Account, now time.Time) bool {
+	ex, err := s.limiter.Take(s.quotaRules(r, account), now)
+	if err != nil {
+		log.Printf("[Web] 记录投稿频率失败: %v", err)
+		return true
+	}
+	if ex == nil {
+		return true
+	}
+	jsonResp(w, 429, false, ex.Message(now))
+	return false
+}
+
+// releaseQuota 投稿保存失败时撤销 takeQuota 的计数
+func (s *Server) releaseQuota(r *http.Request, account *model.Account, now time.Time) {
+	if err := s.limiter.Release(s.quotaRules(r, account), now); err != nil {
+		log.Printf("[Web] 撤销投稿频率记录失败: %v", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"embed"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
@@ -24,16 +25,24 @@ import (
 	"time"
 
 	qzone "github.com/guohuiyuan/qzone-go"
+	"github.com/guohuiyuan/qzonewall-go/internal/anon"
+	"github.com/guohuiyuan/qzonewall-go/internal/artifact"
+	"github.com/guohuiyuan/qzonewall-go/internal/censor"
 	"github.com/guohuiyuan/qzonewall-go/internal/config"
 	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	"github.com/guohuiyuan/qzonewall-go/internal/quota"
 	"github.com/guohuiyuan/qzonewall-go/internal/render"
 	"github.com/guohuiyuan/qzonewall-go/internal/store"
+	"github.com/guohuiyuan/qzonewall-go/internal/validate"
 	zero "github.com/wdvxdr1123/ZeroBot"
 )
 
 //go:embed templates/*.html templates/icon.png
 var templateFS embed.FS
 
+// maxUploadBytes 投稿和预览请求体的上限
+const maxUploadBytes = 32 << 20
+
 // Server Web 服务。
 type Server struct {
 	cfg       config.WebConfig
@@ -41,6 +50,11 @@ type Server struct {
 	store     *store.Store
 	qzClient  *qzone.Client
 	renderer  *render.Renderer
+	namer     *anon.Namer
+	artifacts *artifact.Store
+	limiter   *quota.Limiter
+	validator *validate.Validator
+	censor    *censor.Engine
 	tmpl      *template.Template
 	server    *http.Server
 	uploadDir string
@@ -48,6 +62,9 @@ type Server struct {
 	// [新增] 路由前缀，例如 "/wall"。默认为 ""
 	prefix string
 
+	// 可信反向代理，来自这些地址的请求按 X-Forwarded-For / X-Real-IP 取客户端 IP
+	trustedProxies []*net.IPNet
+
 	// QR 登录状态
 	qrMu      sync.Mutex
 	qrCode    *qzone.QRCode
@@ -62,6 +79,11 @@ func NewServer(
 	st *store.Store,
 	qzClient *qzone.Client,
 	renderer *render.Renderer,
+	namer *anon.Namer,
+	artifacts *artifact.Store,
+	limiter *quota.Limiter,
+	validator *validate.Validator,
+	censorEngine *censor.Engine,
 ) *Server {
 	return &Server{
 		cfg:       cfg,
@@ -69,10 +91,16 @@ func NewServer(
 		store:     st,
 		qzClient:  qzClient,
 		renderer:  renderer,
+		namer:     namer,
+		artifacts: artifacts,
+		limiter:   limiter,
+		validator: validator,
+		censor:    censorEngine,
 		uploadDir: "uploads",
 		// [配置] 在这里设置你的二级路径前缀，例如 "/wall"
 		// 如果在根目录运行，请保持为空字符串 ""
-		prefix: "/wall",
+		prefix:         "/wall",
+		trustedProxies: parseTrustedProxies(cfg.TrustedProxies),
 	}
 }
 
@@ -88,17 +116,7 @@ func (s *Server) Start() error {
 			return time.Unix(ts, 0).Format("2006-01-02 15:04")
 		},
 		"statusText": func(st model.PostStatus) string {
-			m := map[model.PostStatus]string{
-				model.StatusPending:   "待审核",
-				model.StatusApproved:  "已通过",
-				model.StatusRejected:  "已拒绝",
-				model.StatusFailed:    "失败",
-				model.StatusPublished: "已发布",
-			}
-			if v, ok := m[st]; ok {
-				return v
-			}
-			return string(st)
+			return st.Text()
 		},
 		"statusClass": func(st model.PostStatus) string {
 			m := map[model.PostStatus]string{
@@ -111,6 +129,9 @@ func (s *Server) Start() error {
 			return m[st]
 		},
 		"hasImages": func(imgs []string) bool { return len(imgs) > 0 },
+		"actionText": func(a string) string {
+			return censor.Action(a).Text()
+		},
 	}
 
 	var err error
@@ -135,13 +156,21 @@ func (s *Server) Start() error {
 	mux.HandleFunc(s.url("/logout"), s.handleLogout)
 	mux.HandleFunc(s.url("/submit"), s.handleSubmitPage)
 	mux.HandleFunc(s.url("/admin"), s.handleAdminPage)
+	mux.HandleFunc(s.url("/admin/bans"), s.handleBansPage)
+	mux.HandleFunc(s.url("/admin/censor"), s.handleCensorPage)
 	mux.HandleFunc(s.url("/icon.png"), s.handleIcon)
 	mux.HandleFunc(s.url("/favicon.ico"), s.handleFavicon)
 
 	// API 路由
 	mux.HandleFunc(s.url("/api/submit"), s.handleAPISubmit)
+	mux.HandleFunc(s.url("/api/submit/preview"), s.handleAPISubmitPreview)
+	mux.HandleFunc("GET "+s.url("/api/posts/{id}/preview"), s.handleAPIPostPreview)
 	mux.HandleFunc(s.url("/api/approve"), s.handleAPIApprove)
 	mux.HandleFunc(s.url("/api/reject"), s.handleAPIReject)
+	mux.HandleFunc(s.url("/api/warning"), s.handleAPIWarning)
+	mux.HandleFunc(s.url("/api/renders"), s.handleAPIRenders)
+	mux.HandleFunc(s.url("/api/rerender"), s.handleAPIRerender)
+	mux.HandleFunc(s.url("/renders/"), s.handleRenderFile)
 	mux.HandleFunc(s.url("/api/approve/batch"), s.handleAPIBatchApprove)
 	mux.HandleFunc(s.url("/api/reject/batch"), s.handleAPIBatchReject)
 	mux.HandleFunc(s.url("/api/qrcode"), s.handleAPIQRCode)
@@ -149,6 +178,14 @@ func (s *Server) Start() error {
 	mux.HandleFunc(s.url("/api/health"), s.handleAPIHealth)
 	mux.HandleFunc(s.url("/api/qzone/status"), s.handleAPIQzoneStatus)
 	mux.HandleFunc(s.url("/api/qzone/refresh"), s.handleAPIQzoneRefresh)
+	mux.HandleFunc(s.url("/api/bans"), s.handleAPIBan)
+	mux.HandleFunc(s.url("/api/bans/remove"), s.handleAPIUnban)
+	mux.HandleFunc(s.url("/api/censor"), s.handleAPICensorAdd)
+	mux.HandleFunc(s.url("/api/censor/remove"), s.handleAPICensorRemove)
+	mux.HandleFunc(s.url("/api/censor/image"), s.handleAPIImageBlock)
+	mux.HandleFunc(s.url("/api/censor/image/remove"), s.handleAPIImageUnblock)
+	mux.HandleFunc(s.url("/api/team/account"), s.handleAPITeamAccount)
+	mux.HandleFunc(s.url("/api/team/moderator"), s.handleAPITeamModerator)
 
 	// [修复] 静态资源处理
 	// 1. 拼接前缀，例如 "/wall" + "/uploads" -> "/wall/uploads"
@@ -201,7 +238,7 @@ func (s *Server) initAdmin() error {
 	}
 	salt := randomHex(16)
 	hash := hashPassword(s.cfg.AdminPass, salt)
-	return s.store.CreateAccount(s.cfg.AdminUser, hash, salt, "admin")
+	return s.store.CreateAccount(s.cfg.AdminUser, hash, salt, model.RoleOwner)
 }
 
 func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
@@ -243,7 +280,7 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if !account.IsAdmin() {
-		s.renderTemplate(w, "login.html", map[string]interface{}{"Error": "仅管理员可登录", "Root": s.prefix})
+		s.renderTemplate(w, "login.html", map[string]interface{}{"Error": "仅审核团队成员可登录", "Root": s.prefix})
 		return
 	}
 
@@ -321,8 +358,12 @@ func (s *Server) handleAdminPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	displayPosts := make([]*model.Post, len(posts))
+	renders := make(map[int64][]*model.Render, len(posts))
 	for i, p := range posts {
 		displayPosts[i] = s.resolvePostImages(p)
+		if latest, err := s.artifacts.Latest(p.ID); err == nil && len(latest) > 0 {
+			renders[p.ID] = latest
+		}
 	}
 
 	totalCount, _ := s.store.CountAll()
@@ -334,6 +375,11 @@ func (s *Server) handleAdminPage(w http.ResponseWriter, r *http.Request) {
 	data := map[string]interface{}{
 		"Account":        account,
 		"Posts":          displayPosts,
+		"Renders":        renders,
+		"Themes":         s.themeNames(),
+		"Team":           s.teamData(account),
+		"Votes":          s.voteTallies(posts),
+		"Quorum":         s.wallCfg.ApproveQuorum,
 		"TotalCount":     totalCount,
 		"PendingCount":   pendingCount,
 		"ApprovedCount":  approvedCount,
@@ -360,28 +406,19 @@ func (s *Server) handleAPISubmit(w http.ResponseWriter, r *http.Request) {
 
 	account := s.currentAccount(r)
 
-	if err := r.ParseMultipartForm(32 << 20); err != nil {
+	if err := r.ParseMultipartForm(maxUploadBytes); err != nil {
 		jsonResp(w, 400, false, "请求体过大")
 		return
 	}
 
-	text := r.FormValue("text")
-	name := r.FormValue("uin")
-	uin, _ := strconv.ParseInt(name, 10, 64)
-	anon := r.FormValue("anon") == "on" || r.FormValue("anon") == "true"
-	if name == "" && account != nil {
-		name = account.Username
-	}
-	if name == "" {
-		name = "匿名用户"
+	post := postFromForm(r, account)
+	if !s.checkBan(w, r, post.UIN, account) || !s.checkQuota(w, r, account) {
+		return
 	}
 
 	var images []string
 	files := r.MultipartForm.File["images"]
 	for _, fh := range files {
-		if len(images) >= s.wallCfg.MaxImages {
-			break
-		}
 		f, err := fh.Open()
 		if err != nil {
 			continue
@@ -408,27 +445,177 @@ func (s *Server) handleAPISubmit(w http.ResponseWriter, r *http.Request) {
 		images = append(images, "/uploads/"+filename)
 	}
 
-	if text == "" && len(images) == 0 {
-		jsonResp(w, 400, false, "内容不能为空")
+	post.Images = images
+	removeUploads := func() {
+		for _, img := range images {
+			_ = os.Remove(filepath.Join(s.uploadDir, filepath.Base(img)))
+		}
+	}
+	if !s.checkPost(w, post) {
+		removeUploads()
+		return
+	}
+	now := time.Now()
+	if !s.takeQuota(w, r, account, now) {
+		removeUploads()
+		return
+	}
+	s.namer.Apply(post, s.submitterIdentity(account, r))
+	if err := s.store.SavePost(post); err != nil {
+		s.releaseQuota(r, account, now)
+		jsonResp(w, 500, false, "保存失败")
 		return
 	}
 
+	log.Printf("[Web] received post #%d from %s", post.ID, post.Name)
+	jsonRespData(w, 200, true, fmt.Sprintf("投稿成功，编号 #%d，等待审核", post.ID), post.ID)
+}
+
+// postFromForm 按投稿表单构造待审核稿件 (不含图片)
+func postFromForm(r *http.Request, account *model.Account) *model.Post {
+	name := r.FormValue("uin")
+	uin, _ := strconv.ParseInt(name, 10, 64)
+	if name == "" && account != nil {
+		name = account.Username
+	}
+	if name == "" {
+		name = "匿名用户"
+	}
 	post := &model.Post{
 		UIN:        uin,
 		Name:       name,
-		Text:       text,
-		Images:     images,
-		Anon:       anon,
+		Text:       r.FormValue("text"),
+		Anon:       r.FormValue("anon") == "on" || r.FormValue("anon") == "true",
+		Warning:    r.FormValue("warning") == "on" || r.FormValue("warning") == "true",
 		Status:     model.StatusPending,
 		CreateTime: time.Now().Unix(),
 	}
-	if err := s.store.SavePost(post); err != nil {
-		jsonResp(w, 500, false, "保存失败")
+	if post.Warning {
+		post.WarnReason = strings.TrimSpace(r.FormValue("warn_reason"))
+	}
+	return post
+}
+
+// handleAPISubmitPreview 投稿前预览：按投稿表单渲染截图，不保存稿件，上传的图片用完即删。
+// 与投稿一样检查封禁，并单独限流，避免被用来反复试探敏感词或消耗渲染资源
+func (s *Server) handleAPISubmitPreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		jsonResp(w, 405, false, "仅支持 POST")
+		return
+	}
+	account := s.currentAccount(r)
+	// 读取表单前先按账号和 IP 检查，被封禁或超出频率时不接收上传
+	if !s.checkBan(w, r, 0, account) || !s.checkPreviewQuota(w, r, account) {
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadBytes)
+	if err := r.ParseMultipartForm(maxUploadBytes); err != nil {
+		jsonResp(w, 400, false, "请求体过大")
+		return
+	}
+	post := postFromForm(r, account)
+	if post.UIN > 0 && !s.checkBan(w, r, post.UIN, account) {
 		return
 	}
 
-	log.Printf("[Web] received post #%d from %s", post.ID, name)
-	jsonRespData(w, 200, true, fmt.Sprintf("投稿成功，编号 #%d，等待审核", post.ID), post.ID)
+	tmpDir, err := os.MkdirTemp("", "wall-preview-")
+	if err != nil {
+		jsonResp(w, 500, false, "预览失败")
+		return
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for i, fh := range r.MultipartForm.File["images"] {
+		f, err := fh.Open()
+		if err != nil {
+			continue
+		}
+		dst := filepath.Join(tmpDir, fmt.Sprintf("%d%s", i, filepath.Ext(fh.Filename)))
+		out, err := os.Create(dst)
+		if err == nil {
+			_, err = io.Copy(out, f)
+			_ = out.Close()
+		}
+		_ = f.Close()
+		if err == nil {
+			post.Images = append(post.Images, dst)
+		}
+	}
+
+	if !s.checkPost(w, post) {
+		return
+	}
+	s.namer.Apply(post, s.submitterIdentity(account, r))
+	s.writePreview(w, r, post)
+}
+
+// handleAPIPostPreview 渲染稿件截图 (即发布时使用的图片)，用于审核前查看
+func (s *Server) handleAPIPostPreview(w http.ResponseWriter, r *http.Request) {
+	account := s.currentAccount(r)
+	if account == nil || !account.IsAdmin() {
+		jsonResp(w, 403, false, "无权限")
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonResp(w, 400, false, "编号格式错误")
+		return
+	}
+	post, err := s.store.GetPost(id)
+	if err != nil || post == nil {
+		jsonResp(w, 404, false, "稿件不存在")
+		return
+	}
+	s.writePreview(w, r, s.resolvePostImagesForRender(post))
+}
+
+// writePreview 渲染并直接输出截图。查询参数 theme 选择主题，format 覆盖输出格式
+func (s *Server) writePreview(w http.ResponseWriter, r *http.Request, post *model.Post) {
+	if s.renderer == nil || !s.renderer.Available() {
+		jsonResp(w, 500, false, "渲染器不可用")
+		return
+	}
+	q := r.URL.Query()
+	theme, ok := s.renderer.Theme(q.Get("theme"))
+	if !ok {
+		jsonResp(w, 400, false, "未知主题: "+q.Get("theme"))
+		return
+	}
+	if f := q.Get("format"); f != "" {
+		format, err := render.ParseOutputFormat(f)
+		if err != nil {
+			jsonResp(w, 400, false, "不支持的格式: "+f)
+			return
+		}
+		theme.Output.Format = format
+	}
+
+	data, err := s.renderer.RenderPostWithTheme(post, theme)
+	if err != nil {
+		jsonResp(w, 500, false, "渲染失败: "+err.Error())
+		return
+	}
+	// 超出体积上限时无损格式会退回有损格式，按实际数据设置类型
+	w.Header().Set("Content-Type", render.DetectFormat(data).ContentType())
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(data)
+}
+
+// themeNames 可选的渲染主题，供页面的预览下拉框使用
+func (s *Server) themeNames() []string {
+	if s.renderer == nil {
+		return nil
+	}
+	return s.renderer.ThemeNames()
+}
+
+// submitterIdentity 网页投稿者的身份标识，用于计算匿名假名：登录账号 > IP。
+// 表单里的 QQ 号未经验证，不能使用，否则任何人都能冒用他人当天的假名和头像
+func (s *Server) submitterIdentity(account *model.Account, r *http.Request) string {
+	if account != nil {
+		return "account:" + account.Username
+	}
+	return "ip:" + s.clientIP(r)
 }
 
 func (s *Server) handleAPIApprove(w http.ResponseWriter, r *http.Request) {
@@ -437,7 +624,7 @@ func (s *Server) handleAPIApprove(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	account := s.currentAccount(r)
-	if account == nil || !account.IsAdmin() {
+	if account == nil || !account.Can(model.PermReview) {
 		jsonResp(w, 403, false, "无权限")
 		return
 	}
@@ -454,10 +641,36 @@ func (s *Server) handleAPIApprove(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	post.Status = model.StatusApproved
-	if err := s.store.SavePost(post); err != nil {
-		jsonResp(w, 500, false, "更新失败")
+	switch post.Status {
+	case model.StatusPending:
+		ok, tally, err := s.voteApprove(account, post, model.StatusApproved)
+		if errors.Is(err, errPostHandled) {
+			jsonResp(w, 409, false, fmt.Sprintf("稿件 #%d 已被其他审核员处理", id))
+			return
+		}
+		if err != nil {
+			jsonResp(w, 500, false, "记录投票失败")
+			return
+		}
+		if !ok {
+			jsonResp(w, 200, true, fmt.Sprintf("已记录同意，稿件 #%d %s，还需 %d 人", id, tally.Text(), tally.Remaining()))
+			return
+		}
+	case model.StatusPublished:
+		jsonResp(w, 409, false, fmt.Sprintf("稿件 #%d 已发布", id))
 		return
+	default:
+		from := post.Status
+		post.Status = model.StatusApproved
+		ok, err := s.store.SavePostIf(post, from)
+		if err != nil {
+			jsonResp(w, 500, false, "更新失败")
+			return
+		}
+		if !ok {
+			jsonResp(w, 409, false, fmt.Sprintf("稿件 #%d 状态已变化，请刷新后重试", id))
+			return
+		}
 	}
 	jsonResp(w, 200, true, fmt.Sprintf("稿件 #%d 已通过", id))
 }
@@ -468,7 +681,7 @@ func (s *Server) handleAPIReject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	account := s.currentAccount(r)
-	if account == nil || !account.IsAdmin() {
+	if account == nil || !account.Can(model.PermReview) {
 		jsonResp(w, 403, false, "无权限")
 		return
 	}
@@ -486,25 +699,172 @@ func (s *Server) handleAPIReject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 只能拒绝待审核或已通过待发布的稿件，与 QQ 端 /拒稿 一致
+	if post.Status != model.StatusPending && post.Status != model.StatusApproved {
+		jsonResp(w, 409, false, fmt.Sprintf("稿件 #%d %s，无法拒绝", id, post.Status.Text()))
+		return
+	}
+
+	s.voteReject(model.WebVoter(account.Username), post.ID, reason)
 	post.Status = model.StatusRejected
 	post.Reason = reason
-	if err := s.store.SavePost(post); err != nil {
+	ok, err := s.store.SavePostIf(post, model.StatusPending, model.StatusApproved)
+	if err != nil {
 		jsonResp(w, 500, false, "更新失败")
 		return
 	}
+	if !ok {
+		jsonResp(w, 409, false, fmt.Sprintf("稿件 #%d 状态已变化，请刷新后重试", id))
+		return
+	}
 	jsonResp(w, 200, true, fmt.Sprintf("稿件 #%d 已拒绝", id))
 }
 
-func (s *Server) handleAPIBatchApprove(w http.ResponseWriter, r *http.Request) {
+// handleAPIWarning 标记或取消内容警告 (warning=false 取消)
+func (s *Server) handleAPIWarning(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		jsonResp(w, 405, false, "仅支持 POST")
 		return
 	}
+	account := s.currentAccount(r)
+	if account == nil || !account.Can(model.PermReview) {
+		jsonResp(w, 403, false, "无权限")
+		return
+	}
+
+	id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	if err != nil {
+		jsonResp(w, 400, false, "编号格式错误")
+		return
+	}
+	post, err := s.store.GetPost(id)
+	if err != nil || post == nil {
+		jsonResp(w, 404, false, "稿件不存在")
+		return
+	}
+	if post.Status == model.StatusPublished {
+		jsonResp(w, 400, false, "稿件已发布，无法修改")
+		return
+	}
+
+	post.Warning = r.FormValue("warning") != "false"
+	post.WarnReason = ""
+	if post.Warning {
+		post.WarnReason = strings.TrimSpace(r.FormValue("reason"))
+	}
+	if err := s.store.SavePost(post); err != nil {
+		jsonResp(w, 500, false, "更新失败")
+		return
+	}
+	if post.Warning {
+		jsonResp(w, 200, true, fmt.Sprintf("稿件 #%d 已标记%s", id, post.WarningLabel()))
+		return
+	}
+	jsonResp(w, 200, true, fmt.Sprintf("稿件 #%d 已取消内容警告", id))
+}
+
+// handleAPIRenders 列出稿件保存的全部截图版本，新版本在前
+func (s *Server) handleAPIRenders(w http.ResponseWriter, r *http.Request) {
 	account := s.currentAccount(r)
 	if account == nil || !account.IsAdmin() {
 		jsonResp(w, 403, false, "无权限")
 		return
 	}
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		jsonResp(w, 400, false, "编号格式错误")
+		return
+	}
+	renders, err := s.artifacts.Versions(id)
+	if err != nil {
+		jsonResp(w, 500, false, "查询失败")
+		return
+	}
+
+	items := make([]map[string]interface{}, 0, len(renders))
+	for _, rd := range renders {
+		items = append(items, map[string]interface{}{
+			"version":     rd.Version,
+			"index":       rd.Index,
+			"url":         s.url("/renders/" + rd.File),
+			"published":   rd.Published,
+			"create_time": rd.CreateTime,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":      true,
+		"renders": items,
+	})
+}
+
+// handleAPIRerender 重新渲染稿件并保存为新版本，不会重新发布
+func (s *Server) handleAPIRerender(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		jsonResp(w, 405, false, "仅支持 POST")
+		return
+	}
+	account := s.currentAccount(r)
+	if account == nil || !account.Can(model.PermReview) {
+		jsonResp(w, 403, false, "无权限")
+		return
+	}
+
+	id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	if err != nil {
+		jsonResp(w, 400, false, "编号格式错误")
+		return
+	}
+	post, err := s.store.GetPost(id)
+	if err != nil || post == nil {
+		jsonResp(w, 404, false, "稿件不存在")
+		return
+	}
+	if s.renderer == nil || !s.renderer.Available() {
+		jsonResp(w, 500, false, "渲染器不可用")
+		return
+	}
+
+	images, err := s.renderer.RenderForPublish(s.resolvePostImagesForRender(post))
+	if err != nil {
+		jsonResp(w, 500, false, "渲染失败: "+err.Error())
+		return
+	}
+	version, err := s.artifacts.Save(id, images, false)
+	if err != nil {
+		jsonResp(w, 500, false, "保存截图失败")
+		return
+	}
+	jsonResp(w, 200, true, fmt.Sprintf("稿件 #%d 已重新渲染 (版本 %d)", id, version))
+}
+
+// handleRenderFile 提供已保存的截图文件 (仅管理员)
+func (s *Server) handleRenderFile(w http.ResponseWriter, r *http.Request) {
+	account := s.currentAccount(r)
+	if account == nil || !account.IsAdmin() {
+		http.Error(w, "无权限", http.StatusForbidden)
+		return
+	}
+	p, ok := s.artifacts.Path(strings.TrimPrefix(r.URL.Path, s.url("/renders/")))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	// 文件名即内容哈希，内容不会变化
+	w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	http.ServeFile(w, r, p)
+}
+
+func (s *Server) handleAPIBatchApprove(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		jsonResp(w, 405, false, "仅支持 POST")
+		return
+	}
+	account := s.currentAccount(r)
+	if account == nil || !account.Can(model.PermReview) {
+		jsonResp(w, 403, false, "无权限")
+		return
+	}
 
 	ids, err := parseBatchIDs(r.FormValue("ids"))
 	if err != nil {
@@ -529,73 +889,83 @@ func (s *Server) handleAPIBatchApprove(w http.ResponseWriter, r *http.Request) {
 		jsonResp(w, 400, false, "没有待审核的稿件，或已处理")
 		return
 	}
+	// 票数已满的稿件以条件更新直接改为已发布，并发的另一次过稿不会再拿到它们
+	validPosts, waiting := s.voteApproveAll(account, validPosts, model.StatusPublished)
+	if len(validPosts) == 0 {
+		jsonResp(w, 200, true, "已记录同意，"+waiting)
+		return
+	}
 
-	var summaryBuilder strings.Builder
-	summaryBuilder.WriteString(fmt.Sprintf("【表白墙更新】 %s\n", time.Now().Format("01/02")))
-	summaryBuilder.WriteString("----------------\n")
-
-	var imagesData [][]byte
-
+	rendered := map[int64][][]byte{}
 	for _, post := range validPosts {
-		var imgData []byte
+		var imgData [][]byte
 		var renderErr error
 
 		if s.renderer != nil && s.renderer.Available() {
 			// [修复] 使用本地路径解析器，而不是 resolvePostImages
 			// resolvePostImages 会加上 /wall 前缀导致后端无法读取文件
+			// 内容警告稿件在截图后附带清晰原图
 			renderPost := s.resolvePostImagesForRender(post)
-			imgData, renderErr = s.renderer.RenderPost(renderPost)
+			imgData, renderErr = s.renderer.RenderForPublish(renderPost)
 		} else {
 			renderErr = fmt.Errorf("renderer not available")
 		}
 
 		if renderErr != nil || len(imgData) == 0 {
 			log.Printf("[Web] 渲染失败 #%d: %v", post.ID, renderErr)
+			// 渲染失败的稿件退回待审核
+			post.Status = model.StatusPending
+			_ = s.store.SavePost(post)
 			continue
 		}
-		imagesData = append(imagesData, imgData)
-
-		content := []rune(post.Text)
-		if len(content) > 20 {
-			summaryBuilder.WriteString(fmt.Sprintf("#%d: %s...\n", post.ID, string(content[:20])))
-		} else {
-			if post.Text == "" {
-				summaryBuilder.WriteString(fmt.Sprintf("#%d: [图片]\n", post.ID))
-			} else {
-				summaryBuilder.WriteString(fmt.Sprintf("#%d: %s\n", post.ID, post.Text))
-			}
-		}
-
-		post.Status = model.StatusPublished
-		_ = s.store.SavePost(post)
+		rendered[post.ID] = imgData
 	}
 
-	if len(imagesData) == 0 {
+	// 图片超出说说上限时拆成多条发布
+	batches := render.SplitPublish(validPosts, rendered)
+	if len(batches) == 0 {
 		jsonResp(w, 500, false, "没有成功渲染的图片，取消发布")
 		return
 	}
 
-	summaryBuilder.WriteString("----------------\n")
-	summaryBuilder.WriteString("详情见图 👇")
-	finalText := summaryBuilder.String()
-
-	opts := &qzone.PublishOption{
-		ImageBytes: imagesData,
+	date := time.Now().Format("01/02")
+	published := 0
+	var failures []string
+	for i, batch := range batches {
+		opts := &qzone.PublishOption{
+			ImageBytes: batch.Images,
+		}
+		_, publishErr := s.qzClient.Publish(context.Background(), batch.Text(date, i+1, len(batches)), opts)
+		if publishErr != nil {
+			log.Printf("[Web] 发布说说失败: %v", publishErr)
+			for _, p := range batch.Posts {
+				p.Status = model.StatusPending
+				_ = s.store.SavePost(p)
+			}
+			failures = append(failures, publishErr.Error())
+			continue
+		}
+		for _, p := range batch.Posts {
+			if _, err := s.artifacts.Save(p.ID, rendered[p.ID], true); err != nil {
+				log.Printf("[Web] 保存稿件 #%d 发布截图失败: %v", p.ID, err)
+			}
+		}
+		published += len(batch.Posts)
 	}
 
-	_, publishErr := s.qzClient.Publish(context.Background(), finalText, opts)
-
-	if publishErr != nil {
-		log.Printf("[Web] 发布说说失败: %v", publishErr)
-		for _, p := range validPosts {
-			p.Status = model.StatusPending
-			_ = s.store.SavePost(p)
-		}
-		jsonResp(w, 500, false, "发布到QQ空间失败: "+publishErr.Error())
+	if published == 0 {
+		jsonResp(w, 500, false, "发布到QQ空间失败: "+strings.Join(failures, "; "))
 		return
 	}
 
-	jsonResp(w, 200, true, fmt.Sprintf("成功发布 %d 条稿件！", len(imagesData)))
+	msg := fmt.Sprintf("成功发布 %d 条稿件！", published)
+	if len(failures) > 0 {
+		msg += fmt.Sprintf("\n%d 条说说发布失败，相关稿件已退回待审核: %s", len(failures), strings.Join(failures, "; "))
+	}
+	if waiting != "" {
+		msg += "\n" + waiting
+	}
+	jsonResp(w, 200, true, msg)
 }
 
 func (s *Server) handleAPIBatchReject(w http.ResponseWriter, r *http.Request) {
@@ -604,7 +974,7 @@ func (s *Server) handleAPIBatchReject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	account := s.currentAccount(r)
-	if account == nil || !account.IsAdmin() {
+	if account == nil || !account.Can(model.PermReview) {
 		jsonResp(w, 403, false, "无权限")
 		return
 	}
@@ -615,7 +985,7 @@ func (s *Server) handleAPIBatchReject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	reason := strings.TrimSpace(r.FormValue("reason"))
-	updated, skipped, err := s.applyBatchStatus(ids, model.StatusRejected, reason)
+	updated, skipped, err := s.applyBatchStatus(ids, model.StatusRejected, reason, model.WebVoter(account.Username))
 	if err != nil {
 		jsonResp(w, 500, false, "批量拒绝失败")
 		return
@@ -653,7 +1023,7 @@ func parseBatchIDs(raw string) ([]int64, error) {
 	return ids, nil
 }
 
-func (s *Server) applyBatchStatus(ids []int64, status model.PostStatus, reason string) (updated int, skipped int, err error) {
+func (s *Server) applyBatchStatus(ids []int64, status model.PostStatus, reason, voter string) (updated int, skipped int, err error) {
 	posts, err := s.store.GetPostsByIDs(ids)
 	if err != nil {
 		return 0, 0, err
@@ -673,13 +1043,19 @@ func (s *Server) applyBatchStatus(ids []int64, status model.PostStatus, reason s
 		}
 		post.Status = status
 		if status == model.StatusRejected {
+			s.voteReject(voter, post.ID, reason)
 			post.Reason = reason
 		} else {
 			post.Reason = ""
 		}
-		if err := s.store.SavePost(post); err != nil {
+		ok, err := s.store.SavePostIf(post, model.StatusPending)
+		if err != nil {
 			return updated, skipped, err
 		}
+		if !ok {
+			skipped++
+			continue
+		}
 		updated++
 	}
 	missing := len(ids) - len(posts)
@@ -691,7 +1067,7 @@ func (s *Server) applyBatchStatus(ids []int64, status model.PostStatus, reason s
 
 func (s *Server) handleAPIQRCode(w http.ResponseWriter, r *http.Request) {
 	account := s.currentAccount(r)
-	if account == nil || !account.IsAdmin() {
+	if account == nil || !account.Can(model.PermAccount) {
 		jsonResp(w, 403, false, "无权限")
 		return
 	}
@@ -805,7 +1181,7 @@ func (s *Server) handleAPIQzoneRefresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	account := s.currentAccount(r)
-	if account == nil || !account.IsAdmin() {
+	if account == nil || !account.Can(model.PermAccount) {
 		jsonResp(w, 403, false, "无权限")
 		return
 	}
@@ -914,7 +1290,7 @@ func (s *Server) RegisterUser(username, password string) error {
 	}
 	salt := randomHex(16)
 	hash := hashPassword(password, salt)
-	return s.store.CreateAccount(username, hash, salt, "user")
+	return s.store.CreateAccount(username, hash, salt, model.RoleUser)
 }
 
 func (s *Server) SetCookieFile(cookieFile string) {
@@ -0,0 +1,113 @@
+package web
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+)
+
+// ──────────────────────────────────────────
+// 审核团队：网页账号与 QQ 审核员共用角色，仅所有者可修改
+// ──────────────────────────────────────────
+
+// accountRoles 网页账号可选的角色
+var accountRoles = []model.Role{model.RoleOwner, model.RoleModerator, model.RoleViewer, model.RoleUser}
+
+// teamData 管理页的审核团队面板数据，无团队管理权限时返回 nil
+func (s *Server) teamData(account *model.Account) map[string]interface{} {
+	if !account.Can(model.PermTeam) {
+		return nil
+	}
+	accounts, err := s.store.ListAccounts()
+	if err != nil {
+		log.Printf("[Web] 查询账号失败: %v", err)
+	}
+	moderators, err := s.store.ListModerators()
+	if err != nil {
+		log.Printf("[Web] 查询审核员失败: %v", err)
+	}
+	return map[string]interface{}{
+		"Accounts":   accounts,
+		"Moderators": moderators,
+		"Roles":      accountRoles,
+	}
+}
+
+// handleAPITeamAccount 修改网页账号角色 (username, role)
+func (s *Server) handleAPITeamAccount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		jsonResp(w, 405, false, "仅支持 POST")
+		return
+	}
+	account := s.currentAccount(r)
+	if account == nil || !account.Can(model.PermTeam) {
+		jsonResp(w, 403, false, "无权限")
+		return
+	}
+
+	role, ok := model.ParseRole(r.FormValue("role"))
+	if !ok {
+		jsonResp(w, 400, false, "未知角色")
+		return
+	}
+	target, err := s.store.GetAccount(strings.TrimSpace(r.FormValue("username")))
+	if err != nil || target == nil {
+		jsonResp(w, 404, false, "账号不存在")
+		return
+	}
+	if target.Role == model.RoleOwner && role != model.RoleOwner {
+		if n, err := s.store.CountAccountsByRole(model.RoleOwner); err != nil || n <= 1 {
+			jsonResp(w, 400, false, "至少需要保留一个所有者账号")
+			return
+		}
+	}
+	if err := s.store.SetAccountRole(target.ID, role); err != nil {
+		jsonResp(w, 500, false, "保存失败")
+		return
+	}
+	log.Printf("[Web] %s 将账号 %s 设为%s", account.Username, target.Username, role.Text())
+	jsonResp(w, 200, true, "已将 "+target.Username+" 设为"+role.Text())
+}
+
+// handleAPITeamModerator 添加/修改/移除 QQ 审核员 (uin, role；role 为空时移除)
+func (s *Server) handleAPITeamModerator(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		jsonResp(w, 405, false, "仅支持 POST")
+		return
+	}
+	account := s.currentAccount(r)
+	if account == nil || !account.Can(model.PermTeam) {
+		jsonResp(w, 403, false, "无权限")
+		return
+	}
+
+	uin, err := strconv.ParseInt(strings.TrimSpace(r.FormValue("uin")), 10, 64)
+	if err != nil || uin <= 0 {
+		jsonResp(w, 400, false, "QQ 号格式不正确")
+		return
+	}
+	if r.FormValue("role") == "" {
+		if _, err := s.store.RemoveModerator(uin); err != nil {
+			jsonResp(w, 500, false, "移除失败")
+			return
+		}
+		log.Printf("[Web] %s 移除了审核员 %d", account.Username, uin)
+		jsonResp(w, 200, true, "已移出审核团队")
+		return
+	}
+
+	role, ok := model.ParseRole(r.FormValue("role"))
+	if !ok || !role.IsStaff() {
+		jsonResp(w, 400, false, "角色只能是所有者、审核员或观察员")
+		return
+	}
+	if err := s.store.SetModerator(&model.Moderator{UIN: uin, Role: role}); err != nil {
+		jsonResp(w, 500, false, "保存失败")
+		return
+	}
+	log.Printf("[Web] %s 将 QQ %d 设为%s", account.Username, uin, role.Text())
+	jsonResp(w, 200, true, "已设为"+role.Text())
+}
@@ -0,0 +1,47 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+	"github.com/guohuiyuan/qzonewall-go/internal/validate"
+)
+
+// checkPost 规范化并校验稿件，不通过时返回 400 及结构化的错误 (error 字段) 并返回 false
+func (s *Server) checkPost(w http.ResponseWriter, post *model.Post) bool {
+	var verr *validate.Error
+	if !errors.As(s.validator.Check(post), &verr) {
+		return true
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":      false,
+		"message": validationMessage(verr),
+		"error":   verr,
+	})
+	return false
+}
+
+// validationMessage 网页投稿的校验提示
+func validationMessage(e *validate.Error) string {
+	switch e.Code {
+	case validate.CodeEmpty:
+		return "内容不能为空"
+	case validate.CodeTextTooLong:
+		return fmt.Sprintf("正文最多 %d 字，当前 %d 字", e.Limit, e.Actual)
+	case validate.CodeTooManyImages:
+		return fmt.Sprintf("最多上传 %d 张图片，当前 %d 张", e.Limit, e.Actual)
+	case validate.CodeCensored:
+		if len(e.Images) > 0 {
+			return fmt.Sprintf("第 %s 张图片不允许发布，请更换后再提交", e.ImageList())
+		}
+		return "投稿包含不允许发布的内容，请修改后再提交"
+	case validate.CodeDuplicate:
+		return fmt.Sprintf("与稿件 #%d 内容重复，请勿重复投稿", e.PostID)
+	}
+	return e.Error()
+}
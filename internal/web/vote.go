@@ -0,0 +1,100 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/guohuiyuan/qzonewall-go/internal/model"
+)
+
+// ──────────────────────────────────────────
+// 多人审核投票 (规则见 wall.approve_quorum)，网页账号与 QQ 审核员共用票数
+// ──────────────────────────────────────────
+
+// errPostHandled 票数已满但稿件已不是待审核状态 (被其他审核员抢先处理)
+var errPostHandled = errors.New("稿件已被处理")
+
+// voteApprove 记录账号对待审核稿件的同意票，返回是否可以通过 (所有者直接通过)。
+// 通过时以条件更新把稿件从待审核改为 to，已被他人处理时返回 errPostHandled，
+// 防止并发的同意票把同一稿件通过两次
+func (s *Server) voteApprove(account *model.Account, post *model.Post, to model.PostStatus) (bool, *model.VoteTally, error) {
+	v := &model.Vote{PostID: post.ID, Voter: model.WebVoter(account.Username), Approve: true}
+	tally, err := s.store.CastVote(v, s.wallCfg.ApproveQuorum)
+	if err != nil {
+		return false, nil, err
+	}
+	if account.Role != model.RoleOwner && !tally.Passed() {
+		return false, tally, nil
+	}
+	post.Status = to
+	ok, err := s.store.SavePostIf(post, model.StatusPending)
+	if err != nil {
+		post.Status = model.StatusPending
+		return false, tally, err
+	}
+	if !ok {
+		return false, tally, errPostHandled
+	}
+	return true, tally, nil
+}
+
+// voteApproveAll 为多篇稿件记录同意票并把可以通过的稿件改为 to，返回这些稿件和未通过的说明
+func (s *Server) voteApproveAll(account *model.Account, posts []*model.Post, to model.PostStatus) ([]*model.Post, string) {
+	var (
+		passed  []*model.Post
+		waiting []string
+		handled []string
+	)
+	for _, p := range posts {
+		ok, tally, err := s.voteApprove(account, p, to)
+		if errors.Is(err, errPostHandled) {
+			handled = append(handled, fmt.Sprintf("#%d", p.ID))
+			continue
+		}
+		if err != nil {
+			log.Printf("[Web] 记录稿件 #%d 投票失败: %v", p.ID, err)
+			waiting = append(waiting, fmt.Sprintf("#%d 投票记录失败", p.ID))
+			continue
+		}
+		if ok {
+			passed = append(passed, p)
+			continue
+		}
+		waiting = append(waiting, fmt.Sprintf("#%d %s", p.ID, tally.Text()))
+	}
+	var notes []string
+	if len(waiting) > 0 {
+		notes = append(notes, "票数未满："+strings.Join(waiting, "；"))
+	}
+	if len(handled) > 0 {
+		notes = append(notes, "已被其他审核员处理："+strings.Join(handled, "，"))
+	}
+	return passed, strings.Join(notes, "\n")
+}
+
+// voteReject 记录账号的否决票
+func (s *Server) voteReject(voter string, postID int64, reason string) {
+	v := &model.Vote{PostID: postID, Voter: voter, Reason: reason}
+	if _, err := s.store.CastVote(v, s.wallCfg.ApproveQuorum); err != nil {
+		log.Printf("[Web] 记录稿件 #%d 否决失败: %v", postID, err)
+	}
+}
+
+// voteTallies 管理页待审核稿件的投票进度，未启用多人审核时返回 nil
+func (s *Server) voteTallies(posts []*model.Post) map[int64]*model.VoteTally {
+	if s.wallCfg.ApproveQuorum <= 1 {
+		return nil
+	}
+	out := make(map[int64]*model.VoteTally)
+	for _, p := range posts {
+		if p.Status != model.StatusPending {
+			continue
+		}
+		if tally, err := s.store.VoteTally(p.ID, s.wallCfg.ApproveQuorum); err == nil {
+			out[p.ID] = tally
+		}
+	}
+	return out
+}